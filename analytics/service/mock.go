@@ -0,0 +1,23 @@
+package service
+
+import (
+	"context"
+
+	analyticsdomain "github.com/vediagames/onlooker/domain/analytics"
+)
+
+type mock struct{}
+
+func NewMock() analyticsdomain.Service {
+	return &mock{}
+}
+
+func (m mock) LevelFunnel(ctx context.Context, request analyticsdomain.LevelFunnelRequest) (analyticsdomain.LevelFunnelResponse, error) {
+	//TODO implement me
+	panic("implement me")
+}
+
+func (m mock) Retention(ctx context.Context, request analyticsdomain.RetentionRequest) (analyticsdomain.RetentionResponse, error) {
+	//TODO implement me
+	panic("implement me")
+}
@@ -0,0 +1,121 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	domain "github.com/vediagames/onlooker/domain/analytics"
+	"github.com/vediagames/onlooker/errutil"
+)
+
+type service struct {
+	store domain.Store
+}
+
+type Config struct {
+	Store domain.Store
+}
+
+func (c Config) Validate() error {
+	var err errutil.Error
+
+	if c.Store == nil {
+		err.Add(fmt.Errorf("store is empty"))
+	}
+
+	return err.Err()
+}
+
+func New(cfg Config) (domain.Service, error) {
+	if ve := cfg.Validate(); ve != nil {
+		return nil, fmt.Errorf("invalid config: %w", ve)
+	}
+
+	return &service{
+		store: cfg.Store,
+	}, nil
+}
+
+func (s service) LevelFunnel(ctx context.Context, req domain.LevelFunnelRequest) (domain.LevelFunnelResponse, error) {
+	if err := req.Validate(); err != nil {
+		return domain.LevelFunnelResponse{}, fmt.Errorf("invalid request: %w", errutil.WithKind(err, domain.ErrInvalidArgument))
+	}
+
+	funnelRes, err := s.store.LevelFunnel(ctx, domain.LevelFunnelQuery(req))
+	if err != nil {
+		return domain.LevelFunnelResponse{}, fmt.Errorf("failed to get level funnel: %w", err)
+	}
+
+	res := domain.LevelFunnelResponse{
+		Levels: make([]domain.LevelFunnelStep, 0, len(funnelRes.Levels)),
+	}
+
+	for i, l := range funnelRes.Levels {
+		step := domain.LevelFunnelStep{
+			LevelAttempts: l,
+		}
+
+		if l.Started > 0 {
+			step.CompletionRate = float64(l.Completed) / float64(l.Started)
+			step.QuitRate = float64(l.Abandoned) / float64(l.Started)
+		}
+
+		// Drop-off is only known if the next level is in the result. It is at least 0,
+		// since sessions can play the next level without playing this one.
+		if i+1 < len(funnelRes.Levels) && l.Sessions > 0 {
+			if next := funnelRes.Levels[i+1]; next.Level == l.Level+1 {
+				var dropOff float64
+				if next.Sessions < l.Sessions {
+					dropOff = 1 - float64(next.Sessions)/float64(l.Sessions)
+				}
+
+				step.DropOff = &dropOff
+			}
+		}
+
+		res.Levels = append(res.Levels, step)
+	}
+
+	return res, nil
+}
+
+func (s service) Retention(ctx context.Context, req domain.RetentionRequest) (domain.RetentionResponse, error) {
+	if err := req.Validate(); err != nil {
+		return domain.RetentionResponse{}, fmt.Errorf("invalid request: %w", errutil.WithKind(err, domain.ErrInvalidArgument))
+	}
+
+	retentionRes, err := s.store.Retention(ctx, domain.RetentionQuery(req))
+	if err != nil {
+		return domain.RetentionResponse{}, fmt.Errorf("failed to get retention: %w", err)
+	}
+
+	res := domain.RetentionResponse{
+		Cohorts: make([]domain.RetentionCohort, 0, len(retentionRes.Cohorts)),
+	}
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+
+	for _, c := range retentionRes.Cohorts {
+		res.Cohorts = append(res.Cohorts, domain.RetentionCohort{
+			Cohort:    c,
+			Day1Rate:  retentionRate(c, c.Day1, 1, today),
+			Day7Rate:  retentionRate(c, c.Day7, 7, today),
+			Day30Rate: retentionRate(c, c.Day30, 30, today),
+		})
+	}
+
+	return res, nil
+}
+
+// retentionRate returns the share of the players of c who played again days
+// after they were first seen, or nil if that day has not ended before today.
+func retentionRate(c domain.Cohort, retained int, days int, today time.Time) *float64 {
+	if c.Players == 0 || !c.Day.AddDate(0, 0, days).Before(today) {
+		return nil
+	}
+
+	rate := float64(retained) / float64(c.Players)
+
+	return &rate
+}
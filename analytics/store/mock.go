@@ -0,0 +1,28 @@
+package store
+
+import (
+	"context"
+
+	domain "github.com/vediagames/onlooker/domain/analytics"
+)
+
+type mock struct{}
+
+func NewMock() domain.Store {
+	return &mock{}
+}
+
+func (s mock) LevelFunnel(ctx context.Context, q domain.LevelFunnelQuery) (domain.LevelFunnelResult, error) {
+	//TODO implement me
+	panic("implement me")
+}
+
+func (s mock) Retention(ctx context.Context, q domain.RetentionQuery) (domain.RetentionResult, error) {
+	//TODO implement me
+	panic("implement me")
+}
+
+func (s mock) Close() error {
+	//TODO implement me
+	panic("implement me")
+}
@@ -0,0 +1,189 @@
+package postgresql
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	_ "github.com/lib/pq"
+	"github.com/vediagames/onlooker/database"
+	domain "github.com/vediagames/onlooker/domain/analytics"
+	"github.com/vediagames/onlooker/errutil"
+)
+
+// dateLayout is the layout of the days of retention cohorts.
+const dateLayout = "2006-01-02"
+
+type store struct {
+	db *sqlx.DB
+}
+
+type Config struct {
+	DB *sqlx.DB
+}
+
+func (c Config) Validate() error {
+	var err errutil.Error
+
+	if c.DB == nil {
+		err.Add(fmt.Errorf("db is empty"))
+	}
+
+	return err.Err()
+}
+
+func New(cfg Config) (domain.Store, error) {
+	if ve := cfg.Validate(); ve != nil {
+		return nil, fmt.Errorf("invalid config: %w", ve)
+	}
+
+	return &store{
+		db: cfg.DB,
+	}, nil
+}
+
+type levelAttempts struct {
+	Level     int `db:"level"`
+	Sessions  int `db:"sessions"`
+	Started   int `db:"started"`
+	Completed int `db:"completed"`
+	Died      int `db:"died"`
+	Restarted int `db:"restarted"`
+	Abandoned int `db:"abandoned"`
+}
+
+func (s store) LevelFunnel(ctx context.Context, q domain.LevelFunnelQuery) (domain.LevelFunnelResult, error) {
+	var args []interface{}
+	sqlQuery := `
+		SELECT l.level,
+			COUNT(DISTINCT l.session_uuid) AS sessions,
+			COUNT(*) AS started,
+			COUNT(*) FILTER (
+				WHERE EXISTS (SELECT 1 FROM level_complete_events c WHERE c.level_uuid = l.uuid)
+			) AS completed,
+			COUNT(*) FILTER (
+				WHERE EXISTS (SELECT 1 FROM level_death_events d WHERE d.level_uuid = l.uuid)
+			) AS died,
+			COUNT(*) FILTER (WHERE l.outcome = 'restart') AS restarted,
+			COUNT(*) FILTER (
+				WHERE l.outcome = 'abandon' OR (l.outcome IS NULL AND s.ended_at IS NOT NULL)
+			) AS abandoned
+		FROM levels l
+		JOIN sessions s ON s.uuid = l.session_uuid
+		WHERE l.level IS NOT NULL`
+
+	if !q.From.IsZero() {
+		args = append(args, q.From.UTC())
+		sqlQuery += fmt.Sprintf(" AND l.server_time >= $%d", len(args))
+	}
+
+	if !q.To.IsZero() {
+		args = append(args, q.To.UTC())
+		sqlQuery += fmt.Sprintf(" AND l.server_time < $%d", len(args))
+	}
+
+	if q.URL != "" {
+		args = append(args, q.URL)
+		sqlQuery += fmt.Sprintf(" AND s.url = $%d", len(args))
+	}
+
+	if q.Game != "" {
+		args = append(args, q.Game)
+		sqlQuery += fmt.Sprintf(" AND s.game = $%d", len(args))
+	}
+
+	sqlQuery += " GROUP BY l.level ORDER BY l.level"
+
+	var rows []levelAttempts
+
+	if err := s.db.SelectContext(ctx, &rows, sqlQuery, args...); err != nil {
+		return domain.LevelFunnelResult{}, fmt.Errorf("failed to select level funnel: %w", database.TranslateError(err))
+	}
+
+	res := domain.LevelFunnelResult{
+		Levels: make([]domain.LevelAttempts, 0, len(rows)),
+	}
+
+	for _, r := range rows {
+		res.Levels = append(res.Levels, domain.LevelAttempts(r))
+	}
+
+	return res, nil
+}
+
+type cohort struct {
+	Day     time.Time `db:"day"`
+	Players int       `db:"players"`
+	Day1    int       `db:"day1"`
+	Day7    int       `db:"day7"`
+	Day30   int       `db:"day30"`
+}
+
+func (s store) Retention(ctx context.Context, q domain.RetentionQuery) (domain.RetentionResult, error) {
+	// Sessions with an unknown timezone are grouped by their UTC day.
+	day := `(s.server_time AT TIME ZONE 'UTC' AT TIME ZONE COALESCE(tz.name, 'UTC'))::date`
+	if q.UTC {
+		day = `s.server_time::date`
+	}
+
+	args := []interface{}{q.Game}
+	sqlQuery := `
+		WITH days AS (
+			SELECT DISTINCT s.game, s.player_id, ` + day + ` AS day
+			FROM sessions s
+			LEFT JOIN pg_timezone_names tz ON tz.name = s.timezone
+			WHERE s.player_id IS NOT NULL AND ($1 = '' OR s.game = $1)
+		), cohorts AS (
+			SELECT game, player_id, MIN(day) AS day
+			FROM days
+			GROUP BY game, player_id
+		)
+		SELECT c.day,
+			COUNT(*) AS players,
+			COUNT(*) FILTER (WHERE r.day1) AS day1,
+			COUNT(*) FILTER (WHERE r.day7) AS day7,
+			COUNT(*) FILTER (WHERE r.day30) AS day30
+		FROM cohorts c
+		CROSS JOIN LATERAL (
+			SELECT COALESCE(bool_or(d.day = c.day + 1), false) AS day1,
+				COALESCE(bool_or(d.day = c.day + 7), false) AS day7,
+				COALESCE(bool_or(d.day = c.day + 30), false) AS day30
+			FROM days d
+			WHERE d.game = c.game AND d.player_id = c.player_id
+		) r
+		WHERE true`
+
+	if !q.From.IsZero() {
+		args = append(args, q.From.Format(dateLayout))
+		sqlQuery += fmt.Sprintf(" AND c.day >= $%d::date", len(args))
+	}
+
+	if !q.To.IsZero() {
+		args = append(args, q.To.Format(dateLayout))
+		sqlQuery += fmt.Sprintf(" AND c.day < $%d::date", len(args))
+	}
+
+	sqlQuery += " GROUP BY c.day ORDER BY c.day"
+
+	var rows []cohort
+
+	if err := s.db.SelectContext(ctx, &rows, sqlQuery, args...); err != nil {
+		return domain.RetentionResult{}, fmt.Errorf("failed to select retention: %w", database.TranslateError(err))
+	}
+
+	res := domain.RetentionResult{
+		Cohorts: make([]domain.Cohort, 0, len(rows)),
+	}
+
+	for _, r := range rows {
+		res.Cohorts = append(res.Cohorts, domain.Cohort(r))
+	}
+
+	return res, nil
+}
+
+// Close does nothing, the database is shared and closed by its owner.
+func (s store) Close() error {
+	return nil
+}
@@ -0,0 +1,38 @@
+package service
+
+import (
+	"context"
+
+	apikeydomain "github.com/vediagames/onlooker/domain/apikey"
+)
+
+type mock struct{}
+
+func NewMock() apikeydomain.Service {
+	return &mock{}
+}
+
+func (m mock) Create(ctx context.Context, request apikeydomain.CreateRequest) (apikeydomain.CreateResponse, error) {
+	//TODO implement me
+	panic("implement me")
+}
+
+func (m mock) Authenticate(ctx context.Context, request apikeydomain.AuthenticateRequest) (apikeydomain.AuthenticateResponse, error) {
+	//TODO implement me
+	panic("implement me")
+}
+
+func (m mock) List(ctx context.Context, request apikeydomain.ListRequest) (apikeydomain.ListResponse, error) {
+	//TODO implement me
+	panic("implement me")
+}
+
+func (m mock) Revoke(ctx context.Context, request apikeydomain.RevokeRequest) (apikeydomain.RevokeResponse, error) {
+	//TODO implement me
+	panic("implement me")
+}
+
+func (m mock) Ping(ctx context.Context) error {
+	//TODO implement me
+	panic("implement me")
+}
@@ -0,0 +1,172 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	domain "github.com/vediagames/onlooker/domain/apikey"
+	"github.com/vediagames/onlooker/errutil"
+)
+
+const (
+	// keyPrefix marks onlooker API keys, so leaked keys are easy to find.
+	keyPrefix = "onl_"
+	// keyBytes is the number of random bytes of a key.
+	keyBytes = 24
+	// displayPrefixLength is the length of the stored start of a key.
+	displayPrefixLength = len(keyPrefix) + 8
+)
+
+type service struct {
+	store domain.Store
+}
+
+type Config struct {
+	Store domain.Store
+}
+
+func (c Config) Validate() error {
+	var err errutil.Error
+
+	if c.Store == nil {
+		err.Add(fmt.Errorf("store is empty"))
+	}
+
+	return err.Err()
+}
+
+func New(cfg Config) (domain.Service, error) {
+	if ve := cfg.Validate(); ve != nil {
+		return nil, fmt.Errorf("invalid config: %w", ve)
+	}
+
+	return &service{
+		store: cfg.Store,
+	}, nil
+}
+
+func (s service) Create(ctx context.Context, req domain.CreateRequest) (domain.CreateResponse, error) {
+	if err := req.Validate(); err != nil {
+		return domain.CreateResponse{}, fmt.Errorf("invalid request: %w", errutil.WithKind(err, domain.ErrInvalidArgument))
+	}
+
+	key, err := generateKey()
+	if err != nil {
+		return domain.CreateResponse{}, fmt.Errorf("failed to generate key: %w", err)
+	}
+
+	insertRes, err := s.store.Insert(ctx, domain.InsertQuery{
+		Name:   req.Name,
+		Game:   req.Game,
+		Scopes: req.Scopes,
+		Prefix: key[:displayPrefixLength],
+		Hash:   hashKey(key),
+	})
+	if err != nil {
+		return domain.CreateResponse{}, fmt.Errorf("failed to insert: %w", err)
+	}
+
+	res := domain.CreateResponse{
+		UUID:      insertRes.UUID,
+		Key:       key,
+		Prefix:    key[:displayPrefixLength],
+		CreatedAt: insertRes.CreatedAt,
+	}
+
+	if err := res.Validate(); err != nil {
+		return domain.CreateResponse{}, fmt.Errorf("invalid response: %w", err)
+	}
+
+	return res, nil
+}
+
+func (s service) Authenticate(ctx context.Context, req domain.AuthenticateRequest) (domain.AuthenticateResponse, error) {
+	if err := req.Validate(); err != nil {
+		return domain.AuthenticateResponse{}, fmt.Errorf("invalid request: %w", errutil.WithKind(err, domain.ErrInvalidArgument))
+	}
+
+	if !strings.HasPrefix(req.Key, keyPrefix) {
+		return domain.AuthenticateResponse{}, fmt.Errorf("api key: %w", domain.ErrNotFound)
+	}
+
+	getRes, err := s.store.GetByHash(ctx, domain.GetByHashQuery{
+		Hash: hashKey(req.Key),
+	})
+	if err != nil {
+		return domain.AuthenticateResponse{}, fmt.Errorf("failed to get: %w", err)
+	}
+
+	if getRes.Key.RevokedAt != nil {
+		return domain.AuthenticateResponse{}, fmt.Errorf("api key %q is revoked: %w", getRes.Key.UUID, domain.ErrNotFound)
+	}
+
+	return domain.AuthenticateResponse{
+		Principal: domain.Principal{
+			KeyUUID: getRes.Key.UUID,
+			Name:    getRes.Key.Name,
+			Game:    getRes.Key.Game,
+			Scopes:  getRes.Key.Scopes,
+		},
+	}, nil
+}
+
+func (s service) List(ctx context.Context, req domain.ListRequest) (domain.ListResponse, error) {
+	if err := req.Validate(); err != nil {
+		return domain.ListResponse{}, fmt.Errorf("invalid request: %w", errutil.WithKind(err, domain.ErrInvalidArgument))
+	}
+
+	listRes, err := s.store.List(ctx, domain.ListQuery(req))
+	if err != nil {
+		return domain.ListResponse{}, fmt.Errorf("failed to list: %w", err)
+	}
+
+	return domain.ListResponse(listRes), nil
+}
+
+func (s service) Revoke(ctx context.Context, req domain.RevokeRequest) (domain.RevokeResponse, error) {
+	if err := req.Validate(); err != nil {
+		return domain.RevokeResponse{}, fmt.Errorf("invalid request: %w", errutil.WithKind(err, domain.ErrInvalidArgument))
+	}
+
+	revokeRes, err := s.store.Revoke(ctx, domain.RevokeQuery(req))
+	if err != nil {
+		return domain.RevokeResponse{}, fmt.Errorf("failed to revoke: %w", err)
+	}
+
+	res := domain.RevokeResponse(revokeRes)
+
+	if err := res.Validate(); err != nil {
+		return domain.RevokeResponse{}, fmt.Errorf("invalid response: %w", err)
+	}
+
+	return res, nil
+}
+
+func (s service) Ping(ctx context.Context) error {
+	if err := s.store.Ping(ctx); err != nil {
+		return fmt.Errorf("failed to ping store: %w", err)
+	}
+
+	return nil
+}
+
+func generateKey() (string, error) {
+	b := make([]byte, keyBytes)
+
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return keyPrefix + hex.EncodeToString(b), nil
+}
+
+// hashKey hashes a key for storage. Keys are random, so a fast hash
+// is enough to make leaked hashes useless.
+func hashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,154 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	domain "github.com/vediagames/onlooker/domain/apikey"
+	gamedomain "github.com/vediagames/onlooker/domain/game"
+	"github.com/vediagames/onlooker/errutil"
+)
+
+type store struct {
+	gameStore gamedomain.Store
+
+	mu   sync.RWMutex
+	keys map[string]domain.Key
+	// hashes maps key hashes to key UUIDs.
+	hashes map[string]string
+}
+
+type Config struct {
+	// GameStore is used to check that the game of a key exists.
+	GameStore gamedomain.Store
+}
+
+func (c Config) Validate() error {
+	var err errutil.Error
+
+	if c.GameStore == nil {
+		err.Add(fmt.Errorf("game store is empty"))
+	}
+
+	return err.Err()
+}
+
+func New(cfg Config) (domain.Store, error) {
+	if ve := cfg.Validate(); ve != nil {
+		return nil, fmt.Errorf("invalid config: %w", ve)
+	}
+
+	return &store{
+		gameStore: cfg.GameStore,
+		keys:      make(map[string]domain.Key),
+		hashes:    make(map[string]string),
+	}, nil
+}
+
+func (s *store) Insert(ctx context.Context, q domain.InsertQuery) (domain.InsertResult, error) {
+	_, err := s.gameStore.Get(ctx, gamedomain.GetQuery{Slug: q.Game})
+	if errors.Is(err, gamedomain.ErrNotFound) {
+		return domain.InsertResult{}, fmt.Errorf("failed to insert api key: game %q: %w", q.Game, domain.ErrNotFound)
+	}
+	if err != nil {
+		return domain.InsertResult{}, fmt.Errorf("failed to get game: %w", err)
+	}
+
+	key := domain.Key{
+		UUID:      uuid.NewString(),
+		Name:      q.Name,
+		Game:      q.Game,
+		Scopes:    append([]domain.Scope(nil), q.Scopes...),
+		Prefix:    q.Prefix,
+		CreatedAt: time.Now().UTC(),
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.hashes[q.Hash]; ok {
+		return domain.InsertResult{}, fmt.Errorf("api key hash: %w", domain.ErrConflict)
+	}
+
+	s.keys[key.UUID] = key
+	s.hashes[q.Hash] = key.UUID
+
+	return domain.InsertResult{
+		UUID:      key.UUID,
+		CreatedAt: key.CreatedAt,
+	}, nil
+}
+
+func (s *store) GetByHash(ctx context.Context, q domain.GetByHashQuery) (domain.GetByHashResult, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	id, ok := s.hashes[q.Hash]
+	if !ok {
+		return domain.GetByHashResult{}, fmt.Errorf("api key: %w", domain.ErrNotFound)
+	}
+
+	return domain.GetByHashResult{
+		Key: s.keys[id],
+	}, nil
+}
+
+func (s *store) List(ctx context.Context, q domain.ListQuery) (domain.ListResult, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	keys := make([]domain.Key, 0, len(s.keys))
+
+	for _, k := range s.keys {
+		if q.Game != "" && k.Game != q.Game {
+			continue
+		}
+
+		keys = append(keys, k)
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].CreatedAt.Equal(keys[j].CreatedAt) {
+			return keys[i].UUID < keys[j].UUID
+		}
+
+		return keys[i].CreatedAt.Before(keys[j].CreatedAt)
+	})
+
+	return domain.ListResult{
+		Keys: keys,
+	}, nil
+}
+
+func (s *store) Revoke(ctx context.Context, q domain.RevokeQuery) (domain.RevokeResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key, ok := s.keys[q.UUID]
+	if !ok {
+		return domain.RevokeResult{}, fmt.Errorf("api key %q: %w", q.UUID, domain.ErrNotFound)
+	}
+
+	if key.RevokedAt == nil {
+		now := time.Now().UTC()
+		key.RevokedAt = &now
+		s.keys[q.UUID] = key
+	}
+
+	return domain.RevokeResult{
+		RevokedAt: *key.RevokedAt,
+	}, nil
+}
+
+func (s *store) Ping(ctx context.Context) error {
+	return nil
+}
+
+func (s *store) Close() error {
+	return nil
+}
@@ -0,0 +1,43 @@
+package store
+
+import (
+	"context"
+
+	domain "github.com/vediagames/onlooker/domain/apikey"
+)
+
+type mock struct{}
+
+func NewMock() domain.Store {
+	return &mock{}
+}
+
+func (s mock) Insert(ctx context.Context, q domain.InsertQuery) (domain.InsertResult, error) {
+	//TODO implement me
+	panic("implement me")
+}
+
+func (s mock) GetByHash(ctx context.Context, q domain.GetByHashQuery) (domain.GetByHashResult, error) {
+	//TODO implement me
+	panic("implement me")
+}
+
+func (s mock) List(ctx context.Context, q domain.ListQuery) (domain.ListResult, error) {
+	//TODO implement me
+	panic("implement me")
+}
+
+func (s mock) Revoke(ctx context.Context, q domain.RevokeQuery) (domain.RevokeResult, error) {
+	//TODO implement me
+	panic("implement me")
+}
+
+func (s mock) Ping(ctx context.Context) error {
+	//TODO implement me
+	panic("implement me")
+}
+
+func (s mock) Close() error {
+	//TODO implement me
+	panic("implement me")
+}
@@ -0,0 +1,179 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/vediagames/onlooker/database"
+	domain "github.com/vediagames/onlooker/domain/apikey"
+	"github.com/vediagames/onlooker/errutil"
+)
+
+type store struct {
+	db *sqlx.DB
+}
+
+type Config struct {
+	DB *sqlx.DB
+}
+
+func (c Config) Validate() error {
+	var err errutil.Error
+
+	if c.DB == nil {
+		err.Add(fmt.Errorf("db is empty"))
+	}
+
+	return err.Err()
+}
+
+func New(cfg Config) (domain.Store, error) {
+	if ve := cfg.Validate(); ve != nil {
+		return nil, fmt.Errorf("invalid config: %w", ve)
+	}
+
+	return &store{
+		db: cfg.DB,
+	}, nil
+}
+
+type insertResult struct {
+	UUID      string    `db:"uuid"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+func (s store) Insert(ctx context.Context, q domain.InsertQuery) (domain.InsertResult, error) {
+	var res insertResult
+
+	err := s.db.GetContext(ctx, &res, `
+		INSERT INTO api_keys (name, game, scopes, prefix, hash, created_at)
+		VALUES ($1, $2, $3, $4, $5, now())
+		RETURNING uuid, created_at
+	`, q.Name, q.Game, pq.Array(scopeStrings(q.Scopes)), q.Prefix, q.Hash)
+	if err != nil {
+		return domain.InsertResult{}, fmt.Errorf("failed to insert api key: %w", database.TranslateError(err))
+	}
+
+	return domain.InsertResult{
+		UUID:      res.UUID,
+		CreatedAt: res.CreatedAt,
+	}, nil
+}
+
+type key struct {
+	UUID      string         `db:"uuid"`
+	Name      string         `db:"name"`
+	Game      string         `db:"game"`
+	Scopes    pq.StringArray `db:"scopes"`
+	Prefix    string         `db:"prefix"`
+	CreatedAt time.Time      `db:"created_at"`
+	RevokedAt *time.Time     `db:"revoked_at"`
+}
+
+func (k key) toDomain() domain.Key {
+	scopes := make([]domain.Scope, 0, len(k.Scopes))
+	for _, s := range k.Scopes {
+		scopes = append(scopes, domain.Scope(s))
+	}
+
+	return domain.Key{
+		UUID:      k.UUID,
+		Name:      k.Name,
+		Game:      k.Game,
+		Scopes:    scopes,
+		Prefix:    k.Prefix,
+		CreatedAt: k.CreatedAt,
+		RevokedAt: k.RevokedAt,
+	}
+}
+
+func (s store) GetByHash(ctx context.Context, q domain.GetByHashQuery) (domain.GetByHashResult, error) {
+	var row key
+
+	err := s.db.GetContext(ctx, &row, `
+		SELECT uuid, name, game, scopes, prefix, created_at, revoked_at
+		FROM api_keys
+		WHERE hash = $1
+	`, q.Hash)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.GetByHashResult{}, fmt.Errorf("api key: %w", domain.ErrNotFound)
+	}
+	if err != nil {
+		return domain.GetByHashResult{}, fmt.Errorf("failed to get api key: %w", database.TranslateError(err))
+	}
+
+	return domain.GetByHashResult{
+		Key: row.toDomain(),
+	}, nil
+}
+
+func (s store) List(ctx context.Context, q domain.ListQuery) (domain.ListResult, error) {
+	var rows []key
+
+	err := s.db.SelectContext(ctx, &rows, `
+		SELECT uuid, name, game, scopes, prefix, created_at, revoked_at
+		FROM api_keys
+		WHERE $1 = '' OR game = $1
+		ORDER BY created_at, uuid
+	`, q.Game)
+	if err != nil {
+		return domain.ListResult{}, fmt.Errorf("failed to list api keys: %w", database.TranslateError(err))
+	}
+
+	keys := make([]domain.Key, 0, len(rows))
+	for _, r := range rows {
+		keys = append(keys, r.toDomain())
+	}
+
+	return domain.ListResult{
+		Keys: keys,
+	}, nil
+}
+
+func (s store) Revoke(ctx context.Context, q domain.RevokeQuery) (domain.RevokeResult, error) {
+	var revokedAt time.Time
+
+	err := s.db.GetContext(ctx, &revokedAt, `
+		UPDATE api_keys
+		SET revoked_at = COALESCE(revoked_at, now())
+		WHERE uuid = $1
+		RETURNING revoked_at
+	`, q.UUID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.RevokeResult{}, fmt.Errorf("api key %q: %w", q.UUID, domain.ErrNotFound)
+	}
+	if err != nil {
+		return domain.RevokeResult{}, fmt.Errorf("failed to revoke api key: %w", database.TranslateError(err))
+	}
+
+	return domain.RevokeResult{
+		RevokedAt: revokedAt,
+	}, nil
+}
+
+func (s store) Ping(ctx context.Context) error {
+	if err := s.db.PingContext(ctx); err != nil {
+		return fmt.Errorf("failed to ping database: %w", database.TranslateError(err))
+	}
+
+	return nil
+}
+
+// Close does nothing, the database is shared and closed by its owner.
+func (s store) Close() error {
+	return nil
+}
+
+func scopeStrings(scopes []domain.Scope) []string {
+	res := make([]string, 0, len(scopes))
+	for _, s := range scopes {
+		res = append(res, string(s))
+	}
+
+	return res
+}
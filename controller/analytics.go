@@ -0,0 +1,193 @@
+package controller
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	analyticsdomain "github.com/vediagames/onlooker/domain/analytics"
+)
+
+// GetLevelFunnel godoc
+// @Summary  Gets attempts, completions and deaths per level
+// @Produce  json
+// @Tags     analytics, level
+// @Param    from  query     string  false  "Level server time from (RFC3339, inclusive)"
+// @Param    to    query     string  false  "Level server time to (RFC3339, exclusive)"
+// @Param    url   query     string  false  "Session URL"
+// @Param    game  query     string  false  "Game, only for admins"
+// @Success  200   {object}  getLevelFunnelResponse
+// @Failure  400   {object}  httpError
+// @Failure  404   {object}  httpError
+// @Failure  500   {object}  httpError
+// @Failure  503   {object}  httpError
+// @Router   /analytics/levels [get]
+func (c controller) GetLevelFunnel(ctx *gin.Context) {
+	var req getLevelFunnelRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, httpError{Message: err.Error()})
+		return
+	}
+
+	game, err := requestGame(ctx, req.Game)
+	if err != nil {
+		ctx.JSON(errorResponse(err))
+		return
+	}
+
+	res, err := c.analyticsService.LevelFunnel(ctx.Request.Context(), analyticsdomain.LevelFunnelRequest{
+		From: req.From,
+		To:   req.To,
+		URL:  req.URL,
+		Game: game,
+	})
+	if err != nil {
+		ctx.JSON(errorResponse(err))
+		return
+	}
+
+	levels := make([]levelFunnelStepResponse, 0, len(res.Levels))
+	for _, l := range res.Levels {
+		levels = append(levels, levelFunnelStepResponse{
+			Level:          l.Level,
+			Sessions:       l.Sessions,
+			Started:        l.Started,
+			Completed:      l.Completed,
+			Died:           l.Died,
+			Restarted:      l.Restarted,
+			Abandoned:      l.Abandoned,
+			CompletionRate: l.CompletionRate,
+			QuitRate:       l.QuitRate,
+			DropOff:        l.DropOff,
+		})
+	}
+
+	ctx.JSON(http.StatusOK, getLevelFunnelResponse{
+		Levels: levels,
+	})
+}
+
+type getLevelFunnelRequest struct {
+	From time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To   time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+	URL  string    `form:"url"`
+	Game string    `form:"game"`
+}
+
+type levelFunnelStepResponse struct {
+	Level          int     `json:"level"`
+	Sessions       int     `json:"sessions"`
+	Started        int     `json:"started"`
+	Completed      int     `json:"completed"`
+	Died           int     `json:"died"`
+	Restarted      int     `json:"restarted"`
+	Abandoned      int     `json:"abandoned"`
+	CompletionRate float64 `json:"completion_rate"`
+	QuitRate       float64 `json:"quit_rate"`
+	// DropOff is omitted if the next level is not in the result.
+	DropOff *float64 `json:"drop_off,omitempty"`
+}
+
+type getLevelFunnelResponse struct {
+	Levels []levelFunnelStepResponse `json:"levels"`
+}
+
+// GetRetention godoc
+// @Summary      Gets D1, D7 and D30 retention of players by first-seen day
+// @Description  Players are tied together by the player ID of their sessions, anonymous sessions are not counted.
+// @Description  Days are in the timezone of the sessions, or UTC if it is unknown or utc is set.
+// @Description  D1, D7 and D30 are omitted until the day they count has ended in UTC.
+// @Produce      json
+// @Tags         analytics, player
+// @Param        from  query     string  false  "First-seen day from (2006-01-02, inclusive)"
+// @Param        to    query     string  false  "First-seen day to (2006-01-02, exclusive)"
+// @Param        utc   query     bool    false  "Group by UTC day instead of the session timezone"
+// @Param        game  query     string  false  "Game, only for admins"
+// @Success      200   {object}  getRetentionResponse
+// @Failure      400   {object}  httpError
+// @Failure      404   {object}  httpError
+// @Failure      500   {object}  httpError
+// @Failure      503   {object}  httpError
+// @Router       /analytics/retention [get]
+func (c controller) GetRetention(ctx *gin.Context) {
+	var req getRetentionRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, httpError{Message: err.Error()})
+		return
+	}
+
+	game, err := requestGame(ctx, req.Game)
+	if err != nil {
+		ctx.JSON(errorResponse(err))
+		return
+	}
+
+	res, err := c.analyticsService.Retention(ctx.Request.Context(), analyticsdomain.RetentionRequest{
+		From: req.From,
+		To:   req.To,
+		UTC:  req.UTC,
+		Game: game,
+	})
+	if err != nil {
+		ctx.JSON(errorResponse(err))
+		return
+	}
+
+	cohorts := make([]retentionCohortResponse, 0, len(res.Cohorts))
+	for _, c := range res.Cohorts {
+		// c is reused by the loop, the counts are copied before taking their address.
+		day1, day7, day30 := c.Day1, c.Day7, c.Day30
+
+		cohort := retentionCohortResponse{
+			Day:       c.Day.Format(dateLayout),
+			Players:   c.Players,
+			Day1Rate:  c.Day1Rate,
+			Day7Rate:  c.Day7Rate,
+			Day30Rate: c.Day30Rate,
+		}
+
+		if c.Day1Rate != nil {
+			cohort.Day1 = &day1
+		}
+
+		if c.Day7Rate != nil {
+			cohort.Day7 = &day7
+		}
+
+		if c.Day30Rate != nil {
+			cohort.Day30 = &day30
+		}
+
+		cohorts = append(cohorts, cohort)
+	}
+
+	ctx.JSON(http.StatusOK, getRetentionResponse{
+		Cohorts: cohorts,
+	})
+}
+
+// dateLayout is the layout of days in requests and responses.
+const dateLayout = "2006-01-02"
+
+type getRetentionRequest struct {
+	From time.Time `form:"from" time_format:"2006-01-02"`
+	To   time.Time `form:"to" time_format:"2006-01-02"`
+	UTC  bool      `form:"utc"`
+	Game string    `form:"game"`
+}
+
+// retentionCohortResponse omits the retention of days which have not ended yet.
+type retentionCohortResponse struct {
+	Day       string   `json:"day" example:"2024-01-31"`
+	Players   int      `json:"players"`
+	Day1      *int     `json:"d1,omitempty"`
+	Day7      *int     `json:"d7,omitempty"`
+	Day30     *int     `json:"d30,omitempty"`
+	Day1Rate  *float64 `json:"d1_rate,omitempty"`
+	Day7Rate  *float64 `json:"d7_rate,omitempty"`
+	Day30Rate *float64 `json:"d30_rate,omitempty"`
+}
+
+type getRetentionResponse struct {
+	Cohorts []retentionCohortResponse `json:"cohorts"`
+}
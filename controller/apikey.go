@@ -0,0 +1,151 @@
+package controller
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	apikeydomain "github.com/vediagames/onlooker/domain/apikey"
+)
+
+// CreateAPIKey godoc
+// @Summary      Creates API key
+// @Description  The key is only returned in this response, only its hash is stored.
+// @Produce      json
+// @Tags         admin, api key
+// @Accept       json
+// @Param        body  body      createAPIKeyRequest  true  "Create API key"
+// @Success      200   {object}  createAPIKeyResponse
+// @Failure      400   {object}  httpError
+// @Failure      500   {object}  httpError
+// @Failure      503   {object}  httpError
+// @Router       /admin/keys [post]
+func (c controller) CreateAPIKey(ctx *gin.Context) {
+	var req createAPIKeyRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, httpError{Message: err.Error()})
+		return
+	}
+
+	scopes := make([]apikeydomain.Scope, 0, len(req.Scopes))
+	for _, s := range req.Scopes {
+		scopes = append(scopes, apikeydomain.Scope(s))
+	}
+
+	res, err := c.apiKeyService.Create(ctx.Request.Context(), apikeydomain.CreateRequest{
+		Name:   req.Name,
+		Game:   req.Game,
+		Scopes: scopes,
+	})
+	if err != nil {
+		ctx.JSON(errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, createAPIKeyResponse{
+		UUID:      res.UUID,
+		Key:       res.Key,
+		Prefix:    res.Prefix,
+		CreatedAt: res.CreatedAt,
+	})
+}
+
+type createAPIKeyRequest struct {
+	Name   string   `json:"name" example:"web build"`
+	Game   string   `json:"game" example:"grappling-hook"`
+	Scopes []string `json:"scopes" example:"ingest"`
+}
+
+type createAPIKeyResponse struct {
+	UUID      string    `json:"uuid"`
+	Key       string    `json:"key"`
+	Prefix    string    `json:"prefix"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ListAPIKeys godoc
+// @Summary  Lists API keys
+// @Produce  json
+// @Tags     admin, api key
+// @Param    game  query     string  false  "Game"
+// @Success  200   {object}  listAPIKeysResponse
+// @Failure  400   {object}  httpError
+// @Failure  500   {object}  httpError
+// @Failure  503   {object}  httpError
+// @Router   /admin/keys [get]
+func (c controller) ListAPIKeys(ctx *gin.Context) {
+	res, err := c.apiKeyService.List(ctx.Request.Context(), apikeydomain.ListRequest{
+		Game: ctx.Query("game"),
+	})
+	if err != nil {
+		ctx.JSON(errorResponse(err))
+		return
+	}
+
+	keys := make([]apiKeyResponse, 0, len(res.Keys))
+	for _, k := range res.Keys {
+		scopes := make([]string, 0, len(k.Scopes))
+		for _, s := range k.Scopes {
+			scopes = append(scopes, string(s))
+		}
+
+		keys = append(keys, apiKeyResponse{
+			UUID:      k.UUID,
+			Name:      k.Name,
+			Game:      k.Game,
+			Scopes:    scopes,
+			Prefix:    k.Prefix,
+			CreatedAt: k.CreatedAt,
+			RevokedAt: k.RevokedAt,
+		})
+	}
+
+	ctx.JSON(http.StatusOK, listAPIKeysResponse{
+		Keys: keys,
+	})
+}
+
+type apiKeyResponse struct {
+	UUID      string     `json:"uuid"`
+	Name      string     `json:"name"`
+	Game      string     `json:"game"`
+	Scopes    []string   `json:"scopes"`
+	Prefix    string     `json:"prefix"`
+	CreatedAt time.Time  `json:"created_at"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+}
+
+type listAPIKeysResponse struct {
+	Keys []apiKeyResponse `json:"keys"`
+}
+
+// RevokeAPIKey godoc
+// @Summary  Revokes API key
+// @Produce  json
+// @Tags     admin, api key
+// @Param    uuid  path      string  true  "API key UUID"
+// @Success  200   {object}  revokeAPIKeyResponse
+// @Failure  400   {object}  httpError
+// @Failure  404   {object}  httpError
+// @Failure  500   {object}  httpError
+// @Failure  503   {object}  httpError
+// @Router   /admin/keys/{uuid} [delete]
+func (c controller) RevokeAPIKey(ctx *gin.Context) {
+	res, err := c.apiKeyService.Revoke(ctx.Request.Context(), apikeydomain.RevokeRequest{
+		UUID: ctx.Param("uuid"),
+	})
+	if err != nil {
+		ctx.JSON(errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, revokeAPIKeyResponse{
+		UUID:      ctx.Param("uuid"),
+		RevokedAt: res.RevokedAt,
+	})
+}
+
+type revokeAPIKeyResponse struct {
+	UUID      string    `json:"uuid"`
+	RevokedAt time.Time `json:"revoked_at"`
+}
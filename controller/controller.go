@@ -1,9 +1,19 @@
 package controller
 
 import (
+	"errors"
+	"fmt"
+	"net/http"
+
 	"github.com/gin-gonic/gin"
+	analyticsdomain "github.com/vediagames/onlooker/domain/analytics"
+	apikeydomain "github.com/vediagames/onlooker/domain/apikey"
+	gamedomain "github.com/vediagames/onlooker/domain/game"
 	leveldomain "github.com/vediagames/onlooker/domain/level"
+	playerdomain "github.com/vediagames/onlooker/domain/player"
 	sessiondomain "github.com/vediagames/onlooker/domain/session"
+	"github.com/vediagames/onlooker/errutil"
+	"github.com/vediagames/onlooker/metrics"
 )
 
 type Controller interface {
@@ -16,6 +26,31 @@ type Controller interface {
 	HandleEventsComplete(ctx *gin.Context)
 	HandleEventUseGrapplingHook(ctx *gin.Context)
 	HandleEventsUseGrapplingHook(ctx *gin.Context)
+	HandleEvent(ctx *gin.Context)
+	HandleEvents(ctx *gin.Context)
+	GetSession(ctx *gin.Context)
+	ListSessionLevels(ctx *gin.Context)
+	HeartbeatSession(ctx *gin.Context)
+	EndSession(ctx *gin.Context)
+	GetLevel(ctx *gin.Context)
+	ListLevelEvents(ctx *gin.Context)
+	GetLevelGrid(ctx *gin.Context)
+	GetLevelHeatmap(ctx *gin.Context)
+	SetLevelBackground(ctx *gin.Context)
+	GetLevelFunnel(ctx *gin.Context)
+	GetRetention(ctx *gin.Context)
+	Healthz(ctx *gin.Context)
+	Readyz(ctx *gin.Context)
+	CreateAPIKey(ctx *gin.Context)
+	ListAPIKeys(ctx *gin.Context)
+	RevokeAPIKey(ctx *gin.Context)
+	CreateGame(ctx *gin.Context)
+	ListGames(ctx *gin.Context)
+	RotateGameSigningSecret(ctx *gin.Context)
+	DisableGameSigning(ctx *gin.Context)
+	SetGameAllowedOrigins(ctx *gin.Context)
+	GetPlayer(ctx *gin.Context)
+	ListPlayerSessions(ctx *gin.Context)
 }
 
 type key string
@@ -29,24 +64,142 @@ const (
 )
 
 type controller struct {
-	levelService   leveldomain.Service
-	sessionService sessiondomain.Service
+	levelService     leveldomain.Service
+	sessionService   sessiondomain.Service
+	analyticsService analyticsdomain.Service
+	apiKeyService    apikeydomain.Service
+	gameService      gamedomain.Service
+	playerService    playerdomain.Service
+	metrics          *metrics.Metrics
 }
 
 type Config struct {
-	LevelService   leveldomain.Service
-	SessionService sessiondomain.Service
+	LevelService     leveldomain.Service
+	SessionService   sessiondomain.Service
+	AnalyticsService analyticsdomain.Service
+	APIKeyService    apikeydomain.Service
+	GameService      gamedomain.Service
+	PlayerService    playerdomain.Service
+	// Metrics records the size of batches, optional.
+	Metrics *metrics.Metrics
 }
 
 func New(cfg Config) Controller {
 	return &controller{
-		levelService:   cfg.LevelService,
-		sessionService: cfg.SessionService,
+		levelService:     cfg.LevelService,
+		sessionService:   cfg.SessionService,
+		analyticsService: cfg.AnalyticsService,
+		apiKeyService:    cfg.APIKeyService,
+		gameService:      cfg.GameService,
+		playerService:    cfg.PlayerService,
+		metrics:          cfg.Metrics,
+	}
+}
+
+const HeaderIdempotencyKey = "Idempotency-Key"
+
+// idempotencyKey returns key or, if it is empty, the Idempotency-Key header.
+func idempotencyKey(ctx *gin.Context, key string) string {
+	if key != "" {
+		return key
+	}
+
+	return ctx.GetHeader(HeaderIdempotencyKey)
+}
+
+// batchIdempotencyKey returns key or, if it is empty, the Idempotency-Key
+// header suffixed with the index of the item in the batch.
+func batchIdempotencyKey(ctx *gin.Context, key string, index int) string {
+	if key != "" {
+		return key
 	}
+
+	header := ctx.GetHeader(HeaderIdempotencyKey)
+	if header == "" {
+		return ""
+	}
+
+	return fmt.Sprintf("%s/%d", header, index)
+}
+
+// principalGame returns the game of the API key of the request,
+// empty for admins, which are not restricted to a game.
+func principalGame(ctx *gin.Context) string {
+	principal, _ := apikeydomain.PrincipalFromContext(ctx.Request.Context())
+	return principal.Game
+}
+
+// requestGame returns the game a request for game is restricted to. API keys
+// are restricted to their own game, admins to game, all games if it is empty.
+func requestGame(ctx *gin.Context, game string) (string, error) {
+	principalGame := principalGame(ctx)
+	if principalGame == "" {
+		return game, nil
+	}
+
+	if game != "" && game != principalGame {
+		return "", fmt.Errorf("game %q: %w", game, errutil.KindPermissionDenied)
+	}
+
+	return principalGame, nil
+}
+
+// sessionGame returns the game a new session belongs to, the default game if
+// neither the API key nor the request set one.
+func sessionGame(ctx *gin.Context, game string) (string, error) {
+	game, err := requestGame(ctx, game)
+	if err != nil {
+		return "", err
+	}
+
+	if game == "" {
+		return gamedomain.DefaultGame, nil
+	}
+
+	return game, nil
 }
 
 type httpError struct {
-	Message string `json:"message" example:"status bad request"`
+	Message string   `json:"message" example:"status bad request"`
+	Code    string   `json:"code,omitempty" example:"invalid_argument"`
+	Errors  []string `json:"errors,omitempty" example:"uuid must be set"`
+}
+
+// errorResponse returns the status code and body for an error returned by a service.
+func errorResponse(err error) (int, httpError) {
+	res := httpError{
+		Message: err.Error(),
+		Code:    "internal",
+	}
+
+	var e errutil.Error
+	if errors.As(err, &e) {
+		for _, fe := range e.Errors() {
+			res.Errors = append(res.Errors, fe.Error())
+		}
+	}
+
+	status := http.StatusInternalServerError
+
+	switch {
+	case errors.Is(err, errutil.KindInvalidArgument):
+		status = http.StatusBadRequest
+		res.Code = string(errutil.KindInvalidArgument)
+	case errors.Is(err, errutil.KindNotFound):
+		status = http.StatusNotFound
+		res.Code = string(errutil.KindNotFound)
+	case errors.Is(err, errutil.KindConflict):
+		status = http.StatusConflict
+		res.Code = string(errutil.KindConflict)
+	case errors.Is(err, errutil.KindUnavailable):
+		status = http.StatusServiceUnavailable
+		res.Code = string(errutil.KindUnavailable)
+	case errors.Is(err, errutil.KindPermissionDenied):
+		status = http.StatusForbidden
+		res.Code = string(errutil.KindPermissionDenied)
+	}
+
+	return status, res
 }
 
 type helloResponse struct {
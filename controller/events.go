@@ -0,0 +1,267 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+	leveldomain "github.com/vediagames/onlooker/domain/level"
+	sessiondomain "github.com/vediagames/onlooker/domain/session"
+	"github.com/vediagames/onlooker/errutil"
+)
+
+const (
+	eventTypeSession = "session"
+	eventTypeLevel   = "level"
+)
+
+// HandleEvents godoc
+// @Summary      Logs a batch of sessions, levels and level events
+// @Description  Items are processed in order. Sessions and levels can set a client generated id,
+// @Description  which later items can use instead of the UUID in session_uuid and uuid.
+// @Produce      json
+// @Tags         session, level, events
+// @Accept       json
+// @Param        Idempotency-Key  header    string                    false  "Key to deduplicate retries"
+// @Param        body             body      handleMixedEventsRequest  true   "Log events"
+// @Success      200              {object}  handleMixedEventsResponse
+// @Failure      400              {object}  httpError
+// @Failure      404              {object}  httpError
+// @Failure      409              {object}  httpError
+// @Failure      500              {object}  httpError
+// @Failure      503              {object}  httpError
+// @Router       /events [post]
+func (c controller) HandleEvents(ctx *gin.Context) {
+	var req handleMixedEventsRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, httpError{Message: err.Error()})
+		return
+	}
+
+	zerolog.Ctx(ctx.Request.Context()).Info().Msgf("inserting %d mixed events", len(req.Events))
+
+	c.metrics.ObserveBatchSize(ctx.FullPath(), len(req.Events))
+
+	ip := ctx.GetString(KeyRealIP.String())
+	if ip == "" {
+		ip = "Not found"
+	}
+
+	res := handleMixedEventsResponse{
+		Responses: make([]handleMixedEventsItemResponse, len(req.Events)),
+	}
+
+	game := principalGame(ctx)
+
+	// uuids maps the client generated ids to the UUIDs of created objects.
+	uuids := make(map[string]string)
+
+	resolve := func(id string) string {
+		if uuid, ok := uuids[id]; ok {
+			return uuid
+		}
+
+		return id
+	}
+
+	// Consecutive level events are logged as one batch.
+	var (
+		pending        []leveldomain.EventRequest
+		pendingIndexes []int
+	)
+
+	flush := func() error {
+		if len(pending) == 0 {
+			return nil
+		}
+
+		logRes, err := c.levelService.LogEvents(ctx.Request.Context(), leveldomain.LogEventsRequest{
+			Requests: pending,
+		})
+		if err != nil {
+			return err
+		}
+
+		for j, r := range logRes.Results {
+			res.Responses[pendingIndexes[j]].setResult(r.UUID, r.ServerTime, r.Err)
+		}
+
+		pending, pendingIndexes = nil, nil
+
+		return nil
+	}
+
+	for i, e := range req.Events {
+		res.Responses[i].ID = e.ID
+		res.Responses[i].Type = e.Type
+
+		key := batchIdempotencyKey(ctx, e.IdempotencyKey, i)
+
+		switch e.Type {
+		case eventTypeSession, eventTypeLevel:
+			if err := flush(); err != nil {
+				ctx.JSON(errorResponse(err))
+				return
+			}
+		}
+
+		switch e.Type {
+		case eventTypeSession:
+			game, err := sessionGame(ctx, e.Game)
+			if err != nil {
+				res.Responses[i].setResult("", time.Time{}, err)
+				continue
+			}
+
+			createRes, err := c.sessionService.Create(ctx.Request.Context(), sessiondomain.CreateRequest{
+				Game:           game,
+				ClientTime:     e.ClientTime,
+				IP:             ip,
+				URL:            e.URL,
+				Timezone:       e.Timezone,
+				Metadata:       e.Metadata,
+				IdempotencyKey: key,
+			})
+			res.Responses[i].setResult(createRes.UUID, createRes.ServerTime, err)
+
+			if err == nil && e.ID != "" {
+				uuids[e.ID] = createRes.UUID
+			}
+		case eventTypeLevel:
+			createRes, err := c.levelService.Create(ctx.Request.Context(), leveldomain.CreateRequest{
+				SessionUUID:    resolve(e.SessionUUID),
+				Level:          e.Level,
+				ClientTime:     e.ClientTime,
+				Metadata:       e.Metadata,
+				IdempotencyKey: key,
+				Game:           game,
+			})
+			res.Responses[i].setResult(createRes.UUID, createRes.ServerTime, err)
+
+			if err == nil && e.ID != "" {
+				uuids[e.ID] = createRes.UUID
+			}
+		case "":
+			res.Responses[i].setResult("", time.Time{}, errutil.New(errutil.KindInvalidArgument, fmt.Errorf("type must be set")))
+		default:
+			pending = append(pending, e.eventRequest(resolve(e.UUID), key, game))
+			pendingIndexes = append(pendingIndexes, i)
+		}
+	}
+
+	if err := flush(); err != nil {
+		ctx.JSON(errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, res)
+}
+
+type handleMixedEventsRequest struct {
+	Events []mixedEvent `json:"events"`
+}
+
+// mixedEvent is a session, a level or a level event depending on its type.
+type mixedEvent struct {
+	// Type is session, level or the name of a level event.
+	Type string `json:"type" example:"death"`
+	// ID is a client generated id of a session or level.
+	ID         string                 `json:"id"`
+	ClientTime time.Time              `json:"client_time"`
+	Metadata   map[string]interface{} `json:"metadata"`
+	// IdempotencyKey deduplicates retries, defaults to the Idempotency-Key
+	// header suffixed with the index of the item.
+	IdempotencyKey string `json:"idempotency_key"`
+
+	// Session fields.
+	URL      string `json:"url"`
+	Timezone string `json:"timezone"`
+	// Game defaults to the game of the API key, otherwise to the default game.
+	Game string `json:"game"`
+
+	// Level fields.
+	SessionUUID string `json:"session_uuid"`
+	Level       int    `json:"level"`
+
+	// Level event fields.
+	UUID                  string `json:"uuid"`
+	Achievement           string `json:"achievement"`
+	CompletionTimeSeconds int    `json:"completion_time_seconds"`
+	// Position is where the event happened in world coordinates, only for events with a position.
+	Position *position `json:"position"`
+	// Cause is what killed the player, only for deaths.
+	Cause string `json:"cause"`
+}
+
+func (e mixedEvent) eventRequest(levelUUID, key, game string) leveldomain.EventRequest {
+	event := leveldomain.Event(strings.ReplaceAll(e.Type, "-", "_"))
+
+	switch event {
+	case leveldomain.EventDeath:
+		return leveldomain.LogDeathRequest{
+			UUID:           levelUUID,
+			ClientTime:     e.ClientTime,
+			Metadata:       e.Metadata,
+			IdempotencyKey: key,
+			Game:           game,
+			Position:       e.Position.toDomain(),
+			Cause:          e.Cause,
+		}
+	case leveldomain.EventComplete:
+		return leveldomain.LogCompleteRequest{
+			UUID:           levelUUID,
+			ClientTime:     e.ClientTime,
+			Achievement:    leveldomain.Achievement(e.Achievement),
+			CompletionTime: time.Duration(e.CompletionTimeSeconds),
+			Metadata:       e.Metadata,
+			IdempotencyKey: key,
+			Game:           game,
+		}
+	case leveldomain.EventGrapplingHookUsage:
+		return leveldomain.LogGrapplingHookUsageRequest{
+			UUID:           levelUUID,
+			ClientTime:     e.ClientTime,
+			Metadata:       e.Metadata,
+			IdempotencyKey: key,
+			Game:           game,
+			Position:       e.Position.toDomain(),
+		}
+	default:
+		return leveldomain.LogEventRequest{
+			UUID:           levelUUID,
+			Event:          event,
+			ClientTime:     e.ClientTime,
+			Metadata:       e.Metadata,
+			IdempotencyKey: key,
+			Game:           game,
+			Position:       e.Position.toDomain(),
+			Cause:          e.Cause,
+		}
+	}
+}
+
+type handleMixedEventsItemResponse struct {
+	ID         string     `json:"id,omitempty"`
+	Type       string     `json:"type"`
+	UUID       string     `json:"uuid,omitempty"`
+	ServerTime *time.Time `json:"server_time,omitempty"`
+	Error      *httpError `json:"error,omitempty"`
+}
+
+func (r *handleMixedEventsItemResponse) setResult(uuid string, serverTime time.Time, err error) {
+	if err != nil {
+		_, httpErr := errorResponse(err)
+		r.Error = &httpErr
+		return
+	}
+
+	r.UUID = uuid
+	r.ServerTime = &serverTime
+}
+
+type handleMixedEventsResponse struct {
+	Responses []handleMixedEventsItemResponse `json:"responses"`
+}
@@ -0,0 +1,197 @@
+package controller
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	gamedomain "github.com/vediagames/onlooker/domain/game"
+)
+
+// CreateGame godoc
+// @Summary  Creates game
+// @Produce  json
+// @Tags     admin, game
+// @Accept   json
+// @Param    body  body      createGameRequest  true  "Create game"
+// @Success  200   {object}  gameResponse
+// @Failure  400   {object}  httpError
+// @Failure  409   {object}  httpError
+// @Failure  500   {object}  httpError
+// @Failure  503   {object}  httpError
+// @Router   /admin/games [post]
+func (c controller) CreateGame(ctx *gin.Context) {
+	var req createGameRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, httpError{Message: err.Error()})
+		return
+	}
+
+	res, err := c.gameService.Create(ctx.Request.Context(), gamedomain.CreateRequest{
+		Slug: req.Slug,
+		Name: req.Name,
+	})
+	if err != nil {
+		ctx.JSON(errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newGameResponse(gamedomain.Game{
+		Slug:      req.Slug,
+		Name:      req.Name,
+		CreatedAt: res.CreatedAt,
+	}))
+}
+
+type createGameRequest struct {
+	Slug string `json:"slug" example:"grappling-hook"`
+	Name string `json:"name" example:"Grappling Hook"`
+}
+
+type gameResponse struct {
+	Slug      string    `json:"slug"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+	// Signed is set if requests from the API keys of the game must be signed.
+	Signed bool `json:"signed"`
+	// AllowedOrigins are the origins the game is served from, any origin if empty.
+	AllowedOrigins []string `json:"allowed_origins"`
+}
+
+func newGameResponse(g gamedomain.Game) gameResponse {
+	origins := g.AllowedOrigins
+	if origins == nil {
+		origins = []string{}
+	}
+
+	return gameResponse{
+		Slug:           g.Slug,
+		Name:           g.Name,
+		CreatedAt:      g.CreatedAt,
+		Signed:         g.SigningSecret != "",
+		AllowedOrigins: origins,
+	}
+}
+
+// ListGames godoc
+// @Summary  Lists games
+// @Produce  json
+// @Tags     admin, game
+// @Success  200  {object}  listGamesResponse
+// @Failure  500  {object}  httpError
+// @Failure  503  {object}  httpError
+// @Router   /admin/games [get]
+func (c controller) ListGames(ctx *gin.Context) {
+	res, err := c.gameService.List(ctx.Request.Context(), gamedomain.ListRequest{})
+	if err != nil {
+		ctx.JSON(errorResponse(err))
+		return
+	}
+
+	games := make([]gameResponse, 0, len(res.Games))
+	for _, g := range res.Games {
+		games = append(games, newGameResponse(g))
+	}
+
+	ctx.JSON(http.StatusOK, listGamesResponse{
+		Games: games,
+	})
+}
+
+type listGamesResponse struct {
+	Games []gameResponse `json:"games"`
+}
+
+// RotateGameSigningSecret godoc
+// @Summary      Rotates signing secret of game
+// @Description  Requests from the API keys of the game must be signed with the new secret from now on,
+// @Description  see the X-Onlooker-Timestamp and X-Onlooker-Signature headers. The secret is only returned in this response.
+// @Produce      json
+// @Tags         admin, game
+// @Param        slug  path      string  true  "Game slug"
+// @Success      200   {object}  rotateGameSigningSecretResponse
+// @Failure      400   {object}  httpError
+// @Failure      404   {object}  httpError
+// @Failure      500   {object}  httpError
+// @Failure      503   {object}  httpError
+// @Router       /admin/games/{slug}/signing-secret [post]
+func (c controller) RotateGameSigningSecret(ctx *gin.Context) {
+	res, err := c.gameService.RotateSigningSecret(ctx.Request.Context(), gamedomain.RotateSigningSecretRequest{
+		Slug: ctx.Param("slug"),
+	})
+	if err != nil {
+		ctx.JSON(errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, rotateGameSigningSecretResponse{
+		Slug:          ctx.Param("slug"),
+		SigningSecret: res.SigningSecret,
+	})
+}
+
+type rotateGameSigningSecretResponse struct {
+	Slug          string `json:"slug"`
+	SigningSecret string `json:"signing_secret"`
+}
+
+// DisableGameSigning godoc
+// @Summary  Disables request signing of game
+// @Produce  json
+// @Tags     admin, game
+// @Param    slug  path  string  true  "Game slug"
+// @Success  204
+// @Failure  400  {object}  httpError
+// @Failure  404  {object}  httpError
+// @Failure  500  {object}  httpError
+// @Failure  503  {object}  httpError
+// @Router   /admin/games/{slug}/signing-secret [delete]
+func (c controller) DisableGameSigning(ctx *gin.Context) {
+	err := c.gameService.DisableSigning(ctx.Request.Context(), gamedomain.DisableSigningRequest{
+		Slug: ctx.Param("slug"),
+	})
+	if err != nil {
+		ctx.JSON(errorResponse(err))
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// SetGameAllowedOrigins godoc
+// @Summary      Sets allowed origins of game
+// @Description  Browsers may only send requests with the API keys of the game from these origins and sessions
+// @Description  with another url are rejected or flagged. Any origin is allowed if the list is empty.
+// @Produce      json
+// @Tags         admin, game
+// @Accept       json
+// @Param        slug  path  string                        true  "Game slug"
+// @Param        body  body  setGameAllowedOriginsRequest  true  "Allowed origins"
+// @Success      204
+// @Failure      400  {object}  httpError
+// @Failure      404  {object}  httpError
+// @Failure      500  {object}  httpError
+// @Failure      503  {object}  httpError
+// @Router       /admin/games/{slug}/origins [put]
+func (c controller) SetGameAllowedOrigins(ctx *gin.Context) {
+	var req setGameAllowedOriginsRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, httpError{Message: err.Error()})
+		return
+	}
+
+	err := c.gameService.SetAllowedOrigins(ctx.Request.Context(), gamedomain.SetAllowedOriginsRequest{
+		Slug:           ctx.Param("slug"),
+		AllowedOrigins: req.AllowedOrigins,
+	})
+	if err != nil {
+		ctx.JSON(errorResponse(err))
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+type setGameAllowedOriginsRequest struct {
+	AllowedOrigins []string `json:"allowed_origins" example:"https://vediagames.com"`
+}
@@ -0,0 +1,78 @@
+package controller
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+)
+
+const (
+	healthStatusOK    = "ok"
+	healthStatusError = "error"
+
+	pingTimeout = 2 * time.Second
+)
+
+// Healthz reports that the server is running. It does not check dependencies,
+// so a broken database does not get the instance restarted.
+func (c controller) Healthz(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, healthResponse{
+		Status: healthStatusOK,
+	})
+}
+
+// Readyz reports whether the stores are reachable and the instance can serve requests.
+func (c controller) Readyz(ctx *gin.Context) {
+	checks := map[string]func(context.Context) error{
+		"level_store":   c.levelService.Ping,
+		"session_store": c.sessionService.Ping,
+		"api_key_store": c.apiKeyService.Ping,
+	}
+
+	res := healthResponse{
+		Status: healthStatusOK,
+		Checks: make(map[string]healthCheck, len(checks)),
+	}
+
+	for name, ping := range checks {
+		pingCtx, cancel := context.WithTimeout(ctx.Request.Context(), pingTimeout)
+		err := ping(pingCtx)
+		cancel()
+
+		if err != nil {
+			// The endpoint is not authenticated, so the error, which can name
+			// the database host, is only logged.
+			zerolog.Ctx(ctx.Request.Context()).Error().Err(err).Msgf("%s is not ready", name)
+
+			res.Status = healthStatusError
+			res.Checks[name] = healthCheck{
+				Status: healthStatusError,
+			}
+
+			continue
+		}
+
+		res.Checks[name] = healthCheck{
+			Status: healthStatusOK,
+		}
+	}
+
+	code := http.StatusOK
+	if res.Status != healthStatusOK {
+		code = http.StatusServiceUnavailable
+	}
+
+	ctx.JSON(code, res)
+}
+
+type healthCheck struct {
+	Status string `json:"status" example:"ok"`
+}
+
+type healthResponse struct {
+	Status string                 `json:"status" example:"ok"`
+	Checks map[string]healthCheck `json:"checks,omitempty"`
+}
@@ -2,11 +2,13 @@ package controller
 
 import (
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/rs/zerolog"
 	leveldomain "github.com/vediagames/onlooker/domain/level"
+	"github.com/vediagames/onlooker/pagination"
 )
 
 // CreateLevel godoc
@@ -14,11 +16,14 @@ import (
 // @Produce  json
 // @Tags     level, create
 // @Accept   json
-// @Param    body  body      createLevelRequest  true  "Create level"
-// @Success  200   {object}  createLevelResponse
-// @Failure  400   {object}  httpError
-// @Failure  404   {object}  httpError
-// @Failure  500   {object}  httpError
+// @Param    Idempotency-Key  header    string              false  "Key to deduplicate retries"
+// @Param    body             body      createLevelRequest  true   "Create level"
+// @Success  200              {object}  createLevelResponse
+// @Failure  400              {object}  httpError
+// @Failure  404              {object}  httpError
+// @Failure  409              {object}  httpError
+// @Failure  500              {object}  httpError
+// @Failure  503              {object}  httpError
 // @Router   /level [post]
 func (c controller) CreateLevel(ctx *gin.Context) {
 	var req createLevelRequest
@@ -28,12 +33,15 @@ func (c controller) CreateLevel(ctx *gin.Context) {
 	}
 
 	res, err := c.levelService.Create(ctx.Request.Context(), leveldomain.CreateRequest{
-		SessionUUID: req.SessionUUID,
-		Level:       req.Level,
-		ClientTime:  req.ClientTime,
+		SessionUUID:    req.SessionUUID,
+		Level:          req.Level,
+		ClientTime:     req.ClientTime,
+		Metadata:       req.Metadata,
+		IdempotencyKey: idempotencyKey(ctx, req.IdempotencyKey),
+		Game:           principalGame(ctx),
 	})
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, httpError{Message: err.Error()})
+		ctx.JSON(errorResponse(err))
 		return
 	}
 
@@ -44,9 +52,11 @@ func (c controller) CreateLevel(ctx *gin.Context) {
 }
 
 type createLevelRequest struct {
-	SessionUUID string    `json:"session_uuid"`
-	Level       int       `json:"level"`
-	ClientTime  time.Time `json:"client_time"`
+	SessionUUID    string                 `json:"session_uuid"`
+	Level          int                    `json:"level"`
+	ClientTime     time.Time              `json:"client_time"`
+	Metadata       map[string]interface{} `json:"metadata"`
+	IdempotencyKey string                 `json:"idempotency_key"`
 }
 
 type createLevelResponse struct {
@@ -59,11 +69,14 @@ type createLevelResponse struct {
 // @Produce  json
 // @Tags     level, event, death
 // @Accept   json
-// @Param    body  body      handleEventDeathRequest  true  "Log death"
-// @Success  200   {object}  handleEventDeathResponse
-// @Failure  400   {object}  httpError
-// @Failure  404   {object}  httpError
-// @Failure  500   {object}  httpError
+// @Param    Idempotency-Key  header    string                   false  "Key to deduplicate retries"
+// @Param    body             body      handleEventDeathRequest  true   "Log death"
+// @Success  200              {object}  handleEventDeathResponse
+// @Failure  400              {object}  httpError
+// @Failure  404              {object}  httpError
+// @Failure  409              {object}  httpError
+// @Failure  500              {object}  httpError
+// @Failure  503              {object}  httpError
 // @Router   /level/event/death [post]
 func (c controller) HandleEventDeath(ctx *gin.Context) {
 	var req handleEventDeathRequest
@@ -73,11 +86,16 @@ func (c controller) HandleEventDeath(ctx *gin.Context) {
 	}
 
 	res, err := c.levelService.LogDeath(ctx.Request.Context(), leveldomain.LogDeathRequest{
-		UUID:       req.UUID,
-		ClientTime: req.ClientTime,
+		UUID:           req.UUID,
+		ClientTime:     req.ClientTime,
+		Metadata:       req.Metadata,
+		IdempotencyKey: idempotencyKey(ctx, req.IdempotencyKey),
+		Game:           principalGame(ctx),
+		Position:       req.Position.toDomain(),
+		Cause:          req.Cause,
 	})
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, httpError{Message: err.Error()})
+		ctx.JSON(errorResponse(err))
 		return
 	}
 
@@ -88,8 +106,37 @@ func (c controller) HandleEventDeath(ctx *gin.Context) {
 }
 
 type handleEventDeathRequest struct {
-	UUID       string    `json:"uuid"`
-	ClientTime time.Time `json:"client_time"`
+	UUID           string                 `json:"uuid"`
+	ClientTime     time.Time              `json:"client_time"`
+	Metadata       map[string]interface{} `json:"metadata"`
+	IdempotencyKey string                 `json:"idempotency_key"`
+	// Position is where the player died in world coordinates, optional.
+	Position *position `json:"position"`
+	// Cause is what killed the player, optional.
+	Cause string `json:"cause" example:"spikes"`
+}
+
+// position is a position in world coordinates of a level, coordinates are
+// within -1e9 and 1e9.
+type position struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+}
+
+func newPosition(p *leveldomain.Position) *position {
+	if p == nil {
+		return nil
+	}
+
+	return &position{X: p.X, Y: p.Y}
+}
+
+func (p *position) toDomain() *leveldomain.Position {
+	if p == nil {
+		return nil
+	}
+
+	return &leveldomain.Position{X: p.X, Y: p.Y}
 }
 
 type handleEventDeathResponse struct {
@@ -102,11 +149,14 @@ type handleEventDeathResponse struct {
 // @Produce  json
 // @Tags     level, event, complete
 // @Accept   json
-// @Param    body  body      handleEventCompleteRequest  true  "Log completion"
-// @Success  200   {object}  handleEventCompleteResponse
-// @Failure  400   {object}  httpError
-// @Failure  404   {object}  httpError
-// @Failure  500   {object}  httpError
+// @Param    Idempotency-Key  header    string                      false  "Key to deduplicate retries"
+// @Param    body             body      handleEventCompleteRequest  true   "Log completion"
+// @Success  200              {object}  handleEventCompleteResponse
+// @Failure  400              {object}  httpError
+// @Failure  404              {object}  httpError
+// @Failure  409              {object}  httpError
+// @Failure  500              {object}  httpError
+// @Failure  503              {object}  httpError
 // @Router   /level/event/complete [post]
 func (c controller) HandleEventComplete(ctx *gin.Context) {
 	var req handleEventCompleteRequest
@@ -120,9 +170,12 @@ func (c controller) HandleEventComplete(ctx *gin.Context) {
 		ClientTime:     req.ClientTime,
 		Achievement:    leveldomain.Achievement(req.Achievement),
 		CompletionTime: time.Duration(req.CompletionTimeSeconds),
+		Metadata:       req.Metadata,
+		IdempotencyKey: idempotencyKey(ctx, req.IdempotencyKey),
+		Game:           principalGame(ctx),
 	})
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, httpError{Message: err.Error()})
+		ctx.JSON(errorResponse(err))
 		return
 	}
 
@@ -133,10 +186,12 @@ func (c controller) HandleEventComplete(ctx *gin.Context) {
 }
 
 type handleEventCompleteRequest struct {
-	UUID                  string    `json:"uuid"`
-	ClientTime            time.Time `json:"client_time"`
-	Achievement           string    `json:"achievement"`
-	CompletionTimeSeconds int       `json:"completion_time_seconds"`
+	UUID                  string                 `json:"uuid"`
+	ClientTime            time.Time              `json:"client_time"`
+	Achievement           string                 `json:"achievement"`
+	CompletionTimeSeconds int                    `json:"completion_time_seconds"`
+	Metadata              map[string]interface{} `json:"metadata"`
+	IdempotencyKey        string                 `json:"idempotency_key"`
 }
 
 type handleEventCompleteResponse struct {
@@ -149,11 +204,14 @@ type handleEventCompleteResponse struct {
 // @Produce  json
 // @Tags     level, grappling hook, event
 // @Accept   json
-// @Param    body  body      handleEventUseGrapplingHookRequest  true  "Log grappling hook usage"
-// @Success  200   {object}  handleEventUseGrapplingHookResponse
-// @Failure  400   {object}  httpError
-// @Failure  404   {object}  httpError
-// @Failure  500   {object}  httpError
+// @Param    Idempotency-Key  header    string                              false  "Key to deduplicate retries"
+// @Param    body             body      handleEventUseGrapplingHookRequest  true   "Log grappling hook usage"
+// @Success  200              {object}  handleEventUseGrapplingHookResponse
+// @Failure  400              {object}  httpError
+// @Failure  404              {object}  httpError
+// @Failure  409              {object}  httpError
+// @Failure  500              {object}  httpError
+// @Failure  503              {object}  httpError
 // @Router   /level/event/grappling-hook-usage [post]
 func (c controller) HandleEventUseGrapplingHook(ctx *gin.Context) {
 	var req handleEventUseGrapplingHookRequest
@@ -163,11 +221,15 @@ func (c controller) HandleEventUseGrapplingHook(ctx *gin.Context) {
 	}
 
 	res, err := c.levelService.LogGrapplingHookUsage(ctx.Request.Context(), leveldomain.LogGrapplingHookUsageRequest{
-		UUID:       req.UUID,
-		ClientTime: req.ClientTime,
+		UUID:           req.UUID,
+		ClientTime:     req.ClientTime,
+		Metadata:       req.Metadata,
+		IdempotencyKey: idempotencyKey(ctx, req.IdempotencyKey),
+		Game:           principalGame(ctx),
+		Position:       req.Position.toDomain(),
 	})
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, httpError{Message: err.Error()})
+		ctx.JSON(errorResponse(err))
 		return
 	}
 
@@ -178,8 +240,12 @@ func (c controller) HandleEventUseGrapplingHook(ctx *gin.Context) {
 }
 
 type handleEventUseGrapplingHookRequest struct {
-	UUID       string    `json:"uuid"`
-	ClientTime time.Time `json:"client_time"`
+	UUID           string                 `json:"uuid"`
+	ClientTime     time.Time              `json:"client_time"`
+	Metadata       map[string]interface{} `json:"metadata"`
+	IdempotencyKey string                 `json:"idempotency_key"`
+	// Position is where the grappling hook was used in world coordinates, optional.
+	Position *position `json:"position"`
 }
 
 type handleEventUseGrapplingHookResponse struct {
@@ -192,11 +258,14 @@ type handleEventUseGrapplingHookResponse struct {
 // @Produce  json
 // @Tags     level, events, complete
 // @Accept   json
-// @Param    body  body      handleEventsCompleteRequest  true  "Log completion"
-// @Success  200   {object}  handleEventsCompleteResponse
-// @Failure  400   {object}  httpError
-// @Failure  404   {object}  httpError
-// @Failure  500   {object}  httpError
+// @Param    Idempotency-Key  header    string                       false  "Key to deduplicate retries"
+// @Param    body             body      handleEventsCompleteRequest  true   "Log completion"
+// @Success  200              {object}  handleEventsResponse
+// @Failure  400              {object}  httpError
+// @Failure  404              {object}  httpError
+// @Failure  409              {object}  httpError
+// @Failure  500              {object}  httpError
+// @Failure  503              {object}  httpError
 // @Router   /level/events/complete [post]
 func (c controller) HandleEventsComplete(ctx *gin.Context) {
 	var req handleEventsCompleteRequest
@@ -205,38 +274,28 @@ func (c controller) HandleEventsComplete(ctx *gin.Context) {
 		return
 	}
 
-	res := handleEventsCompleteResponse{
-		Responses: make([]handleEventCompleteResponse, 0, len(req.Requests)),
-	}
-
-	zerolog.Ctx(ctx.Request.Context()).Info().Msgf("inserting %d events", len(req.Requests))
+	game := principalGame(ctx)
 
-	for _, r := range req.Requests {
-		logRes, err := c.levelService.LogComplete(ctx.Request.Context(), leveldomain.LogCompleteRequest{
+	requests := make([]leveldomain.EventRequest, 0, len(req.Requests))
+	for i, r := range req.Requests {
+		requests = append(requests, leveldomain.LogCompleteRequest{
 			UUID:           r.UUID,
 			ClientTime:     r.ClientTime,
 			Achievement:    leveldomain.Achievement(r.Achievement),
 			CompletionTime: time.Duration(r.CompletionTimeSeconds),
-		})
-		if err != nil {
-			ctx.JSON(http.StatusInternalServerError, httpError{Message: err.Error()})
-			return
-		}
-
-		res.Responses = append(res.Responses, handleEventCompleteResponse{
-			UUID:       logRes.UUID,
-			ServerTime: logRes.ServerTime,
+			Metadata:       r.Metadata,
+			IdempotencyKey: batchIdempotencyKey(ctx, r.IdempotencyKey, i),
+			Game:           game,
 		})
 	}
 
-	ctx.JSON(http.StatusOK, res)
+	c.logEvents(ctx, requests, req.Atomic)
 }
 
 type handleEventsCompleteRequest struct {
 	Requests []handleEventCompleteRequest `json:"requests"`
-}
-type handleEventsCompleteResponse struct {
-	Responses []handleEventCompleteResponse `json:"responses"`
+	// Atomic rejects the whole batch if one event fails, defaults to true.
+	Atomic *bool `json:"atomic"`
 }
 
 // HandleEventsDeath godoc
@@ -244,11 +303,14 @@ type handleEventsCompleteResponse struct {
 // @Produce  json
 // @Tags     level, death, events
 // @Accept   json
-// @Param    body  body      handleEventsDeathRequest  true  "Log death"
-// @Success  200   {object}  handleEventsDeathResponse
-// @Failure  400   {object}  httpError
-// @Failure  404   {object}  httpError
-// @Failure  500   {object}  httpError
+// @Param    Idempotency-Key  header    string                    false  "Key to deduplicate retries"
+// @Param    body             body      handleEventsDeathRequest  true   "Log death"
+// @Success  200              {object}  handleEventsResponse
+// @Failure  400              {object}  httpError
+// @Failure  404              {object}  httpError
+// @Failure  409              {object}  httpError
+// @Failure  500              {object}  httpError
+// @Failure  503              {object}  httpError
 // @Router   /level/events/death [post]
 func (c controller) HandleEventsDeath(ctx *gin.Context) {
 	var req handleEventsDeathRequest
@@ -257,36 +319,28 @@ func (c controller) HandleEventsDeath(ctx *gin.Context) {
 		return
 	}
 
-	res := handleEventsDeathResponse{
-		Responses: make([]handleEventDeathResponse, 0, len(req.Requests)),
-	}
-
-	zerolog.Ctx(ctx.Request.Context()).Info().Msgf("inserting %d events", len(req.Requests))
+	game := principalGame(ctx)
 
-	for _, r := range req.Requests {
-		logRes, err := c.levelService.LogDeath(ctx.Request.Context(), leveldomain.LogDeathRequest{
-			UUID:       r.UUID,
-			ClientTime: r.ClientTime,
-		})
-		if err != nil {
-			ctx.JSON(http.StatusInternalServerError, httpError{Message: err.Error()})
-			return
-		}
-
-		res.Responses = append(res.Responses, handleEventDeathResponse{
-			UUID:       logRes.UUID,
-			ServerTime: logRes.ServerTime,
+	requests := make([]leveldomain.EventRequest, 0, len(req.Requests))
+	for i, r := range req.Requests {
+		requests = append(requests, leveldomain.LogDeathRequest{
+			UUID:           r.UUID,
+			ClientTime:     r.ClientTime,
+			Metadata:       r.Metadata,
+			IdempotencyKey: batchIdempotencyKey(ctx, r.IdempotencyKey, i),
+			Game:           game,
+			Position:       r.Position.toDomain(),
+			Cause:          r.Cause,
 		})
 	}
 
-	ctx.JSON(http.StatusOK, res)
+	c.logEvents(ctx, requests, req.Atomic)
 }
 
 type handleEventsDeathRequest struct {
 	Requests []handleEventDeathRequest `json:"requests"`
-}
-type handleEventsDeathResponse struct {
-	Responses []handleEventDeathResponse `json:"responses"`
+	// Atomic rejects the whole batch if one event fails, defaults to true.
+	Atomic *bool `json:"atomic"`
 }
 
 // HandleEventsUseGrapplingHook godoc
@@ -294,11 +348,14 @@ type handleEventsDeathResponse struct {
 // @Produce  json
 // @Tags     level, grappling hook, events
 // @Accept   json
-// @Param    body  body      handleEventsUseGrapplingHookRequest  true  "Log grappling hook usage"
-// @Success  200   {object}  handleEventsUseGrapplingHookResponse
-// @Failure  400   {object}  httpError
-// @Failure  404   {object}  httpError
-// @Failure  500   {object}  httpError
+// @Param    Idempotency-Key  header    string                               false  "Key to deduplicate retries"
+// @Param    body             body      handleEventsUseGrapplingHookRequest  true   "Log grappling hook usage"
+// @Success  200              {object}  handleEventsResponse
+// @Failure  400              {object}  httpError
+// @Failure  404              {object}  httpError
+// @Failure  409              {object}  httpError
+// @Failure  500              {object}  httpError
+// @Failure  503              {object}  httpError
 // @Router   /level/events/grappling-hook-usage [post]
 func (c controller) HandleEventsUseGrapplingHook(ctx *gin.Context) {
 	var req handleEventsUseGrapplingHookRequest
@@ -307,34 +364,282 @@ func (c controller) HandleEventsUseGrapplingHook(ctx *gin.Context) {
 		return
 	}
 
-	res := handleEventsUseGrapplingHookResponse{
-		Responses: make([]handleEventUseGrapplingHookResponse, 0, len(req.Requests)),
+	game := principalGame(ctx)
+
+	requests := make([]leveldomain.EventRequest, 0, len(req.Requests))
+	for i, r := range req.Requests {
+		requests = append(requests, leveldomain.LogGrapplingHookUsageRequest{
+			UUID:           r.UUID,
+			ClientTime:     r.ClientTime,
+			Metadata:       r.Metadata,
+			IdempotencyKey: batchIdempotencyKey(ctx, r.IdempotencyKey, i),
+			Game:           game,
+			Position:       r.Position.toDomain(),
+		})
 	}
 
-	zerolog.Ctx(ctx.Request.Context()).Info().Msgf("inserting %d events", len(req.Requests))
+	c.logEvents(ctx, requests, req.Atomic)
+}
 
-	for _, r := range req.Requests {
-		logRes, err := c.levelService.LogGrapplingHookUsage(ctx.Request.Context(), leveldomain.LogGrapplingHookUsageRequest{
-			UUID:       r.UUID,
-			ClientTime: r.ClientTime,
-		})
-		if err != nil {
-			ctx.JSON(http.StatusInternalServerError, httpError{Message: err.Error()})
-			return
+type handleEventsUseGrapplingHookRequest struct {
+	Requests []handleEventUseGrapplingHookRequest `json:"requests"`
+	// Atomic rejects the whole batch if one event fails, defaults to true.
+	Atomic *bool `json:"atomic"`
+}
+
+// logEvents logs a batch of events and writes the result of each one.
+func (c controller) logEvents(ctx *gin.Context, requests []leveldomain.EventRequest, atomic *bool) {
+	zerolog.Ctx(ctx.Request.Context()).Info().Msgf("inserting %d events", len(requests))
+
+	c.metrics.ObserveBatchSize(ctx.FullPath(), len(requests))
+
+	res, err := c.levelService.LogEvents(ctx.Request.Context(), leveldomain.LogEventsRequest{
+		Requests: requests,
+		Atomic:   atomic == nil || *atomic,
+	})
+	if err != nil {
+		ctx.JSON(errorResponse(err))
+		return
+	}
+
+	responses := make([]handleEventsItemResponse, 0, len(res.Results))
+	for _, r := range res.Results {
+		var item handleEventsItemResponse
+
+		if r.Err != nil {
+			_, httpErr := errorResponse(r.Err)
+			item.Error = &httpErr
+		} else {
+			serverTime := r.ServerTime
+			item.UUID = r.UUID
+			item.ServerTime = &serverTime
 		}
 
-		res.Responses = append(res.Responses, handleEventUseGrapplingHookResponse{
-			UUID:       logRes.UUID,
-			ServerTime: logRes.ServerTime,
+		responses = append(responses, item)
+	}
+
+	ctx.JSON(http.StatusOK, handleEventsResponse{
+		Responses: responses,
+	})
+}
+
+type handleEventsItemResponse struct {
+	UUID       string     `json:"uuid,omitempty"`
+	ServerTime *time.Time `json:"server_time,omitempty"`
+	Error      *httpError `json:"error,omitempty"`
+}
+
+type handleEventsResponse struct {
+	Responses []handleEventsItemResponse `json:"responses"`
+}
+
+// HandleEvent godoc
+// @Summary  Logs custom event of level, restart and abandon end the attempt
+// @Produce  json
+// @Tags     level, event
+// @Accept   json
+// @Param    Idempotency-Key  header    string              false  "Key to deduplicate retries"
+// @Param    name             path      string              true   "Event name"
+// @Param    body             body      handleEventRequest  true   "Log event"
+// @Success  200              {object}  handleEventResponse
+// @Failure  400              {object}  httpError
+// @Failure  404              {object}  httpError
+// @Failure  409              {object}  httpError
+// @Failure  500              {object}  httpError
+// @Failure  503              {object}  httpError
+// @Router   /level/event/{name} [post]
+func (c controller) HandleEvent(ctx *gin.Context) {
+	var req handleEventRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, httpError{Message: err.Error()})
+		return
+	}
+
+	res, err := c.levelService.LogEvent(ctx.Request.Context(), leveldomain.LogEventRequest{
+		UUID:           req.UUID,
+		Event:          leveldomain.Event(strings.ReplaceAll(ctx.Param("name"), "-", "_")),
+		ClientTime:     req.ClientTime,
+		Metadata:       req.Metadata,
+		IdempotencyKey: idempotencyKey(ctx, req.IdempotencyKey),
+		Game:           principalGame(ctx),
+		Position:       req.Position.toDomain(),
+	})
+	if err != nil {
+		ctx.JSON(errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, handleEventResponse{
+		UUID:       res.UUID,
+		ServerTime: res.ServerTime,
+	})
+}
+
+type handleEventRequest struct {
+	UUID           string                 `json:"uuid"`
+	ClientTime     time.Time              `json:"client_time"`
+	Metadata       map[string]interface{} `json:"metadata"`
+	IdempotencyKey string                 `json:"idempotency_key"`
+	// Position is where the event happened in world coordinates, only for events with a position.
+	Position *position `json:"position"`
+}
+
+type handleEventResponse struct {
+	UUID       string    `json:"uuid"`
+	ServerTime time.Time `json:"server_time"`
+}
+
+type levelResponse struct {
+	UUID        string                 `json:"uuid"`
+	SessionUUID string                 `json:"session_uuid"`
+	Level       int                    `json:"level"`
+	ClientTime  time.Time              `json:"client_time"`
+	ServerTime  time.Time              `json:"server_time"`
+	Metadata    map[string]interface{} `json:"metadata"`
+	EndedAt     *time.Time             `json:"ended_at,omitempty"`
+	Outcome     string                 `json:"outcome,omitempty"`
+}
+
+func newLevelResponse(l leveldomain.Level) levelResponse {
+	return levelResponse{
+		UUID:        l.UUID,
+		SessionUUID: l.SessionUUID,
+		Level:       l.Level,
+		ClientTime:  l.ClientTime,
+		ServerTime:  l.ServerTime,
+		Metadata:    l.Metadata,
+		EndedAt:     l.EndedAt,
+		Outcome:     string(l.Outcome),
+	}
+}
+
+// GetLevel godoc
+// @Summary  Gets level object
+// @Produce  json
+// @Tags     level
+// @Param    uuid  path      string  true   "Level UUID"
+// @Param    game  query     string  false  "Game, only for admins"
+// @Success  200   {object}  levelResponse
+// @Failure  400   {object}  httpError
+// @Failure  404   {object}  httpError
+// @Failure  500   {object}  httpError
+// @Failure  503   {object}  httpError
+// @Router   /level/{uuid} [get]
+func (c controller) GetLevel(ctx *gin.Context) {
+	game, err := requestGame(ctx, ctx.Query("game"))
+	if err != nil {
+		ctx.JSON(errorResponse(err))
+		return
+	}
+
+	res, err := c.levelService.Get(ctx.Request.Context(), leveldomain.GetRequest{
+		UUID: ctx.Param("uuid"),
+		Game: game,
+	})
+	if err != nil {
+		ctx.JSON(errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newLevelResponse(res.Level))
+}
+
+// ListLevelEvents godoc
+// @Summary  Lists events of level
+// @Produce  json
+// @Tags     level, event
+// @Param    uuid    path      string    true   "Level UUID"
+// @Param    event   query     []string  false  "Event types"  collectionFormat(multi)
+// @Param    from    query     string    false  "Server time from (RFC3339, inclusive)"
+// @Param    to      query     string    false  "Server time to (RFC3339, exclusive)"
+// @Param    cursor  query     string    false  "Cursor of the next page"
+// @Param    limit   query     int       false  "Page size"
+// @Param    game    query     string    false  "Game, only for admins"
+// @Success  200     {object}  listLevelEventsResponse
+// @Failure  400     {object}  httpError
+// @Failure  404     {object}  httpError
+// @Failure  500     {object}  httpError
+// @Failure  503     {object}  httpError
+// @Router   /level/{uuid}/events [get]
+func (c controller) ListLevelEvents(ctx *gin.Context) {
+	var req listLevelEventsRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, httpError{Message: err.Error()})
+		return
+	}
+
+	cursor, err := pagination.Parse(req.Cursor)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, httpError{Message: err.Error()})
+		return
+	}
+
+	game, err := requestGame(ctx, req.Game)
+	if err != nil {
+		ctx.JSON(errorResponse(err))
+		return
+	}
+
+	events := make([]leveldomain.Event, 0, len(req.Events))
+	for _, e := range req.Events {
+		events = append(events, leveldomain.Event(strings.ReplaceAll(e, "-", "_")))
+	}
+
+	res, err := c.levelService.ListEvents(ctx.Request.Context(), leveldomain.ListEventsRequest{
+		LevelUUID: ctx.Param("uuid"),
+		Events:    events,
+		From:      req.From,
+		To:        req.To,
+		Cursor:    cursor,
+		Limit:     req.Limit,
+		Game:      game,
+	})
+	if err != nil {
+		ctx.JSON(errorResponse(err))
+		return
+	}
+
+	resEvents := make([]levelEventResponse, 0, len(res.Events))
+	for _, e := range res.Events {
+		resEvents = append(resEvents, levelEventResponse{
+			UUID:       e.UUID,
+			LevelUUID:  e.LevelUUID,
+			Event:      string(e.Event),
+			ClientTime: e.ClientTime,
+			ServerTime: e.ServerTime,
+			Metadata:   e.Metadata,
+			Position:   newPosition(e.Position),
+			Cause:      e.Cause,
 		})
 	}
 
-	ctx.JSON(http.StatusOK, res)
+	ctx.JSON(http.StatusOK, listLevelEventsResponse{
+		Events:     resEvents,
+		NextCursor: res.NextCursor.String(),
+	})
 }
 
-type handleEventsUseGrapplingHookRequest struct {
-	Requests []handleEventUseGrapplingHookRequest `json:"requests"`
+type listLevelEventsRequest struct {
+	Events []string  `form:"event"`
+	From   time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To     time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+	Cursor string    `form:"cursor"`
+	Limit  int       `form:"limit"`
+	Game   string    `form:"game"`
+}
+
+type levelEventResponse struct {
+	UUID       string                 `json:"uuid"`
+	LevelUUID  string                 `json:"level_uuid"`
+	Event      string                 `json:"event"`
+	ClientTime time.Time              `json:"client_time"`
+	ServerTime time.Time              `json:"server_time"`
+	Metadata   map[string]interface{} `json:"metadata"`
+	Position   *position              `json:"position,omitempty"`
+	Cause      string                 `json:"cause,omitempty"`
 }
-type handleEventsUseGrapplingHookResponse struct {
-	Responses []handleEventUseGrapplingHookResponse `json:"responses"`
+
+type listLevelEventsResponse struct {
+	Events     []levelEventResponse `json:"events"`
+	NextCursor string               `json:"next_cursor"`
 }
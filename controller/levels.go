@@ -0,0 +1,290 @@
+package controller
+
+import (
+	"bytes"
+	"fmt"
+	"image/png"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	leveldomain "github.com/vediagames/onlooker/domain/level"
+)
+
+// defaultCellSize is the size of grid cells in world coordinates if none is requested.
+const defaultCellSize = 10
+
+// GetLevelGrid godoc
+// @Summary      Aggregates positions of events of level into grid
+// @Description  Counts the events with a position per cell of the grid, cells without events are omitted.
+// @Produce      json
+// @Tags         level, event, death
+// @Param        level      path      int     true   "Level number"
+// @Param        event      query     string  false  "Event with a position, defaults to death"
+// @Param        cell_size  query     number  false  "Size of cells in world coordinates, at least 0.001, defaults to 10"
+// @Param        cause      query     string  false  "Cause of deaths"
+// @Param        from       query     string  false  "Server time from (RFC3339, inclusive)"
+// @Param        to         query     string  false  "Server time to (RFC3339, exclusive)"
+// @Param        game       query     string  false  "Game, only for admins"
+// @Success      200        {object}  getLevelGridResponse
+// @Failure      400        {object}  httpError
+// @Failure      404        {object}  httpError
+// @Failure      500        {object}  httpError
+// @Failure      503        {object}  httpError
+// @Router       /levels/{level}/grid [get]
+func (c controller) GetLevelGrid(ctx *gin.Context) {
+	var req getLevelGridRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, httpError{Message: err.Error()})
+		return
+	}
+
+	game, err := requestGame(ctx, req.Game)
+	if err != nil {
+		ctx.JSON(errorResponse(err))
+		return
+	}
+
+	gridReq, err := req.gridRequest(ctx, game)
+	if err != nil {
+		ctx.JSON(errorResponse(err))
+		return
+	}
+
+	res, err := c.levelService.Grid(ctx.Request.Context(), gridReq)
+	if err != nil {
+		ctx.JSON(errorResponse(err))
+		return
+	}
+
+	cells := make([]cellResponse, 0, len(res.Cells))
+	for _, cell := range res.Cells {
+		cells = append(cells, cellResponse{
+			X:     cell.X,
+			Y:     cell.Y,
+			MinX:  float64(cell.X) * gridReq.CellSize,
+			MinY:  float64(cell.Y) * gridReq.CellSize,
+			Count: cell.Count,
+		})
+	}
+
+	ctx.JSON(http.StatusOK, getLevelGridResponse{
+		Level:    gridReq.Level,
+		Event:    string(gridReq.Event),
+		CellSize: gridReq.CellSize,
+		Cells:    cells,
+	})
+}
+
+type getLevelGridRequest struct {
+	Event    string    `form:"event"`
+	CellSize float64   `form:"cell_size"`
+	Cause    string    `form:"cause"`
+	From     time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To       time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+	Game     string    `form:"game"`
+}
+
+type cellResponse struct {
+	// X and Y are the indexes of the cell in the grid.
+	X int `json:"x"`
+	Y int `json:"y"`
+	// MinX and MinY are the world coordinates of the corner of the cell, which
+	// covers the positions up to MinX+cell_size and MinY+cell_size.
+	MinX  float64 `json:"min_x"`
+	MinY  float64 `json:"min_y"`
+	Count int     `json:"count"`
+}
+
+type getLevelGridResponse struct {
+	Level    int            `json:"level"`
+	Event    string         `json:"event" example:"death"`
+	CellSize float64        `json:"cell_size"`
+	Cells    []cellResponse `json:"cells"`
+}
+
+// gridRequest returns the request for the grid of the level in the path, applying
+// the defaults of the request.
+func (r getLevelGridRequest) gridRequest(ctx *gin.Context, game string) (leveldomain.GridRequest, error) {
+	level, err := strconv.Atoi(ctx.Param("level"))
+	if err != nil {
+		return leveldomain.GridRequest{}, fmt.Errorf("level %q: %w", ctx.Param("level"), leveldomain.ErrInvalidArgument)
+	}
+
+	event := leveldomain.EventDeath
+	if r.Event != "" {
+		event = leveldomain.Event(strings.ReplaceAll(r.Event, "-", "_"))
+	}
+
+	cellSize := r.CellSize
+	if cellSize == 0 {
+		cellSize = defaultCellSize
+	}
+
+	return leveldomain.GridRequest{
+		Level:    level,
+		Event:    event,
+		CellSize: cellSize,
+		Cause:    r.Cause,
+		From:     r.From,
+		To:       r.To,
+		Game:     game,
+	}, nil
+}
+
+// GetLevelHeatmap godoc
+// @Summary      Renders heatmap of events of level
+// @Description  Draws the grid of the level as a PNG, over the background of the level if one was uploaded.
+// @Description  Links opened in a browser cannot set headers and have to pass the token in access_token.
+// @Produce      png
+// @Tags         level, event, death
+// @Param        level         path      int     true   "Level number"
+// @Param        event         query     string  false  "Event with a position, defaults to death"
+// @Param        cell_size     query     number  false  "Size of cells in world coordinates, at least 0.001, defaults to 10"
+// @Param        cause         query     string  false  "Cause of deaths"
+// @Param        from          query     string  false  "Server time from (RFC3339, inclusive)"
+// @Param        to            query     string  false  "Server time to (RFC3339, exclusive)"
+// @Param        game          query     string  false  "Game, only for admins, defaults to the default game"
+// @Param        access_token  query     string  false  "Token of a key with a single scope other than admin, instead of the Authorization header"
+// @Success      200           {file}    binary
+// @Failure      400           {object}  httpError
+// @Failure      404           {object}  httpError
+// @Failure      500           {object}  httpError
+// @Failure      503           {object}  httpError
+// @Router       /levels/{level}/heatmap.png [get]
+func (c controller) GetLevelHeatmap(ctx *gin.Context) {
+	var req getLevelGridRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, httpError{Message: err.Error()})
+		return
+	}
+
+	// Backgrounds belong to a game, so a heatmap is always of one game.
+	game, err := sessionGame(ctx, req.Game)
+	if err != nil {
+		ctx.JSON(errorResponse(err))
+		return
+	}
+
+	gridReq, err := req.gridRequest(ctx, game)
+	if err != nil {
+		ctx.JSON(errorResponse(err))
+		return
+	}
+
+	res, err := c.levelService.Heatmap(ctx.Request.Context(), leveldomain.HeatmapRequest{
+		Level:    gridReq.Level,
+		Event:    gridReq.Event,
+		CellSize: gridReq.CellSize,
+		Cause:    gridReq.Cause,
+		From:     gridReq.From,
+		To:       gridReq.To,
+		Game:     gridReq.Game,
+	})
+	if err != nil {
+		ctx.JSON(errorResponse(err))
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, res.Image); err != nil {
+		ctx.JSON(http.StatusInternalServerError, httpError{Message: fmt.Sprintf("failed to encode heatmap: %s", err)})
+		return
+	}
+
+	ctx.Data(http.StatusOK, "image/png", buf.Bytes())
+}
+
+// SetLevelBackground godoc
+// @Summary      Uploads background of level
+// @Description  The body is a PNG, JPEG or GIF image which replaces the background heatmaps of the level are drawn over.
+// @Description  The top left pixel of the image is at origin_x and origin_y in world coordinates, and each pixel is scale
+// @Description  world units wide and high. Images are at most 2048x2048 pixels.
+// @Produce      json
+// @Tags         level
+// @Accept       png
+// @Accept       jpeg
+// @Accept       gif
+// @Param        level     path      int     true   "Level number"
+// @Param        origin_x  query     number  false  "World x of the left edge of the image, defaults to 0"
+// @Param        origin_y  query     number  false  "World y of the top edge of the image, defaults to 0"
+// @Param        scale     query     number  false  "World units per pixel, at least 0.001, defaults to 1"
+// @Param        game      query     string  false  "Game, only for admins, defaults to the default game"
+// @Param        body      body      string  true   "Image"
+// @Success      200       {object}  setLevelBackgroundResponse
+// @Failure      400       {object}  httpError
+// @Failure      404       {object}  httpError
+// @Failure      500       {object}  httpError
+// @Failure      503       {object}  httpError
+// @Router       /levels/{level}/background [put]
+func (c controller) SetLevelBackground(ctx *gin.Context) {
+	var req setLevelBackgroundRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, httpError{Message: err.Error()})
+		return
+	}
+
+	level, err := strconv.Atoi(ctx.Param("level"))
+	if err != nil {
+		ctx.JSON(errorResponse(fmt.Errorf("level %q: %w", ctx.Param("level"), leveldomain.ErrInvalidArgument)))
+		return
+	}
+
+	game, err := sessionGame(ctx, req.Game)
+	if err != nil {
+		ctx.JSON(errorResponse(err))
+		return
+	}
+
+	// One byte more than allowed is read, so that too large images fail validation.
+	image, err := io.ReadAll(io.LimitReader(ctx.Request.Body, leveldomain.MaxBackgroundBytes+1))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, httpError{Message: err.Error()})
+		return
+	}
+
+	scale := 1.0
+	if req.Scale != nil {
+		scale = *req.Scale
+	}
+
+	res, err := c.levelService.SetBackground(ctx.Request.Context(), leveldomain.SetBackgroundRequest{
+		Game:   game,
+		Level:  level,
+		Image:  image,
+		Origin: leveldomain.Position{X: req.OriginX, Y: req.OriginY},
+		Scale:  scale,
+	})
+	if err != nil {
+		ctx.JSON(errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, setLevelBackgroundResponse{
+		Game:      game,
+		Level:     level,
+		Format:    res.Format,
+		Width:     res.Width,
+		Height:    res.Height,
+		UpdatedAt: res.UpdatedAt,
+	})
+}
+
+type setLevelBackgroundRequest struct {
+	OriginX float64  `form:"origin_x"`
+	OriginY float64  `form:"origin_y"`
+	Scale   *float64 `form:"scale"`
+	Game    string   `form:"game"`
+}
+
+type setLevelBackgroundResponse struct {
+	Game      string    `json:"game"`
+	Level     int       `json:"level"`
+	Format    string    `json:"format" example:"png"`
+	Width     int       `json:"width"`
+	Height    int       `json:"height"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
@@ -0,0 +1,121 @@
+package controller
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	playerdomain "github.com/vediagames/onlooker/domain/player"
+	sessiondomain "github.com/vediagames/onlooker/domain/session"
+	"github.com/vediagames/onlooker/pagination"
+)
+
+// GetPlayer godoc
+// @Summary  Gets player object
+// @Produce  json
+// @Tags     player
+// @Param    id    path      string  true   "Player ID"
+// @Param    game  query     string  false  "Game, only for admins, defaults to the default game"
+// @Success  200   {object}  playerResponse
+// @Failure  400   {object}  httpError
+// @Failure  404   {object}  httpError
+// @Failure  500   {object}  httpError
+// @Failure  503   {object}  httpError
+// @Router   /player/{id} [get]
+func (c controller) GetPlayer(ctx *gin.Context) {
+	game, err := sessionGame(ctx, ctx.Query("game"))
+	if err != nil {
+		ctx.JSON(errorResponse(err))
+		return
+	}
+
+	res, err := c.playerService.Get(ctx.Request.Context(), playerdomain.GetRequest{
+		Game: game,
+		ID:   ctx.Param("id"),
+	})
+	if err != nil {
+		ctx.JSON(errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, playerResponse{
+		ID:          res.Player.ID,
+		Game:        res.Player.Game,
+		FirstSeenAt: res.Player.FirstSeenAt,
+		LastSeenAt:  res.Player.LastSeenAt,
+	})
+}
+
+type playerResponse struct {
+	ID          string    `json:"id"`
+	Game        string    `json:"game"`
+	FirstSeenAt time.Time `json:"first_seen_at"`
+	// LastSeenAt is when the last session of the player started.
+	LastSeenAt time.Time `json:"last_seen_at"`
+}
+
+// ListPlayerSessions godoc
+// @Summary  Lists sessions of player
+// @Produce  json
+// @Tags     player, session
+// @Param    id      path      string  true   "Player ID"
+// @Param    cursor  query     string  false  "Cursor of the next page"
+// @Param    limit   query     int     false  "Page size"
+// @Param    game    query     string  false  "Game, only for admins, defaults to the default game"
+// @Success  200     {object}  listPlayerSessionsResponse
+// @Failure  400     {object}  httpError
+// @Failure  404     {object}  httpError
+// @Failure  500     {object}  httpError
+// @Failure  503     {object}  httpError
+// @Router   /player/{id}/sessions [get]
+func (c controller) ListPlayerSessions(ctx *gin.Context) {
+	var req listPlayerSessionsRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, httpError{Message: err.Error()})
+		return
+	}
+
+	cursor, err := pagination.Parse(req.Cursor)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, httpError{Message: err.Error()})
+		return
+	}
+
+	game, err := sessionGame(ctx, req.Game)
+	if err != nil {
+		ctx.JSON(errorResponse(err))
+		return
+	}
+
+	res, err := c.sessionService.List(ctx.Request.Context(), sessiondomain.ListRequest{
+		Game:     game,
+		PlayerID: ctx.Param("id"),
+		Cursor:   cursor,
+		Limit:    req.Limit,
+	})
+	if err != nil {
+		ctx.JSON(errorResponse(err))
+		return
+	}
+
+	sessions := make([]sessionResponse, 0, len(res.Sessions))
+	for _, s := range res.Sessions {
+		sessions = append(sessions, newSessionResponse(s))
+	}
+
+	ctx.JSON(http.StatusOK, listPlayerSessionsResponse{
+		Sessions:   sessions,
+		NextCursor: res.NextCursor.String(),
+	})
+}
+
+type listPlayerSessionsRequest struct {
+	Cursor string `form:"cursor"`
+	Limit  int    `form:"limit"`
+	Game   string `form:"game"`
+}
+
+type listPlayerSessionsResponse struct {
+	Sessions   []sessionResponse `json:"sessions"`
+	NextCursor string            `json:"next_cursor"`
+}
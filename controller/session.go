@@ -5,7 +5,9 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	leveldomain "github.com/vediagames/onlooker/domain/level"
 	sessiondomain "github.com/vediagames/onlooker/domain/session"
+	"github.com/vediagames/onlooker/pagination"
 )
 
 // CreateSession godoc
@@ -13,11 +15,14 @@ import (
 // @Produce  json
 // @Tags     session
 // @Accept   json
-// @Param    body  body      createSessionRequest  true  "Create session"
-// @Success  200   {object}  createSessionResponse
-// @Failure  400   {object}  httpError
-// @Failure  404   {object}  httpError
-// @Failure  500   {object}  httpError
+// @Param    Idempotency-Key  header    string                false  "Key to deduplicate retries"
+// @Param    body             body      createSessionRequest  true   "Create session"
+// @Success  200              {object}  createSessionResponse
+// @Failure  400              {object}  httpError
+// @Failure  404              {object}  httpError
+// @Failure  409              {object}  httpError
+// @Failure  500              {object}  httpError
+// @Failure  503              {object}  httpError
 // @Router   /session [post]
 func (c controller) CreateSession(ctx *gin.Context) {
 	var req createSessionRequest
@@ -31,14 +36,24 @@ func (c controller) CreateSession(ctx *gin.Context) {
 		ip = "Not found"
 	}
 
+	game, err := sessionGame(ctx, req.Game)
+	if err != nil {
+		ctx.JSON(errorResponse(err))
+		return
+	}
+
 	res, err := c.sessionService.Create(ctx.Request.Context(), sessiondomain.CreateRequest{
-		ClientTime: req.ClientTime,
-		IP:         ip,
-		URL:        req.URL,
-		Timezone:   req.Timezone,
+		Game:           game,
+		ClientTime:     req.ClientTime,
+		IP:             ip,
+		URL:            req.URL,
+		Timezone:       req.Timezone,
+		Metadata:       req.Metadata,
+		IdempotencyKey: idempotencyKey(ctx, req.IdempotencyKey),
+		PlayerID:       req.PlayerID,
 	})
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, httpError{Message: err.Error()})
+		ctx.JSON(errorResponse(err))
 		return
 	}
 
@@ -49,13 +64,223 @@ func (c controller) CreateSession(ctx *gin.Context) {
 }
 
 type createSessionRequest struct {
-	ClientTime time.Time `json:"client_time"`
-	IP         string    `json:"ip"`
-	URL        string    `json:"url"`
-	Timezone   string    `json:"timezone"`
+	// Game defaults to the game of the API key, otherwise to the default game.
+	Game           string                 `json:"game"`
+	ClientTime     time.Time              `json:"client_time"`
+	IP             string                 `json:"ip"`
+	URL            string                 `json:"url"`
+	Timezone       string                 `json:"timezone"`
+	Metadata       map[string]interface{} `json:"metadata"`
+	IdempotencyKey string                 `json:"idempotency_key"`
+	// PlayerID is an anonymous ID the client generates once and keeps, for example in localStorage.
+	PlayerID string `json:"player_id"`
 }
 
 type createSessionResponse struct {
 	UUID       string    `json:"uuid"`
 	ServerTime time.Time `json:"server_time"`
 }
+
+// GetSession godoc
+// @Summary  Gets session object
+// @Produce  json
+// @Tags     session
+// @Param    uuid  path      string  true   "Session UUID"
+// @Param    game  query     string  false  "Game, only for admins"
+// @Success  200   {object}  sessionResponse
+// @Failure  400   {object}  httpError
+// @Failure  404   {object}  httpError
+// @Failure  500   {object}  httpError
+// @Failure  503   {object}  httpError
+// @Router   /session/{uuid} [get]
+func (c controller) GetSession(ctx *gin.Context) {
+	game, err := requestGame(ctx, ctx.Query("game"))
+	if err != nil {
+		ctx.JSON(errorResponse(err))
+		return
+	}
+
+	res, err := c.sessionService.Get(ctx.Request.Context(), sessiondomain.GetRequest{
+		UUID: ctx.Param("uuid"),
+		Game: game,
+	})
+	if err != nil {
+		ctx.JSON(errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newSessionResponse(res.Session))
+}
+
+type sessionResponse struct {
+	UUID       string                 `json:"uuid"`
+	Game       string                 `json:"game"`
+	ClientTime time.Time              `json:"client_time"`
+	ServerTime time.Time              `json:"server_time"`
+	IP         string                 `json:"ip"`
+	URL        string                 `json:"url"`
+	Timezone   string                 `json:"timezone"`
+	Metadata   map[string]interface{} `json:"metadata"`
+	PlayerID   string                 `json:"player_id,omitempty"`
+	// UnknownOrigin is set if the url is not an allowed origin of the game.
+	UnknownOrigin bool       `json:"unknown_origin"`
+	LastSeenAt    time.Time  `json:"last_seen_at"`
+	EndedAt       *time.Time `json:"ended_at,omitempty"`
+	// Duration is how long the session lasted in seconds, until it was last seen if it did not end.
+	Duration float64 `json:"duration_seconds"`
+}
+
+func newSessionResponse(s sessiondomain.Session) sessionResponse {
+	return sessionResponse{
+		UUID:          s.UUID,
+		Game:          s.Game,
+		ClientTime:    s.ClientTime,
+		ServerTime:    s.ServerTime,
+		IP:            s.IP,
+		URL:           s.URL,
+		Timezone:      s.Timezone,
+		Metadata:      s.Metadata,
+		PlayerID:      s.PlayerID,
+		UnknownOrigin: s.UnknownOrigin,
+		LastSeenAt:    s.LastSeenAt,
+		EndedAt:       s.EndedAt,
+		Duration:      s.Duration().Seconds(),
+	}
+}
+
+// HeartbeatSession godoc
+// @Summary      Marks session as seen
+// @Description  The body is ignored, so it can be sent with navigator.sendBeacon,
+// @Description  which cannot set headers and has to pass the token in access_token.
+// @Produce      json
+// @Tags         session
+// @Param        uuid          path      string  true   "Session UUID"
+// @Param        access_token  query     string  false  "Token of a key with a single scope other than admin, instead of the Authorization header"
+// @Success      200           {object}  heartbeatSessionResponse
+// @Failure      400           {object}  httpError
+// @Failure      404           {object}  httpError
+// @Failure      409           {object}  httpError
+// @Failure      500           {object}  httpError
+// @Failure      503           {object}  httpError
+// @Router       /session/{uuid}/heartbeat [post]
+func (c controller) HeartbeatSession(ctx *gin.Context) {
+	res, err := c.sessionService.Heartbeat(ctx.Request.Context(), sessiondomain.HeartbeatRequest{
+		UUID: ctx.Param("uuid"),
+		Game: principalGame(ctx),
+	})
+	if err != nil {
+		ctx.JSON(errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, heartbeatSessionResponse{
+		UUID:       ctx.Param("uuid"),
+		LastSeenAt: res.LastSeenAt,
+	})
+}
+
+type heartbeatSessionResponse struct {
+	UUID       string    `json:"uuid"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+}
+
+// EndSession godoc
+// @Summary      Ends session
+// @Description  Ending an ended session keeps its end time. The body is ignored, so it can be sent with
+// @Description  navigator.sendBeacon, which cannot set headers and has to pass the token in access_token.
+// @Produce      json
+// @Tags         session
+// @Param        uuid          path      string  true   "Session UUID"
+// @Param        access_token  query     string  false  "Token of a key with a single scope other than admin, instead of the Authorization header"
+// @Success      200           {object}  endSessionResponse
+// @Failure      400           {object}  httpError
+// @Failure      404           {object}  httpError
+// @Failure      500           {object}  httpError
+// @Failure      503           {object}  httpError
+// @Router       /session/{uuid}/end [post]
+func (c controller) EndSession(ctx *gin.Context) {
+	res, err := c.sessionService.End(ctx.Request.Context(), sessiondomain.EndRequest{
+		UUID: ctx.Param("uuid"),
+		Game: principalGame(ctx),
+	})
+	if err != nil {
+		ctx.JSON(errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, endSessionResponse{
+		UUID:    ctx.Param("uuid"),
+		EndedAt: res.EndedAt,
+	})
+}
+
+type endSessionResponse struct {
+	UUID    string    `json:"uuid"`
+	EndedAt time.Time `json:"ended_at"`
+}
+
+// ListSessionLevels godoc
+// @Summary  Lists levels of session
+// @Produce  json
+// @Tags     session, level
+// @Param    uuid    path      string  true   "Session UUID"
+// @Param    cursor  query     string  false  "Cursor of the next page"
+// @Param    limit   query     int     false  "Page size"
+// @Param    game    query     string  false  "Game, only for admins"
+// @Success  200     {object}  listSessionLevelsResponse
+// @Failure  400     {object}  httpError
+// @Failure  404     {object}  httpError
+// @Failure  500     {object}  httpError
+// @Failure  503     {object}  httpError
+// @Router   /session/{uuid}/levels [get]
+func (c controller) ListSessionLevels(ctx *gin.Context) {
+	var req listSessionLevelsRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, httpError{Message: err.Error()})
+		return
+	}
+
+	cursor, err := pagination.Parse(req.Cursor)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, httpError{Message: err.Error()})
+		return
+	}
+
+	game, err := requestGame(ctx, req.Game)
+	if err != nil {
+		ctx.JSON(errorResponse(err))
+		return
+	}
+
+	res, err := c.levelService.List(ctx.Request.Context(), leveldomain.ListRequest{
+		SessionUUID: ctx.Param("uuid"),
+		Cursor:      cursor,
+		Limit:       req.Limit,
+		Game:        game,
+	})
+	if err != nil {
+		ctx.JSON(errorResponse(err))
+		return
+	}
+
+	levels := make([]levelResponse, 0, len(res.Levels))
+	for _, l := range res.Levels {
+		levels = append(levels, newLevelResponse(l))
+	}
+
+	ctx.JSON(http.StatusOK, listSessionLevelsResponse{
+		Levels:     levels,
+		NextCursor: res.NextCursor.String(),
+	})
+}
+
+type listSessionLevelsRequest struct {
+	Cursor string `form:"cursor"`
+	Limit  int    `form:"limit"`
+	Game   string `form:"game"`
+}
+
+type listSessionLevelsResponse struct {
+	Levels     []levelResponse `json:"levels"`
+	NextCursor string          `json:"next_cursor"`
+}
@@ -0,0 +1,103 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/vediagames/onlooker/errutil"
+)
+
+const pingTimeout = 5 * time.Second
+
+type Config struct {
+	ConnectionString string
+	// MaxOpenConns limits the open connections, 0 means unlimited.
+	MaxOpenConns int
+	// MaxIdleConns limits the idle connections kept in the pool, 0 keeps none.
+	MaxIdleConns int
+	// ConnMaxLifetime closes connections after they were open this long, 0 keeps them.
+	ConnMaxLifetime time.Duration
+	// StatementTimeout aborts statements running longer, 0 disables the timeout.
+	StatementTimeout time.Duration
+}
+
+func (c Config) Validate() error {
+	var err errutil.Error
+
+	if c.ConnectionString == "" {
+		err.Add(fmt.Errorf("connection string is empty"))
+	}
+
+	if c.MaxOpenConns < 0 {
+		err.Add(fmt.Errorf("max open connections must not be negative"))
+	}
+
+	if c.MaxIdleConns < 0 {
+		err.Add(fmt.Errorf("max idle connections must not be negative"))
+	}
+
+	if c.ConnMaxLifetime < 0 {
+		err.Add(fmt.Errorf("connection max lifetime must not be negative"))
+	}
+
+	if c.StatementTimeout < 0 {
+		err.Add(fmt.Errorf("statement timeout must not be negative"))
+	}
+
+	return err.Err()
+}
+
+// Open opens the connection pool shared by the stores and checks that
+// the database is reachable. The caller closes it.
+func Open(cfg Config) (*sqlx.DB, error) {
+	if ve := cfg.Validate(); ve != nil {
+		return nil, fmt.Errorf("invalid config: %w", ve)
+	}
+
+	connString, err := connectionString(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("invalid connection string: %w", err)
+	}
+
+	db, err := sqlx.Open("postgres", connString)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	db.SetMaxOpenConns(cfg.MaxOpenConns)
+	db.SetMaxIdleConns(cfg.MaxIdleConns)
+	db.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+
+	ctx, cancel := context.WithTimeout(context.Background(), pingTimeout)
+	defer cancel()
+
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to ping database: %w", TranslateError(err))
+	}
+
+	return db, nil
+}
+
+// connectionString returns the connection string with the statement timeout
+// as a run-time parameter, which pq sets on every new connection.
+func connectionString(cfg Config) (string, error) {
+	connString := cfg.ConnectionString
+
+	if cfg.StatementTimeout == 0 {
+		return connString, nil
+	}
+
+	if strings.HasPrefix(connString, "postgres://") || strings.HasPrefix(connString, "postgresql://") {
+		var err error
+		if connString, err = pq.ParseURL(connString); err != nil {
+			return "", err
+		}
+	}
+
+	return fmt.Sprintf("%s statement_timeout=%d", connString, cfg.StatementTimeout.Milliseconds()), nil
+}
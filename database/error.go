@@ -0,0 +1,48 @@
+package database
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"net"
+
+	"github.com/lib/pq"
+	"github.com/vediagames/onlooker/errutil"
+)
+
+// TranslateError sets the kind of PostgreSQL errors which are caused by the
+// caller or the connection, so they are not reported as internal errors.
+func TranslateError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		switch {
+		case pqErr.Code == "23503": // foreign_key_violation
+			return errutil.WithKind(err, errutil.KindNotFound)
+		case pqErr.Code == "23505": // unique_violation
+			return errutil.WithKind(err, errutil.KindConflict)
+		case pqErr.Code.Class() == "22", // data_exception
+			pqErr.Code == "23502", // not_null_violation
+			pqErr.Code == "23514": // check_violation
+			return errutil.WithKind(err, errutil.KindInvalidArgument)
+		case pqErr.Code.Class() == "08", // connection_exception
+			pqErr.Code.Class() == "53", // insufficient_resources
+			pqErr.Code.Class() == "57": // operator_intervention
+			return errutil.WithKind(err, errutil.KindUnavailable)
+		}
+
+		return err
+	}
+
+	var netErr net.Error
+	if errors.Is(err, driver.ErrBadConn) ||
+		errors.Is(err, context.DeadlineExceeded) ||
+		errors.As(err, &netErr) {
+		return errutil.WithKind(err, errutil.KindUnavailable)
+	}
+
+	return err
+}
@@ -0,0 +1,301 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/vediagames/onlooker/errutil"
+)
+
+// migrationsTable is the table used by golang-migrate, so databases migrated
+// with the migrate CLI can be migrated further by the binary and the other way round.
+const migrationsTable = "schema_migrations"
+
+// migrationsLockID is the key of the advisory lock which prevents
+// concurrent migrations.
+const migrationsLockID = 7_469_584_122
+
+var migrationFileRegexp = regexp.MustCompile(`^(\d+)_(.+)\.(up|down)\.sql$`)
+
+type Migration struct {
+	Version uint64
+	Name    string
+	Up      string
+	Down    string
+}
+
+type MigrationStatus struct {
+	Migration
+	Applied bool
+}
+
+type Migrator struct {
+	db         *sqlx.DB
+	migrations []Migration
+}
+
+type MigratorConfig struct {
+	DB *sqlx.DB
+	// FS holds migration files named like {version}_{name}.{up|down}.sql in Dir.
+	FS  fs.FS
+	Dir string
+}
+
+func (c MigratorConfig) Validate() error {
+	var err errutil.Error
+
+	if c.DB == nil {
+		err.Add(fmt.Errorf("db is empty"))
+	}
+
+	if c.FS == nil {
+		err.Add(fmt.Errorf("fs is empty"))
+	}
+
+	return err.Err()
+}
+
+func NewMigrator(cfg MigratorConfig) (*Migrator, error) {
+	if ve := cfg.Validate(); ve != nil {
+		return nil, fmt.Errorf("invalid config: %w", ve)
+	}
+
+	migrations, err := readMigrations(cfg.FS, cfg.Dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
+	}
+
+	return &Migrator{
+		db:         cfg.DB,
+		migrations: migrations,
+	}, nil
+}
+
+func readMigrations(fsys fs.FS, dir string) ([]Migration, error) {
+	if dir == "" {
+		dir = "."
+	}
+
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[uint64]*Migration)
+
+	for _, e := range entries {
+		m := migrationFileRegexp.FindStringSubmatch(e.Name())
+		if e.IsDir() || m == nil {
+			continue
+		}
+
+		version, err := strconv.ParseUint(m[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid version of %q: %w", e.Name(), err)
+		}
+
+		b, err := fs.ReadFile(fsys, path.Join(dir, e.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: m[2]}
+			byVersion[version] = migration
+		}
+
+		if migration.Name != m[2] {
+			return nil, fmt.Errorf("version %d has multiple names: %q and %q", version, migration.Name, m[2])
+		}
+
+		if m[3] == "up" {
+			migration.Up = string(b)
+		} else {
+			migration.Down = string(b)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		migrations = append(migrations, *m)
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+// Up applies all pending migrations and returns them.
+func (m Migrator) Up(ctx context.Context) ([]Migration, error) {
+	var applied []Migration
+
+	err := m.withLock(ctx, func(conn *sqlx.Conn) error {
+		current, err := m.version(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for _, migration := range m.migrations {
+			if migration.Version <= current {
+				continue
+			}
+
+			if err := m.apply(ctx, conn, migration.Up, migration.Version); err != nil {
+				return fmt.Errorf("failed to apply %d_%s: %w", migration.Version, migration.Name, err)
+			}
+
+			applied = append(applied, migration)
+		}
+
+		return nil
+	})
+
+	return applied, err
+}
+
+// Down rolls back the last n applied migrations and returns them.
+func (m Migrator) Down(ctx context.Context, n int) ([]Migration, error) {
+	var rolledBack []Migration
+
+	err := m.withLock(ctx, func(conn *sqlx.Conn) error {
+		current, err := m.version(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for i := len(m.migrations) - 1; i >= 0 && len(rolledBack) < n; i-- {
+			migration := m.migrations[i]
+			if migration.Version > current {
+				continue
+			}
+
+			var previous uint64
+			if i > 0 {
+				previous = m.migrations[i-1].Version
+			}
+
+			if err := m.apply(ctx, conn, migration.Down, previous); err != nil {
+				return fmt.Errorf("failed to roll back %d_%s: %w", migration.Version, migration.Name, err)
+			}
+
+			rolledBack = append(rolledBack, migration)
+		}
+
+		return nil
+	})
+
+	return rolledBack, err
+}
+
+// Status returns all migrations and whether they are applied.
+func (m Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	conn, err := m.db.Connx(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get connection: %w", TranslateError(err))
+	}
+	defer conn.Close()
+
+	current, err := m.version(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]MigrationStatus, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		statuses = append(statuses, MigrationStatus{
+			Migration: migration,
+			Applied:   migration.Version <= current,
+		})
+	}
+
+	return statuses, nil
+}
+
+func (m Migrator) withLock(ctx context.Context, fn func(conn *sqlx.Conn) error) error {
+	conn, err := m.db.Connx(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get connection: %w", TranslateError(err))
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_lock($1)`, migrationsLockID); err != nil {
+		return fmt.Errorf("failed to lock migrations: %w", TranslateError(err))
+	}
+
+	defer conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock($1)`, migrationsLockID)
+
+	return fn(conn)
+}
+
+// version creates the migrations table if needed and returns the current version.
+func (m Migrator) version(ctx context.Context, conn *sqlx.Conn) (uint64, error) {
+	_, err := conn.ExecContext(ctx, fmt.Sprintf(`
+		CREATE TABLE IF NOT EXISTS %s (version bigint NOT NULL PRIMARY KEY, dirty boolean NOT NULL)
+	`, migrationsTable))
+	if err != nil {
+		return 0, fmt.Errorf("failed to create migrations table: %w", TranslateError(err))
+	}
+
+	var row struct {
+		Version uint64 `db:"version"`
+		Dirty   bool   `db:"dirty"`
+	}
+
+	err = conn.GetContext(ctx, &row, fmt.Sprintf(`SELECT version, dirty FROM %s LIMIT 1`, migrationsTable))
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to get version: %w", TranslateError(err))
+	}
+
+	if row.Dirty {
+		return 0, fmt.Errorf("database is dirty at version %d, fix it manually and reset the dirty flag", row.Version)
+	}
+
+	return row.Version, nil
+}
+
+// apply runs query and sets the version in one transaction.
+func (m Migrator) apply(ctx context.Context, conn *sqlx.Conn, query string, version uint64) error {
+	tx, err := conn.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", TranslateError(err))
+	}
+	defer tx.Rollback()
+
+	if strings.TrimSpace(query) != "" {
+		if _, err := tx.ExecContext(ctx, query); err != nil {
+			return TranslateError(err)
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf(`DELETE FROM %s`, migrationsTable)); err != nil {
+		return fmt.Errorf("failed to reset version: %w", TranslateError(err))
+	}
+
+	if version > 0 {
+		_, err = tx.ExecContext(ctx, fmt.Sprintf(`INSERT INTO %s (version, dirty) VALUES ($1, false)`, migrationsTable), version)
+		if err != nil {
+			return fmt.Errorf("failed to set version: %w", TranslateError(err))
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", TranslateError(err))
+	}
+
+	return nil
+}
@@ -0,0 +1,11 @@
+package database
+
+import "database/sql"
+
+// NullString returns a NULL string if s is empty.
+func NullString(s string) sql.NullString {
+	return sql.NullString{
+		String: s,
+		Valid:  s != "",
+	}
+}
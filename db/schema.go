@@ -0,0 +1,9 @@
+// Package db embeds the SQL schema migrations.
+package db
+
+import "embed"
+
+// Schema holds the migrations in the schema directory.
+//
+//go:embed schema/*.sql
+var Schema embed.FS
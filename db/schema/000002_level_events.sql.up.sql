@@ -0,0 +1,14 @@
+CREATE TABLE "level_events"
+(
+    "uuid"        uuid UNIQUE PRIMARY KEY default gen_random_uuid(),
+    "level_uuid"  uuid,
+    "event"       text NOT NULL,
+    "client_time" timestamp,
+    "server_time" timestamp,
+    "metadata"    jsonb
+);
+
+CREATE INDEX "level_events_level_uuid_event_idx" ON "level_events" ("level_uuid", "event");
+
+ALTER TABLE "level_events"
+    ADD FOREIGN KEY ("level_uuid") REFERENCES "levels" ("uuid");
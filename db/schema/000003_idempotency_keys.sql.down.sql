@@ -0,0 +1,6 @@
+ALTER TABLE "sessions" DROP COLUMN IF EXISTS "idempotency_key";
+ALTER TABLE "levels" DROP COLUMN IF EXISTS "idempotency_key";
+ALTER TABLE "level_complete_events" DROP COLUMN IF EXISTS "idempotency_key";
+ALTER TABLE "level_death_events" DROP COLUMN IF EXISTS "idempotency_key";
+ALTER TABLE "level_grappling_hook_events" DROP COLUMN IF EXISTS "idempotency_key";
+ALTER TABLE "level_events" DROP COLUMN IF EXISTS "idempotency_key";
@@ -0,0 +1,26 @@
+ALTER TABLE "sessions"
+    ADD COLUMN "idempotency_key" text;
+
+ALTER TABLE "levels"
+    ADD COLUMN "idempotency_key" text;
+
+ALTER TABLE "level_complete_events"
+    ADD COLUMN "idempotency_key" text;
+
+ALTER TABLE "level_death_events"
+    ADD COLUMN "idempotency_key" text;
+
+ALTER TABLE "level_grappling_hook_events"
+    ADD COLUMN "idempotency_key" text;
+
+ALTER TABLE "level_events"
+    ADD COLUMN "idempotency_key" text;
+
+-- Level and event keys are unique per parent, so keys of other sessions and
+-- levels are never returned.
+CREATE UNIQUE INDEX "sessions_idempotency_key_idx" ON "sessions" ("idempotency_key") WHERE "idempotency_key" IS NOT NULL;
+CREATE UNIQUE INDEX "levels_session_uuid_idempotency_key_idx" ON "levels" ("session_uuid", "idempotency_key") WHERE "idempotency_key" IS NOT NULL;
+CREATE UNIQUE INDEX "level_complete_events_level_uuid_idempotency_key_idx" ON "level_complete_events" ("level_uuid", "idempotency_key") WHERE "idempotency_key" IS NOT NULL;
+CREATE UNIQUE INDEX "level_death_events_level_uuid_idempotency_key_idx" ON "level_death_events" ("level_uuid", "idempotency_key") WHERE "idempotency_key" IS NOT NULL;
+CREATE UNIQUE INDEX "level_grappling_hook_events_level_uuid_idempotency_key_idx" ON "level_grappling_hook_events" ("level_uuid", "idempotency_key") WHERE "idempotency_key" IS NOT NULL;
+CREATE UNIQUE INDEX "level_events_level_uuid_event_idempotency_key_idx" ON "level_events" ("level_uuid", "event", "idempotency_key") WHERE "idempotency_key" IS NOT NULL;
@@ -0,0 +1,13 @@
+CREATE TABLE "api_keys"
+(
+    "uuid"       uuid UNIQUE PRIMARY KEY default gen_random_uuid(),
+    "name"       text      NOT NULL,
+    "game"       text      NOT NULL,
+    "scopes"     text[]    NOT NULL,
+    "prefix"     text      NOT NULL,
+    "hash"       text      NOT NULL UNIQUE,
+    "created_at" timestamp NOT NULL,
+    "revoked_at" timestamp
+);
+
+CREATE INDEX "api_keys_game_idx" ON "api_keys" ("game");
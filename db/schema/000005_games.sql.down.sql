@@ -0,0 +1,13 @@
+DROP INDEX IF EXISTS "levels_session_uuid_idx";
+DROP INDEX IF EXISTS "sessions_game_server_time_idx";
+DROP INDEX IF EXISTS "sessions_game_idempotency_key_idx";
+
+CREATE UNIQUE INDEX "sessions_idempotency_key_idx" ON "sessions" ("idempotency_key") WHERE "idempotency_key" IS NOT NULL;
+
+ALTER TABLE "sessions"
+    DROP COLUMN IF EXISTS "game";
+
+ALTER TABLE "api_keys"
+    DROP CONSTRAINT IF EXISTS "api_keys_game_fkey";
+
+DROP TABLE IF EXISTS "games";
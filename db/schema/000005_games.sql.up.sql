@@ -0,0 +1,34 @@
+CREATE TABLE "games"
+(
+    "slug"       text UNIQUE PRIMARY KEY,
+    "name"       text      NOT NULL,
+    "created_at" timestamp NOT NULL
+);
+
+-- Sessions logged before games existed belong to the default game.
+INSERT INTO "games" ("slug", "name", "created_at")
+VALUES ('default', 'Default', now());
+
+INSERT INTO "games" ("slug", "name", "created_at")
+SELECT DISTINCT "game", "game", now()
+FROM "api_keys"
+ON CONFLICT DO NOTHING;
+
+ALTER TABLE "api_keys"
+    ADD FOREIGN KEY ("game") REFERENCES "games" ("slug");
+
+ALTER TABLE "sessions"
+    ADD COLUMN "game" text REFERENCES "games" ("slug");
+
+UPDATE "sessions"
+SET "game" = 'default';
+
+ALTER TABLE "sessions"
+    ALTER COLUMN "game" SET NOT NULL;
+
+-- Idempotency keys of sessions are unique per game.
+DROP INDEX IF EXISTS "sessions_idempotency_key_idx";
+
+CREATE UNIQUE INDEX "sessions_game_idempotency_key_idx" ON "sessions" ("game", "idempotency_key") WHERE "idempotency_key" IS NOT NULL;
+CREATE INDEX "sessions_game_server_time_idx" ON "sessions" ("game", "server_time");
+CREATE INDEX "levels_session_uuid_idx" ON "levels" ("session_uuid");
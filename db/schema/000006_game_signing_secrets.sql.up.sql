@@ -0,0 +1,2 @@
+ALTER TABLE "games"
+    ADD COLUMN "signing_secret" text;
@@ -0,0 +1,5 @@
+ALTER TABLE "sessions"
+    DROP COLUMN IF EXISTS "unknown_origin";
+
+ALTER TABLE "games"
+    DROP COLUMN IF EXISTS "allowed_origins";
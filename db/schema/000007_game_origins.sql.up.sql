@@ -0,0 +1,5 @@
+ALTER TABLE "games"
+    ADD COLUMN "allowed_origins" text[] NOT NULL DEFAULT '{}';
+
+ALTER TABLE "sessions"
+    ADD COLUMN "unknown_origin" boolean NOT NULL DEFAULT false;
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS "sessions_open_last_seen_at_idx";
+
+ALTER TABLE "sessions"
+    DROP COLUMN IF EXISTS "ended_at",
+    DROP COLUMN IF EXISTS "last_seen_at";
@@ -0,0 +1,12 @@
+ALTER TABLE "sessions"
+    ADD COLUMN "last_seen_at" timestamp,
+    ADD COLUMN "ended_at"     timestamp;
+
+-- Sessions logged before heartbeats existed were last seen when they started.
+UPDATE "sessions"
+SET "last_seen_at" = "server_time";
+
+ALTER TABLE "sessions"
+    ALTER COLUMN "last_seen_at" SET NOT NULL;
+
+CREATE INDEX "sessions_open_last_seen_at_idx" ON "sessions" ("last_seen_at") WHERE "ended_at" IS NULL;
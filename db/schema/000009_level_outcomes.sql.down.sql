@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS "levels_level_outcome_idx";
+
+ALTER TABLE "levels"
+    DROP COLUMN IF EXISTS "outcome",
+    DROP COLUMN IF EXISTS "ended_at";
@@ -0,0 +1,14 @@
+ALTER TABLE "levels"
+    ADD COLUMN "ended_at" timestamp,
+    ADD COLUMN "outcome"  text;
+
+-- Levels completed before outcomes existed ended with their first complete event.
+UPDATE "levels" l
+SET "ended_at" = c."server_time",
+    "outcome"  = 'complete'
+FROM (SELECT "level_uuid", MIN("server_time") AS "server_time"
+      FROM "level_complete_events"
+      GROUP BY "level_uuid") c
+WHERE c."level_uuid" = l."uuid";
+
+CREATE INDEX "levels_level_outcome_idx" ON "levels" ("level", "outcome");
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS "sessions_game_player_id_idx";
+
+ALTER TABLE "sessions"
+    DROP COLUMN IF EXISTS "player_id";
+
+DROP TABLE IF EXISTS "players";
@@ -0,0 +1,17 @@
+CREATE TABLE "players"
+(
+    "game"          text      NOT NULL REFERENCES "games" ("slug"),
+    "id"            text      NOT NULL,
+    "first_seen_at" timestamp NOT NULL,
+    "last_seen_at"  timestamp NOT NULL,
+    PRIMARY KEY ("game", "id")
+);
+
+ALTER TABLE "sessions"
+    ADD COLUMN "player_id" text;
+
+-- Players are touched after their session in the same transaction.
+ALTER TABLE "sessions"
+    ADD FOREIGN KEY ("game", "player_id") REFERENCES "players" ("game", "id") DEFERRABLE INITIALLY DEFERRED;
+
+CREATE INDEX "sessions_game_player_id_idx" ON "sessions" ("game", "player_id", "server_time") WHERE "player_id" IS NOT NULL;
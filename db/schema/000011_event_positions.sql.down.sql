@@ -0,0 +1,8 @@
+ALTER TABLE "level_events"
+    DROP COLUMN IF EXISTS "y",
+    DROP COLUMN IF EXISTS "x";
+
+ALTER TABLE "level_death_events"
+    DROP COLUMN IF EXISTS "cause",
+    DROP COLUMN IF EXISTS "y",
+    DROP COLUMN IF EXISTS "x";
@@ -0,0 +1,8 @@
+ALTER TABLE "level_death_events"
+    ADD COLUMN "x"     double precision,
+    ADD COLUMN "y"     double precision,
+    ADD COLUMN "cause" text;
+
+ALTER TABLE "level_events"
+    ADD COLUMN "x" double precision,
+    ADD COLUMN "y" double precision;
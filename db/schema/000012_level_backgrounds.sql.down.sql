@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS "level_backgrounds";
+
+ALTER TABLE "level_grappling_hook_events"
+    DROP COLUMN IF EXISTS "y",
+    DROP COLUMN IF EXISTS "x";
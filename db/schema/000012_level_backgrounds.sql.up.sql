@@ -0,0 +1,16 @@
+ALTER TABLE "level_grappling_hook_events"
+    ADD COLUMN "x" double precision,
+    ADD COLUMN "y" double precision;
+
+CREATE TABLE "level_backgrounds"
+(
+    "game"       text             NOT NULL REFERENCES "games" ("slug"),
+    "level"      int              NOT NULL,
+    "image"      bytea            NOT NULL,
+    "format"     text             NOT NULL,
+    "origin_x"   double precision NOT NULL,
+    "origin_y"   double precision NOT NULL,
+    "scale"      double precision NOT NULL,
+    "updated_at" timestamp        NOT NULL,
+    PRIMARY KEY ("game", "level")
+);
@@ -24,42 +24,37 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/hello": {
+        "/admin/games": {
             "get": {
-                "description": "Hello World",
                 "produces": [
                     "application/json"
                 ],
-                "summary": "Hello World",
+                "tags": [
+                    "admin",
+                    "game"
+                ],
+                "summary": "Lists games",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/controller.helloResponse"
-                        }
-                    },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {
-                            "$ref": "#/definitions/controller.httpError"
+                            "$ref": "#/definitions/controller.listGamesResponse"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "500": {
+                        "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/controller.httpError"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "503": {
+                        "description": "Service Unavailable",
                         "schema": {
                             "$ref": "#/definitions/controller.httpError"
                         }
                     }
                 }
-            }
-        },
-        "/level": {
+            },
             "post": {
                 "consumes": [
                     "application/json"
@@ -68,18 +63,18 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "level",
-                    "create"
+                    "admin",
+                    "game"
                 ],
-                "summary": "Creates level object",
+                "summary": "Creates game",
                 "parameters": [
                     {
-                        "description": "Create level",
+                        "description": "Create game",
                         "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/controller.createLevelRequest"
+                            "$ref": "#/definitions/controller.createGameRequest"
                         }
                     }
                 ],
@@ -87,7 +82,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/controller.createLevelResponse"
+                            "$ref": "#/definitions/controller.gameResponse"
                         }
                     },
                     "400": {
@@ -96,8 +91,8 @@ const docTemplate = `{
                             "$ref": "#/definitions/controller.httpError"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "409": {
+                        "description": "Conflict",
                         "schema": {
                             "$ref": "#/definitions/controller.httpError"
                         }
@@ -107,12 +102,19 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/controller.httpError"
                         }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/controller.httpError"
+                        }
                     }
                 }
             }
         },
-        "/level/event/complete": {
-            "post": {
+        "/admin/games/{slug}/origins": {
+            "put": {
+                "description": "Browsers may only send requests with the API keys of the game from these origins and sessions\nwith another url are rejected or flagged. Any origin is allowed if the list is empty.",
                 "consumes": [
                     "application/json"
                 ],
@@ -120,28 +122,31 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "level",
-                    "event",
-                    "complete"
+                    "admin",
+                    "game"
                 ],
-                "summary": "Logs completion of level",
+                "summary": "Sets allowed origins of game",
                 "parameters": [
                     {
-                        "description": "Log completion",
+                        "type": "string",
+                        "description": "Game slug",
+                        "name": "slug",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Allowed origins",
                         "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/controller.handleEventCompleteRequest"
+                            "$ref": "#/definitions/controller.setGameAllowedOriginsRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/controller.handleEventCompleteResponse"
-                        }
+                    "204": {
+                        "description": ""
                     },
                     "400": {
                         "description": "Bad Request",
@@ -160,42 +165,91 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/controller.httpError"
                         }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/controller.httpError"
+                        }
                     }
                 }
             }
         },
-        "/level/event/death": {
+        "/admin/games/{slug}/signing-secret": {
             "post": {
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Requests from the API keys of the game must be signed with the new secret from now on,\nsee the X-Onlooker-Timestamp and X-Onlooker-Signature headers. The secret is only returned in this response.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "level",
-                    "event",
-                    "death"
+                    "admin",
+                    "game"
                 ],
-                "summary": "Logs death of player in level",
+                "summary": "Rotates signing secret of game",
                 "parameters": [
                     {
-                        "description": "Log death",
-                        "name": "body",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/controller.handleEventDeathRequest"
-                        }
+                        "type": "string",
+                        "description": "Game slug",
+                        "name": "slug",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/controller.handleEventDeathResponse"
+                            "$ref": "#/definitions/controller.rotateGameSigningSecretResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controller.httpError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controller.httpError"
                         }
                     },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controller.httpError"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/controller.httpError"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin",
+                    "game"
+                ],
+                "summary": "Disables request signing of game",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Game slug",
+                        "name": "slug",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": ""
+                    },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
@@ -213,12 +267,63 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/controller.httpError"
                         }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/controller.httpError"
+                        }
                     }
                 }
             }
         },
-        "/level/event/grappling-hook-usage": {
+        "/admin/keys": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin",
+                    "api key"
+                ],
+                "summary": "Lists API keys",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Game",
+                        "name": "game",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controller.listAPIKeysResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controller.httpError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controller.httpError"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/controller.httpError"
+                        }
+                    }
+                }
+            },
             "post": {
+                "description": "The key is only returned in this response, only its hash is stored.",
                 "consumes": [
                     "application/json"
                 ],
@@ -226,19 +331,18 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "level",
-                    "grappling hook",
-                    "event"
+                    "admin",
+                    "api key"
                 ],
-                "summary": "Logs usage of grappling hook",
+                "summary": "Creates API key",
                 "parameters": [
                     {
-                        "description": "Log grappling hook usage",
+                        "description": "Create API key",
                         "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/controller.handleEventUseGrapplingHookRequest"
+                            "$ref": "#/definitions/controller.createAPIKeyRequest"
                         }
                     }
                 ],
@@ -246,7 +350,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/controller.handleEventUseGrapplingHookResponse"
+                            "$ref": "#/definitions/controller.createAPIKeyResponse"
                         }
                     },
                     "400": {
@@ -255,14 +359,14 @@ const docTemplate = `{
                             "$ref": "#/definitions/controller.httpError"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "500": {
+                        "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/controller.httpError"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "503": {
+                        "description": "Service Unavailable",
                         "schema": {
                             "$ref": "#/definitions/controller.httpError"
                         }
@@ -270,36 +374,30 @@ const docTemplate = `{
                 }
             }
         },
-        "/level/events/complete": {
-            "post": {
-                "consumes": [
-                    "application/json"
-                ],
+        "/admin/keys/{uuid}": {
+            "delete": {
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "level",
-                    "events",
-                    "complete"
+                    "admin",
+                    "api key"
                 ],
-                "summary": "Logs completion of level",
+                "summary": "Revokes API key",
                 "parameters": [
                     {
-                        "description": "Log completion",
-                        "name": "body",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/controller.handleEventsCompleteRequest"
-                        }
+                        "type": "string",
+                        "description": "API key UUID",
+                        "name": "uuid",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/controller.handleEventsCompleteResponse"
+                            "$ref": "#/definitions/controller.revokeAPIKeyResponse"
                         }
                     },
                     "400": {
@@ -319,40 +417,57 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/controller.httpError"
                         }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/controller.httpError"
+                        }
                     }
                 }
             }
         },
-        "/level/events/death": {
-            "post": {
-                "consumes": [
-                    "application/json"
-                ],
+        "/analytics/levels": {
+            "get": {
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "level",
-                    "death",
-                    "events"
+                    "analytics",
+                    "level"
                 ],
-                "summary": "Logs death of level",
+                "summary": "Gets attempts, completions and deaths per level",
                 "parameters": [
                     {
-                        "description": "Log death",
-                        "name": "body",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/controller.handleEventsDeathRequest"
-                        }
+                        "type": "string",
+                        "description": "Level server time from (RFC3339, inclusive)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Level server time to (RFC3339, exclusive)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Session URL",
+                        "name": "url",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Game, only for admins",
+                        "name": "game",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/controller.handleEventsDeathResponse"
+                            "$ref": "#/definitions/controller.getLevelFunnelResponse"
                         }
                     },
                     "400": {
@@ -372,40 +487,58 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/controller.httpError"
                         }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/controller.httpError"
+                        }
                     }
                 }
             }
         },
-        "/level/events/grappling-hook-usage": {
-            "post": {
-                "consumes": [
-                    "application/json"
-                ],
+        "/analytics/retention": {
+            "get": {
+                "description": "Players are tied together by the player ID of their sessions, anonymous sessions are not counted.\nDays are in the timezone of the sessions, or UTC if it is unknown or utc is set.\nD1, D7 and D30 are omitted until the day they count has ended in UTC.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "level",
-                    "grappling hook",
-                    "events"
+                    "analytics",
+                    "player"
                 ],
-                "summary": "Logs usage of grappling hook",
+                "summary": "Gets D1, D7 and D30 retention of players by first-seen day",
                 "parameters": [
                     {
-                        "description": "Log grappling hook usage",
-                        "name": "body",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/controller.handleEventsUseGrapplingHookRequest"
-                        }
+                        "type": "string",
+                        "description": "First-seen day from (2006-01-02, inclusive)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "First-seen day to (2006-01-02, exclusive)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Group by UTC day instead of the session timezone",
+                        "name": "utc",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Game, only for admins",
+                        "name": "game",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/controller.handleEventsUseGrapplingHookResponse"
+                            "$ref": "#/definitions/controller.getRetentionResponse"
                         }
                     },
                     "400": {
@@ -425,12 +558,19 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/controller.httpError"
                         }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/controller.httpError"
+                        }
                     }
                 }
             }
         },
-        "/session": {
+        "/events": {
             "post": {
+                "description": "Items are processed in order. Sessions and levels can set a client generated id,\nwhich later items can use instead of the UUID in session_uuid and uuid.",
                 "consumes": [
                     "application/json"
                 ],
@@ -438,17 +578,25 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "session"
+                    "session",
+                    "level",
+                    "events"
                 ],
-                "summary": "Creates session object",
+                "summary": "Logs a batch of sessions, levels and level events",
                 "parameters": [
                     {
-                        "description": "Create session",
+                        "type": "string",
+                        "description": "Key to deduplicate retries",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Log events",
                         "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/controller.createSessionRequest"
+                            "$ref": "#/definitions/controller.handleMixedEventsRequest"
                         }
                     }
                 ],
@@ -456,7 +604,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/controller.createSessionResponse"
+                            "$ref": "#/definitions/controller.handleMixedEventsResponse"
                         }
                     },
                     "400": {
@@ -471,35 +619,2030 @@ const docTemplate = `{
                             "$ref": "#/definitions/controller.httpError"
                         }
                     },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/controller.httpError"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/controller.httpError"
                         }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/controller.httpError"
+                        }
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "controller.createLevelRequest": {
-            "type": "object",
-            "properties": {
-                "client_time": {
-                    "type": "string"
-                },
-                "level": {
-                    "type": "integer"
+        },
+        "/hello": {
+            "get": {
+                "description": "Hello World",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Hello World",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controller.helloResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controller.httpError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controller.httpError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controller.httpError"
+                        }
+                    }
+                }
+            }
+        },
+        "/level": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "level",
+                    "create"
+                ],
+                "summary": "Creates level object",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Key to deduplicate retries",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Create level",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controller.createLevelRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controller.createLevelResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controller.httpError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controller.httpError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/controller.httpError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controller.httpError"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/controller.httpError"
+                        }
+                    }
+                }
+            }
+        },
+        "/level/event/complete": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "level",
+                    "event",
+                    "complete"
+                ],
+                "summary": "Logs completion of level",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Key to deduplicate retries",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Log completion",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controller.handleEventCompleteRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controller.handleEventCompleteResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controller.httpError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controller.httpError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/controller.httpError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controller.httpError"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/controller.httpError"
+                        }
+                    }
+                }
+            }
+        },
+        "/level/event/death": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "level",
+                    "event",
+                    "death"
+                ],
+                "summary": "Logs death of player in level",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Key to deduplicate retries",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Log death",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controller.handleEventDeathRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controller.handleEventDeathResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controller.httpError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controller.httpError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/controller.httpError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controller.httpError"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/controller.httpError"
+                        }
+                    }
+                }
+            }
+        },
+        "/level/event/grappling-hook-usage": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "level",
+                    "grappling hook",
+                    "event"
+                ],
+                "summary": "Logs usage of grappling hook",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Key to deduplicate retries",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Log grappling hook usage",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controller.handleEventUseGrapplingHookRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controller.handleEventUseGrapplingHookResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controller.httpError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controller.httpError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/controller.httpError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controller.httpError"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/controller.httpError"
+                        }
+                    }
+                }
+            }
+        },
+        "/level/event/{name}": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "level",
+                    "event"
+                ],
+                "summary": "Logs custom event of level, restart and abandon end the attempt",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Key to deduplicate retries",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Event name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Log event",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controller.handleEventRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controller.handleEventResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controller.httpError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controller.httpError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/controller.httpError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controller.httpError"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/controller.httpError"
+                        }
+                    }
+                }
+            }
+        },
+        "/level/events/complete": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "level",
+                    "events",
+                    "complete"
+                ],
+                "summary": "Logs completion of level",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Key to deduplicate retries",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Log completion",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controller.handleEventsCompleteRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controller.handleEventsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controller.httpError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controller.httpError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/controller.httpError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controller.httpError"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/controller.httpError"
+                        }
+                    }
+                }
+            }
+        },
+        "/level/events/death": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "level",
+                    "death",
+                    "events"
+                ],
+                "summary": "Logs death of level",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Key to deduplicate retries",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Log death",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controller.handleEventsDeathRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controller.handleEventsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controller.httpError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controller.httpError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/controller.httpError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controller.httpError"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/controller.httpError"
+                        }
+                    }
+                }
+            }
+        },
+        "/level/events/grappling-hook-usage": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "level",
+                    "grappling hook",
+                    "events"
+                ],
+                "summary": "Logs usage of grappling hook",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Key to deduplicate retries",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Log grappling hook usage",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controller.handleEventsUseGrapplingHookRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controller.handleEventsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controller.httpError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controller.httpError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/controller.httpError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controller.httpError"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/controller.httpError"
+                        }
+                    }
+                }
+            }
+        },
+        "/level/{uuid}": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "level"
+                ],
+                "summary": "Gets level object",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Level UUID",
+                        "name": "uuid",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Game, only for admins",
+                        "name": "game",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controller.levelResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controller.httpError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controller.httpError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controller.httpError"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/controller.httpError"
+                        }
+                    }
+                }
+            }
+        },
+        "/level/{uuid}/events": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "level",
+                    "event"
+                ],
+                "summary": "Lists events of level",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Level UUID",
+                        "name": "uuid",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Event types",
+                        "name": "event",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Server time from (RFC3339, inclusive)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Server time to (RFC3339, exclusive)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor of the next page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Game, only for admins",
+                        "name": "game",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controller.listLevelEventsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controller.httpError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controller.httpError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controller.httpError"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/controller.httpError"
+                        }
+                    }
+                }
+            }
+        },
+        "/levels/{level}/background": {
+            "put": {
+                "description": "The body is a PNG, JPEG or GIF image which replaces the background heatmaps of the level are drawn over.\nThe top left pixel of the image is at origin_x and origin_y in world coordinates, and each pixel is scale\nworld units wide and high. Images are at most 2048x2048 pixels.",
+                "consumes": [
+                    "image/png",
+                    "image/jpeg",
+                    "image/gif"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "level"
+                ],
+                "summary": "Uploads background of level",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Level number",
+                        "name": "level",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "number",
+                        "description": "World x of the left edge of the image, defaults to 0",
+                        "name": "origin_x",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "World y of the top edge of the image, defaults to 0",
+                        "name": "origin_y",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "World units per pixel, at least 0.001, defaults to 1",
+                        "name": "scale",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Game, only for admins, defaults to the default game",
+                        "name": "game",
+                        "in": "query"
+                    },
+                    {
+                        "description": "Image",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controller.setLevelBackgroundResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controller.httpError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controller.httpError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controller.httpError"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/controller.httpError"
+                        }
+                    }
+                }
+            }
+        },
+        "/levels/{level}/grid": {
+            "get": {
+                "description": "Counts the events with a position per cell of the grid, cells without events are omitted.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "level",
+                    "event",
+                    "death"
+                ],
+                "summary": "Aggregates positions of events of level into grid",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Level number",
+                        "name": "level",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Event with a position, defaults to death",
+                        "name": "event",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Size of cells in world coordinates, at least 0.001, defaults to 10",
+                        "name": "cell_size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cause of deaths",
+                        "name": "cause",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Server time from (RFC3339, inclusive)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Server time to (RFC3339, exclusive)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Game, only for admins",
+                        "name": "game",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controller.getLevelGridResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controller.httpError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controller.httpError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controller.httpError"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/controller.httpError"
+                        }
+                    }
+                }
+            }
+        },
+        "/levels/{level}/heatmap.png": {
+            "get": {
+                "description": "Draws the grid of the level as a PNG, over the background of the level if one was uploaded.\nLinks opened in a browser cannot set headers and have to pass the token in access_token.",
+                "produces": [
+                    "image/png"
+                ],
+                "tags": [
+                    "level",
+                    "event",
+                    "death"
+                ],
+                "summary": "Renders heatmap of events of level",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Level number",
+                        "name": "level",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Event with a position, defaults to death",
+                        "name": "event",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Size of cells in world coordinates, at least 0.001, defaults to 10",
+                        "name": "cell_size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cause of deaths",
+                        "name": "cause",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Server time from (RFC3339, inclusive)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Server time to (RFC3339, exclusive)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Game, only for admins, defaults to the default game",
+                        "name": "game",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Token of a key with a single scope other than admin, instead of the Authorization header",
+                        "name": "access_token",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controller.httpError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controller.httpError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controller.httpError"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/controller.httpError"
+                        }
+                    }
+                }
+            }
+        },
+        "/player/{id}": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "player"
+                ],
+                "summary": "Gets player object",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Player ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Game, only for admins, defaults to the default game",
+                        "name": "game",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controller.playerResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controller.httpError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controller.httpError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controller.httpError"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/controller.httpError"
+                        }
+                    }
+                }
+            }
+        },
+        "/player/{id}/sessions": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "player",
+                    "session"
+                ],
+                "summary": "Lists sessions of player",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Player ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor of the next page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Game, only for admins, defaults to the default game",
+                        "name": "game",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controller.listPlayerSessionsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controller.httpError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controller.httpError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controller.httpError"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/controller.httpError"
+                        }
+                    }
+                }
+            }
+        },
+        "/session": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "session"
+                ],
+                "summary": "Creates session object",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Key to deduplicate retries",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Create session",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controller.createSessionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controller.createSessionResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controller.httpError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controller.httpError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/controller.httpError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controller.httpError"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/controller.httpError"
+                        }
+                    }
+                }
+            }
+        },
+        "/session/{uuid}": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "session"
+                ],
+                "summary": "Gets session object",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Session UUID",
+                        "name": "uuid",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Game, only for admins",
+                        "name": "game",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controller.sessionResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controller.httpError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controller.httpError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controller.httpError"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/controller.httpError"
+                        }
+                    }
+                }
+            }
+        },
+        "/session/{uuid}/end": {
+            "post": {
+                "description": "Ending an ended session keeps its end time. The body is ignored, so it can be sent with\nnavigator.sendBeacon, which cannot set headers and has to pass the token in access_token.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "session"
+                ],
+                "summary": "Ends session",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Session UUID",
+                        "name": "uuid",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Token of a key with a single scope other than admin, instead of the Authorization header",
+                        "name": "access_token",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controller.endSessionResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controller.httpError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controller.httpError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controller.httpError"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/controller.httpError"
+                        }
+                    }
+                }
+            }
+        },
+        "/session/{uuid}/heartbeat": {
+            "post": {
+                "description": "The body is ignored, so it can be sent with navigator.sendBeacon,\nwhich cannot set headers and has to pass the token in access_token.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "session"
+                ],
+                "summary": "Marks session as seen",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Session UUID",
+                        "name": "uuid",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Token of a key with a single scope other than admin, instead of the Authorization header",
+                        "name": "access_token",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controller.heartbeatSessionResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controller.httpError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controller.httpError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/controller.httpError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controller.httpError"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/controller.httpError"
+                        }
+                    }
+                }
+            }
+        },
+        "/session/{uuid}/levels": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "session",
+                    "level"
+                ],
+                "summary": "Lists levels of session",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Session UUID",
+                        "name": "uuid",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor of the next page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Game, only for admins",
+                        "name": "game",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controller.listSessionLevelsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controller.httpError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controller.httpError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controller.httpError"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/controller.httpError"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "controller.apiKeyResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "game": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "prefix": {
+                    "type": "string"
+                },
+                "revoked_at": {
+                    "type": "string"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "uuid": {
+                    "type": "string"
+                }
+            }
+        },
+        "controller.cellResponse": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "min_x": {
+                    "description": "MinX and MinY are the world coordinates of the corner of the cell, which\ncovers the positions up to MinX+cell_size and MinY+cell_size.",
+                    "type": "number"
+                },
+                "min_y": {
+                    "type": "number"
+                },
+                "x": {
+                    "description": "X and Y are the indexes of the cell in the grid.",
+                    "type": "integer"
+                },
+                "y": {
+                    "type": "integer"
+                }
+            }
+        },
+        "controller.createAPIKeyRequest": {
+            "type": "object",
+            "properties": {
+                "game": {
+                    "type": "string",
+                    "example": "grappling-hook"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "web build"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "ingest"
+                    ]
+                }
+            }
+        },
+        "controller.createAPIKeyResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "key": {
+                    "type": "string"
+                },
+                "prefix": {
+                    "type": "string"
+                },
+                "uuid": {
+                    "type": "string"
+                }
+            }
+        },
+        "controller.createGameRequest": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "example": "Grappling Hook"
+                },
+                "slug": {
+                    "type": "string",
+                    "example": "grappling-hook"
+                }
+            }
+        },
+        "controller.createLevelRequest": {
+            "type": "object",
+            "properties": {
+                "client_time": {
+                    "type": "string"
+                },
+                "idempotency_key": {
+                    "type": "string"
+                },
+                "level": {
+                    "type": "integer"
+                },
+                "metadata": {
+                    "type": "object",
+                    "additionalProperties": true
+                },
+                "session_uuid": {
+                    "type": "string"
+                }
+            }
+        },
+        "controller.createLevelResponse": {
+            "type": "object",
+            "properties": {
+                "server_time": {
+                    "type": "string"
+                },
+                "uuid": {
+                    "type": "string"
+                }
+            }
+        },
+        "controller.createSessionRequest": {
+            "type": "object",
+            "properties": {
+                "client_time": {
+                    "type": "string"
+                },
+                "game": {
+                    "description": "Game defaults to the game of the API key, otherwise to the default game.",
+                    "type": "string"
+                },
+                "idempotency_key": {
+                    "type": "string"
+                },
+                "ip": {
+                    "type": "string"
+                },
+                "metadata": {
+                    "type": "object",
+                    "additionalProperties": true
+                },
+                "player_id": {
+                    "description": "PlayerID is an anonymous ID the client generates once and keeps, for example in localStorage.",
+                    "type": "string"
+                },
+                "timezone": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "controller.createSessionResponse": {
+            "type": "object",
+            "properties": {
+                "server_time": {
+                    "type": "string"
                 },
-                "session_uuid": {
+                "uuid": {
+                    "type": "string"
+                }
+            }
+        },
+        "controller.endSessionResponse": {
+            "type": "object",
+            "properties": {
+                "ended_at": {
+                    "type": "string"
+                },
+                "uuid": {
+                    "type": "string"
+                }
+            }
+        },
+        "controller.gameResponse": {
+            "type": "object",
+            "properties": {
+                "allowed_origins": {
+                    "description": "AllowedOrigins are the origins the game is served from, any origin if empty.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "signed": {
+                    "description": "Signed is set if requests from the API keys of the game must be signed.",
+                    "type": "boolean"
+                },
+                "slug": {
+                    "type": "string"
+                }
+            }
+        },
+        "controller.getLevelFunnelResponse": {
+            "type": "object",
+            "properties": {
+                "levels": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/controller.levelFunnelStepResponse"
+                    }
+                }
+            }
+        },
+        "controller.getLevelGridResponse": {
+            "type": "object",
+            "properties": {
+                "cell_size": {
+                    "type": "number"
+                },
+                "cells": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/controller.cellResponse"
+                    }
+                },
+                "event": {
+                    "type": "string",
+                    "example": "death"
+                },
+                "level": {
+                    "type": "integer"
+                }
+            }
+        },
+        "controller.getRetentionResponse": {
+            "type": "object",
+            "properties": {
+                "cohorts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/controller.retentionCohortResponse"
+                    }
+                }
+            }
+        },
+        "controller.handleEventCompleteRequest": {
+            "type": "object",
+            "properties": {
+                "achievement": {
+                    "type": "string"
+                },
+                "client_time": {
+                    "type": "string"
+                },
+                "completion_time_seconds": {
+                    "type": "integer"
+                },
+                "idempotency_key": {
+                    "type": "string"
+                },
+                "metadata": {
+                    "type": "object",
+                    "additionalProperties": true
+                },
+                "uuid": {
+                    "type": "string"
+                }
+            }
+        },
+        "controller.handleEventCompleteResponse": {
+            "type": "object",
+            "properties": {
+                "server_time": {
+                    "type": "string"
+                },
+                "uuid": {
+                    "type": "string"
+                }
+            }
+        },
+        "controller.handleEventDeathRequest": {
+            "type": "object",
+            "properties": {
+                "cause": {
+                    "description": "Cause is what killed the player, optional.",
+                    "type": "string",
+                    "example": "spikes"
+                },
+                "client_time": {
+                    "type": "string"
+                },
+                "idempotency_key": {
+                    "type": "string"
+                },
+                "metadata": {
+                    "type": "object",
+                    "additionalProperties": true
+                },
+                "position": {
+                    "description": "Position is where the player died in world coordinates, optional.",
+                    "$ref": "#/definitions/controller.position"
+                },
+                "uuid": {
+                    "type": "string"
+                }
+            }
+        },
+        "controller.handleEventDeathResponse": {
+            "type": "object",
+            "properties": {
+                "server_time": {
+                    "type": "string"
+                },
+                "uuid": {
+                    "type": "string"
+                }
+            }
+        },
+        "controller.handleEventRequest": {
+            "type": "object",
+            "properties": {
+                "client_time": {
+                    "type": "string"
+                },
+                "idempotency_key": {
+                    "type": "string"
+                },
+                "metadata": {
+                    "type": "object",
+                    "additionalProperties": true
+                },
+                "position": {
+                    "description": "Position is where the event happened in world coordinates, only for events with a position.",
+                    "$ref": "#/definitions/controller.position"
+                },
+                "uuid": {
+                    "type": "string"
+                }
+            }
+        },
+        "controller.handleEventResponse": {
+            "type": "object",
+            "properties": {
+                "server_time": {
+                    "type": "string"
+                },
+                "uuid": {
+                    "type": "string"
+                }
+            }
+        },
+        "controller.handleEventUseGrapplingHookRequest": {
+            "type": "object",
+            "properties": {
+                "client_time": {
+                    "type": "string"
+                },
+                "idempotency_key": {
+                    "type": "string"
+                },
+                "metadata": {
+                    "type": "object",
+                    "additionalProperties": true
+                },
+                "position": {
+                    "description": "Position is where the grappling hook was used in world coordinates, optional.",
+                    "$ref": "#/definitions/controller.position"
+                },
+                "uuid": {
+                    "type": "string"
+                }
+            }
+        },
+        "controller.handleEventUseGrapplingHookResponse": {
+            "type": "object",
+            "properties": {
+                "server_time": {
+                    "type": "string"
+                },
+                "uuid": {
+                    "type": "string"
+                }
+            }
+        },
+        "controller.handleEventsCompleteRequest": {
+            "type": "object",
+            "properties": {
+                "atomic": {
+                    "description": "Atomic rejects the whole batch if one event fails, defaults to true.",
+                    "type": "boolean"
+                },
+                "requests": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/controller.handleEventCompleteRequest"
+                    }
+                }
+            }
+        },
+        "controller.handleEventsDeathRequest": {
+            "type": "object",
+            "properties": {
+                "atomic": {
+                    "description": "Atomic rejects the whole batch if one event fails, defaults to true.",
+                    "type": "boolean"
+                },
+                "requests": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/controller.handleEventDeathRequest"
+                    }
+                }
+            }
+        },
+        "controller.handleEventsItemResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "$ref": "#/definitions/controller.httpError"
+                },
+                "server_time": {
+                    "type": "string"
+                },
+                "uuid": {
+                    "type": "string"
+                }
+            }
+        },
+        "controller.handleEventsResponse": {
+            "type": "object",
+            "properties": {
+                "responses": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/controller.handleEventsItemResponse"
+                    }
+                }
+            }
+        },
+        "controller.handleEventsUseGrapplingHookRequest": {
+            "type": "object",
+            "properties": {
+                "atomic": {
+                    "description": "Atomic rejects the whole batch if one event fails, defaults to true.",
+                    "type": "boolean"
+                },
+                "requests": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/controller.handleEventUseGrapplingHookRequest"
+                    }
+                }
+            }
+        },
+        "controller.handleMixedEventsItemResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "$ref": "#/definitions/controller.httpError"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "server_time": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                },
+                "uuid": {
                     "type": "string"
                 }
             }
         },
-        "controller.createLevelResponse": {
+        "controller.handleMixedEventsRequest": {
+            "type": "object",
+            "properties": {
+                "events": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/controller.mixedEvent"
+                    }
+                }
+            }
+        },
+        "controller.handleMixedEventsResponse": {
+            "type": "object",
+            "properties": {
+                "responses": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/controller.handleMixedEventsItemResponse"
+                    }
+                }
+            }
+        },
+        "controller.heartbeatSessionResponse": {
             "type": "object",
             "properties": {
-                "server_time": {
+                "last_seen_at": {
                     "type": "string"
                 },
                 "uuid": {
@@ -507,187 +2650,421 @@ const docTemplate = `{
                 }
             }
         },
-        "controller.createSessionRequest": {
+        "controller.helloResponse": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string",
+                    "example": "Hello world!"
+                }
+            }
+        },
+        "controller.httpError": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "invalid_argument"
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "uuid must be set"
+                    ]
+                },
+                "message": {
+                    "type": "string",
+                    "example": "status bad request"
+                }
+            }
+        },
+        "controller.levelEventResponse": {
             "type": "object",
             "properties": {
+                "cause": {
+                    "type": "string"
+                },
                 "client_time": {
                     "type": "string"
                 },
-                "ip": {
+                "event": {
                     "type": "string"
                 },
-                "timezone": {
+                "level_uuid": {
                     "type": "string"
                 },
-                "url": {
+                "metadata": {
+                    "type": "object",
+                    "additionalProperties": true
+                },
+                "position": {
+                    "$ref": "#/definitions/controller.position"
+                },
+                "server_time": {
+                    "type": "string"
+                },
+                "uuid": {
                     "type": "string"
                 }
             }
         },
-        "controller.createSessionResponse": {
+        "controller.levelFunnelStepResponse": {
             "type": "object",
             "properties": {
-                "server_time": {
-                    "type": "string"
+                "abandoned": {
+                    "type": "integer"
                 },
-                "uuid": {
-                    "type": "string"
+                "completed": {
+                    "type": "integer"
+                },
+                "completion_rate": {
+                    "type": "number"
+                },
+                "died": {
+                    "type": "integer"
+                },
+                "drop_off": {
+                    "description": "DropOff is omitted if the next level is not in the result.",
+                    "type": "number"
+                },
+                "level": {
+                    "type": "integer"
+                },
+                "quit_rate": {
+                    "type": "number"
+                },
+                "restarted": {
+                    "type": "integer"
+                },
+                "sessions": {
+                    "type": "integer"
+                },
+                "started": {
+                    "type": "integer"
                 }
             }
         },
-        "controller.handleEventCompleteRequest": {
+        "controller.levelResponse": {
             "type": "object",
             "properties": {
-                "achievement": {
+                "client_time": {
                     "type": "string"
                 },
-                "client_time": {
+                "ended_at": {
                     "type": "string"
                 },
-                "completion_time_seconds": {
+                "level": {
                     "type": "integer"
                 },
+                "metadata": {
+                    "type": "object",
+                    "additionalProperties": true
+                },
+                "outcome": {
+                    "type": "string"
+                },
+                "server_time": {
+                    "type": "string"
+                },
+                "session_uuid": {
+                    "type": "string"
+                },
                 "uuid": {
                     "type": "string"
                 }
             }
         },
-        "controller.handleEventCompleteResponse": {
+        "controller.listAPIKeysResponse": {
             "type": "object",
             "properties": {
-                "server_time": {
-                    "type": "string"
+                "keys": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/controller.apiKeyResponse"
+                    }
+                }
+            }
+        },
+        "controller.listGamesResponse": {
+            "type": "object",
+            "properties": {
+                "games": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/controller.gameResponse"
+                    }
+                }
+            }
+        },
+        "controller.listLevelEventsResponse": {
+            "type": "object",
+            "properties": {
+                "events": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/controller.levelEventResponse"
+                    }
                 },
-                "uuid": {
+                "next_cursor": {
                     "type": "string"
                 }
             }
         },
-        "controller.handleEventDeathRequest": {
+        "controller.listPlayerSessionsResponse": {
             "type": "object",
             "properties": {
-                "client_time": {
+                "next_cursor": {
                     "type": "string"
                 },
-                "uuid": {
-                    "type": "string"
+                "sessions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/controller.sessionResponse"
+                    }
                 }
             }
         },
-        "controller.handleEventDeathResponse": {
+        "controller.listSessionLevelsResponse": {
             "type": "object",
             "properties": {
-                "server_time": {
-                    "type": "string"
+                "levels": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/controller.levelResponse"
+                    }
                 },
-                "uuid": {
+                "next_cursor": {
                     "type": "string"
                 }
             }
         },
-        "controller.handleEventUseGrapplingHookRequest": {
+        "controller.mixedEvent": {
             "type": "object",
             "properties": {
+                "achievement": {
+                    "type": "string"
+                },
+                "cause": {
+                    "description": "Cause is what killed the player, only for deaths.",
+                    "type": "string"
+                },
                 "client_time": {
                     "type": "string"
                 },
+                "completion_time_seconds": {
+                    "type": "integer"
+                },
+                "game": {
+                    "description": "Game defaults to the game of the API key, otherwise to the default game.",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "ID is a client generated id of a session or level.",
+                    "type": "string"
+                },
+                "idempotency_key": {
+                    "description": "IdempotencyKey deduplicates retries, defaults to the Idempotency-Key\nheader suffixed with the index of the item.",
+                    "type": "string"
+                },
+                "level": {
+                    "type": "integer"
+                },
+                "metadata": {
+                    "type": "object",
+                    "additionalProperties": true
+                },
+                "position": {
+                    "description": "Position is where the event happened in world coordinates, only for events with a position.",
+                    "$ref": "#/definitions/controller.position"
+                },
+                "session_uuid": {
+                    "description": "Level fields.",
+                    "type": "string"
+                },
+                "timezone": {
+                    "type": "string"
+                },
+                "type": {
+                    "description": "Type is session, level or the name of a level event.",
+                    "type": "string",
+                    "example": "death"
+                },
+                "url": {
+                    "description": "Session fields.",
+                    "type": "string"
+                },
                 "uuid": {
+                    "description": "Level event fields.",
                     "type": "string"
                 }
             }
         },
-        "controller.handleEventUseGrapplingHookResponse": {
+        "controller.playerResponse": {
             "type": "object",
             "properties": {
-                "server_time": {
+                "first_seen_at": {
                     "type": "string"
                 },
-                "uuid": {
+                "game": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "last_seen_at": {
+                    "description": "LastSeenAt is when the last session of the player started.",
                     "type": "string"
                 }
             }
         },
-        "controller.handleEventsCompleteRequest": {
+        "controller.position": {
             "type": "object",
             "properties": {
-                "requests": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/controller.handleEventCompleteRequest"
-                    }
+                "x": {
+                    "type": "number"
+                },
+                "y": {
+                    "type": "number"
                 }
             }
         },
-        "controller.handleEventsCompleteResponse": {
+        "controller.retentionCohortResponse": {
             "type": "object",
             "properties": {
-                "responses": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/controller.handleEventCompleteResponse"
-                    }
+                "d1": {
+                    "type": "integer"
+                },
+                "d1_rate": {
+                    "type": "number"
+                },
+                "d30": {
+                    "type": "integer"
+                },
+                "d30_rate": {
+                    "type": "number"
+                },
+                "d7": {
+                    "type": "integer"
+                },
+                "d7_rate": {
+                    "type": "number"
+                },
+                "day": {
+                    "type": "string",
+                    "example": "2024-01-31"
+                },
+                "players": {
+                    "type": "integer"
                 }
             }
         },
-        "controller.handleEventsDeathRequest": {
+        "controller.revokeAPIKeyResponse": {
             "type": "object",
             "properties": {
-                "requests": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/controller.handleEventDeathRequest"
-                    }
+                "revoked_at": {
+                    "type": "string"
+                },
+                "uuid": {
+                    "type": "string"
                 }
             }
         },
-        "controller.handleEventsDeathResponse": {
+        "controller.rotateGameSigningSecretResponse": {
             "type": "object",
             "properties": {
-                "responses": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/controller.handleEventDeathResponse"
-                    }
+                "signing_secret": {
+                    "type": "string"
+                },
+                "slug": {
+                    "type": "string"
                 }
             }
         },
-        "controller.handleEventsUseGrapplingHookRequest": {
+        "controller.sessionResponse": {
             "type": "object",
             "properties": {
-                "requests": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/controller.handleEventUseGrapplingHookRequest"
-                    }
+                "client_time": {
+                    "type": "string"
+                },
+                "duration_seconds": {
+                    "description": "Duration is how long the session lasted in seconds, until it was last seen if it did not end.",
+                    "type": "number"
+                },
+                "ended_at": {
+                    "type": "string"
+                },
+                "game": {
+                    "type": "string"
+                },
+                "ip": {
+                    "type": "string"
+                },
+                "last_seen_at": {
+                    "type": "string"
+                },
+                "metadata": {
+                    "type": "object",
+                    "additionalProperties": true
+                },
+                "player_id": {
+                    "type": "string"
+                },
+                "server_time": {
+                    "type": "string"
+                },
+                "timezone": {
+                    "type": "string"
+                },
+                "unknown_origin": {
+                    "description": "UnknownOrigin is set if the url is not an allowed origin of the game.",
+                    "type": "boolean"
+                },
+                "url": {
+                    "type": "string"
+                },
+                "uuid": {
+                    "type": "string"
                 }
             }
         },
-        "controller.handleEventsUseGrapplingHookResponse": {
+        "controller.setGameAllowedOriginsRequest": {
             "type": "object",
             "properties": {
-                "responses": {
+                "allowed_origins": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/controller.handleEventUseGrapplingHookResponse"
-                    }
-                }
-            }
-        },
-        "controller.helloResponse": {
-            "type": "object",
-            "properties": {
-                "message": {
-                    "type": "string",
-                    "example": "Hello world!"
+                        "type": "string"
+                    },
+                    "example": [
+                        "https://vediagames.com"
+                    ]
                 }
             }
         },
-        "controller.httpError": {
+        "controller.setLevelBackgroundResponse": {
             "type": "object",
             "properties": {
-                "message": {
+                "format": {
                     "type": "string",
-                    "example": "status bad request"
+                    "example": "png"
+                },
+                "game": {
+                    "type": "string"
+                },
+                "height": {
+                    "type": "integer"
+                },
+                "level": {
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "width": {
+                    "type": "integer"
                 }
             }
         }
@@ -0,0 +1,79 @@
+package analytics
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/vediagames/onlooker/errutil"
+)
+
+type Service interface {
+	LevelFunnel(context.Context, LevelFunnelRequest) (LevelFunnelResponse, error)
+	// Retention returns the D1, D7 and D30 retention of players by the day they were first seen.
+	Retention(context.Context, RetentionRequest) (RetentionResponse, error)
+}
+
+type LevelFunnelRequest struct {
+	From time.Time
+	To   time.Time
+	URL  string
+	// Game restricts the sessions to a game, any game if empty.
+	Game string
+}
+
+func (r LevelFunnelRequest) Validate() error {
+	var err errutil.Error
+
+	if !r.From.IsZero() && !r.To.IsZero() && r.To.Before(r.From) {
+		err.Add(fmt.Errorf("to must be after from"))
+	}
+
+	return err.Err()
+}
+
+type LevelFunnelResponse struct {
+	Levels []LevelFunnelStep
+}
+
+type LevelFunnelStep struct {
+	LevelAttempts
+	// CompletionRate is the share of started attempts which were completed.
+	CompletionRate float64
+	// QuitRate is the share of started attempts which were abandoned.
+	QuitRate float64
+	// DropOff is the share of sessions which played this level but did
+	// not play the next one, nil if the next level is not in the result.
+	DropOff *float64
+}
+
+type RetentionRequest struct {
+	From time.Time
+	To   time.Time
+	UTC  bool
+	// Game restricts the sessions to a game, any game if empty.
+	Game string
+}
+
+func (r RetentionRequest) Validate() error {
+	var err errutil.Error
+
+	if !r.From.IsZero() && !r.To.IsZero() && r.To.Before(r.From) {
+		err.Add(fmt.Errorf("to must be after from"))
+	}
+
+	return err.Err()
+}
+
+type RetentionResponse struct {
+	Cohorts []RetentionCohort
+}
+
+type RetentionCohort struct {
+	Cohort
+	// Day1Rate, Day7Rate and Day30Rate are the shares of the players of the
+	// cohort who played again, nil until the day they count has ended in UTC.
+	Day1Rate  *float64
+	Day7Rate  *float64
+	Day30Rate *float64
+}
@@ -0,0 +1,60 @@
+package analytics
+
+import (
+	"context"
+	"time"
+)
+
+type Store interface {
+	LevelFunnel(context.Context, LevelFunnelQuery) (LevelFunnelResult, error)
+	Retention(context.Context, RetentionQuery) (RetentionResult, error)
+	// Close releases the resources of the store.
+	Close() error
+}
+
+type LevelFunnelQuery struct {
+	From time.Time
+	To   time.Time
+	URL  string
+	// Game restricts the sessions to a game, any game if empty.
+	Game string
+}
+
+type LevelFunnelResult struct {
+	Levels []LevelAttempts
+}
+
+type LevelAttempts struct {
+	Level     int
+	Sessions  int
+	Started   int
+	Completed int
+	Died      int
+	Restarted int
+	// Abandoned counts the attempts which were quit, or left open when their session ended.
+	Abandoned int
+}
+
+type RetentionQuery struct {
+	// From and To restrict the first-seen days of the cohorts, To is exclusive.
+	From time.Time
+	To   time.Time
+	// UTC groups sessions by their UTC day instead of the day in their timezone.
+	UTC bool
+	// Game restricts the sessions to a game, any game if empty.
+	Game string
+}
+
+type RetentionResult struct {
+	Cohorts []Cohort
+}
+
+// Cohort holds the players first seen on a day and how many of them
+// played again exactly 1, 7 and 30 days later.
+type Cohort struct {
+	Day     time.Time
+	Players int
+	Day1    int
+	Day7    int
+	Day30   int
+}
@@ -0,0 +1,67 @@
+package apikey
+
+import (
+	"context"
+	"fmt"
+)
+
+type Scope string
+
+const (
+	// ScopeIngest allows to log sessions, levels and events.
+	ScopeIngest Scope = "ingest"
+	// ScopeRead allows to read sessions, levels, events and analytics.
+	ScopeRead Scope = "read"
+	// ScopeAdmin allows everything, including managing API keys.
+	// It cannot be granted to API keys.
+	ScopeAdmin Scope = "admin"
+)
+
+func (s Scope) Validate() error {
+	switch s {
+	case ScopeIngest, ScopeRead:
+		return nil
+	default:
+		return fmt.Errorf("invalid scope: %q", s)
+	}
+}
+
+// Principal is the authenticated caller of a request.
+type Principal struct {
+	// KeyUUID is the UUID of the API key, empty for the admin token.
+	KeyUUID string
+	Name    string
+	// Game is the game the caller belongs to, empty for admins.
+	Game   string
+	Scopes []Scope
+}
+
+// AdminPrincipal is the principal of requests authenticated with the admin token.
+var AdminPrincipal = Principal{
+	Name:   "admin",
+	Scopes: []Scope{ScopeAdmin},
+}
+
+// HasScope reports whether the principal was granted scope.
+func (p Principal) HasScope(scope Scope) bool {
+	for _, s := range p.Scopes {
+		if s == scope || s == ScopeAdmin {
+			return true
+		}
+	}
+
+	return false
+}
+
+type principalKey struct{}
+
+// ContextWithPrincipal returns a copy of ctx which holds p.
+func ContextWithPrincipal(ctx context.Context, p Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// PrincipalFromContext returns the principal in ctx, if any.
+func PrincipalFromContext(ctx context.Context) (Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(Principal)
+	return p, ok
+}
@@ -0,0 +1,140 @@
+package apikey
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/vediagames/onlooker/errutil"
+)
+
+const MaxNameLength = 255
+
+type Service interface {
+	Create(context.Context, CreateRequest) (CreateResponse, error)
+	// Authenticate resolves a key to the principal it was issued to.
+	// Unknown and revoked keys return ErrNotFound.
+	Authenticate(context.Context, AuthenticateRequest) (AuthenticateResponse, error)
+	List(context.Context, ListRequest) (ListResponse, error)
+	Revoke(context.Context, RevokeRequest) (RevokeResponse, error)
+	// Ping checks that the store of the service is reachable.
+	Ping(context.Context) error
+}
+
+type CreateRequest struct {
+	Name   string
+	Game   string
+	Scopes []Scope
+}
+
+func (r CreateRequest) Validate() error {
+	var err errutil.Error
+
+	if r.Name == "" {
+		err.Add(fmt.Errorf("name must be set"))
+	}
+
+	if len(r.Name) > MaxNameLength {
+		err.Add(fmt.Errorf("name must be at most %d characters", MaxNameLength))
+	}
+
+	if r.Game == "" {
+		err.Add(fmt.Errorf("game must be set"))
+	}
+
+	if len(r.Scopes) == 0 {
+		err.Add(fmt.Errorf("scopes must be set"))
+	}
+
+	for _, s := range r.Scopes {
+		if ve := s.Validate(); ve != nil {
+			err.Add(ve)
+		}
+	}
+
+	return err.Err()
+}
+
+type CreateResponse struct {
+	UUID string
+	// Key is the secret to authenticate with. It is only returned on creation.
+	Key       string
+	Prefix    string
+	CreatedAt time.Time
+}
+
+func (r CreateResponse) Validate() error {
+	var err errutil.Error
+
+	if r.UUID == "" {
+		err.Add(fmt.Errorf("uuid must be set"))
+	}
+
+	if r.Key == "" {
+		err.Add(fmt.Errorf("key must be set"))
+	}
+
+	if r.CreatedAt.IsZero() {
+		err.Add(fmt.Errorf("created at must be set"))
+	}
+
+	return err.Err()
+}
+
+type AuthenticateRequest struct {
+	Key string
+}
+
+func (r AuthenticateRequest) Validate() error {
+	var err errutil.Error
+
+	if r.Key == "" {
+		err.Add(fmt.Errorf("key must be set"))
+	}
+
+	return err.Err()
+}
+
+type AuthenticateResponse struct {
+	Principal Principal
+}
+
+type ListRequest struct {
+	Game string
+}
+
+func (r ListRequest) Validate() error {
+	return nil
+}
+
+type ListResponse struct {
+	Keys []Key
+}
+
+type RevokeRequest struct {
+	UUID string
+}
+
+func (r RevokeRequest) Validate() error {
+	var err errutil.Error
+
+	if r.UUID == "" {
+		err.Add(fmt.Errorf("uuid must be set"))
+	}
+
+	return err.Err()
+}
+
+type RevokeResponse struct {
+	RevokedAt time.Time
+}
+
+func (r RevokeResponse) Validate() error {
+	var err errutil.Error
+
+	if r.RevokedAt.IsZero() {
+		err.Add(fmt.Errorf("revoked at must be set"))
+	}
+
+	return err.Err()
+}
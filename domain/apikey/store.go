@@ -0,0 +1,66 @@
+package apikey
+
+import (
+	"context"
+	"time"
+)
+
+type Store interface {
+	Insert(context.Context, InsertQuery) (InsertResult, error)
+	GetByHash(context.Context, GetByHashQuery) (GetByHashResult, error)
+	List(context.Context, ListQuery) (ListResult, error)
+	Revoke(context.Context, RevokeQuery) (RevokeResult, error)
+	// Ping checks that the store is reachable.
+	Ping(context.Context) error
+	// Close releases the resources of the store.
+	Close() error
+}
+
+type InsertQuery struct {
+	Name   string
+	Game   string
+	Scopes []Scope
+	Prefix string
+	Hash   string
+}
+
+type InsertResult struct {
+	UUID      string
+	CreatedAt time.Time
+}
+
+type GetByHashQuery struct {
+	Hash string
+}
+
+type GetByHashResult struct {
+	Key Key
+}
+
+type ListQuery struct {
+	// Game filters the keys by game, all keys are listed if empty.
+	Game string
+}
+
+type ListResult struct {
+	Keys []Key
+}
+
+type RevokeQuery struct {
+	UUID string
+}
+
+type RevokeResult struct {
+	RevokedAt time.Time
+}
+
+type Key struct {
+	UUID   string
+	Name   string
+	Game   string
+	Scopes []Scope
+	// Prefix is the start of the key, to tell keys apart without storing them.
+	Prefix    string
+	CreatedAt time.Time
+	RevokedAt *time.Time
+}
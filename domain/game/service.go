@@ -0,0 +1,157 @@
+package game
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/vediagames/onlooker/errutil"
+)
+
+const (
+	MaxSlugLength = 64
+	MaxNameLength = 255
+)
+
+var slugRegexp = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
+
+type Service interface {
+	Create(context.Context, CreateRequest) (CreateResponse, error)
+	Get(context.Context, GetRequest) (GetResponse, error)
+	List(context.Context, ListRequest) (ListResponse, error)
+	// RotateSigningSecret replaces the signing secret of a game with a new
+	// random one, which enables signing if it was disabled.
+	RotateSigningSecret(context.Context, RotateSigningSecretRequest) (RotateSigningSecretResponse, error)
+	DisableSigning(context.Context, DisableSigningRequest) error
+	// SetAllowedOrigins replaces the origins the game is served from.
+	SetAllowedOrigins(context.Context, SetAllowedOriginsRequest) error
+}
+
+type CreateRequest struct {
+	Slug string
+	Name string
+}
+
+func (r CreateRequest) Validate() error {
+	var err errutil.Error
+
+	if !slugRegexp.MatchString(r.Slug) {
+		err.Add(fmt.Errorf("slug must only contain lowercase letters, digits and dashes"))
+	}
+
+	if len(r.Slug) > MaxSlugLength {
+		err.Add(fmt.Errorf("slug must be at most %d characters", MaxSlugLength))
+	}
+
+	if r.Name == "" {
+		err.Add(fmt.Errorf("name must be set"))
+	}
+
+	if len(r.Name) > MaxNameLength {
+		err.Add(fmt.Errorf("name must be at most %d characters", MaxNameLength))
+	}
+
+	return err.Err()
+}
+
+type CreateResponse struct {
+	CreatedAt time.Time
+}
+
+func (r CreateResponse) Validate() error {
+	var err errutil.Error
+
+	if r.CreatedAt.IsZero() {
+		err.Add(fmt.Errorf("created at must be set"))
+	}
+
+	return err.Err()
+}
+
+type GetRequest struct {
+	Slug string
+}
+
+func (r GetRequest) Validate() error {
+	var err errutil.Error
+
+	if r.Slug == "" {
+		err.Add(fmt.Errorf("slug must be set"))
+	}
+
+	return err.Err()
+}
+
+type GetResponse struct {
+	Game Game
+}
+
+type ListRequest struct{}
+
+func (r ListRequest) Validate() error {
+	return nil
+}
+
+type ListResponse struct {
+	Games []Game
+}
+
+type RotateSigningSecretRequest struct {
+	Slug string
+}
+
+func (r RotateSigningSecretRequest) Validate() error {
+	var err errutil.Error
+
+	if r.Slug == "" {
+		err.Add(fmt.Errorf("slug must be set"))
+	}
+
+	return err.Err()
+}
+
+type RotateSigningSecretResponse struct {
+	SigningSecret string
+}
+
+type DisableSigningRequest struct {
+	Slug string
+}
+
+func (r DisableSigningRequest) Validate() error {
+	var err errutil.Error
+
+	if r.Slug == "" {
+		err.Add(fmt.Errorf("slug must be set"))
+	}
+
+	return err.Err()
+}
+
+type SetAllowedOriginsRequest struct {
+	Slug           string
+	AllowedOrigins []string
+}
+
+func (r SetAllowedOriginsRequest) Validate() error {
+	var err errutil.Error
+
+	if r.Slug == "" {
+		err.Add(fmt.Errorf("slug must be set"))
+	}
+
+	for _, o := range r.AllowedOrigins {
+		origin, oe := Origin(o)
+		if oe != nil {
+			err.Add(fmt.Errorf("invalid origin %q: %w", o, oe))
+			continue
+		}
+
+		if origin != o {
+			err.Add(fmt.Errorf("invalid origin %q: must be %q", o, origin))
+		}
+	}
+
+	return err.Err()
+}
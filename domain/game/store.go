@@ -0,0 +1,115 @@
+package game
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// DefaultGame owns the sessions logged before games existed and the
+// sessions created by admins without a game.
+const DefaultGame = "default"
+
+type Store interface {
+	Insert(context.Context, InsertQuery) (InsertResult, error)
+	Get(context.Context, GetQuery) (GetResult, error)
+	List(context.Context, ListQuery) (ListResult, error)
+	UpdateSigningSecret(context.Context, UpdateSigningSecretQuery) error
+	UpdateAllowedOrigins(context.Context, UpdateAllowedOriginsQuery) error
+	// Ping checks that the store is reachable.
+	Ping(context.Context) error
+	// Close releases the resources of the store.
+	Close() error
+}
+
+type InsertQuery struct {
+	Slug string
+	Name string
+}
+
+type InsertResult struct {
+	CreatedAt time.Time
+}
+
+type GetQuery struct {
+	Slug string
+}
+
+type GetResult struct {
+	Game Game
+}
+
+type ListQuery struct{}
+
+// UpdateSigningSecretQuery sets the signing secret of a game, empty disables signing.
+type UpdateSigningSecretQuery struct {
+	Slug          string
+	SigningSecret string
+}
+
+type UpdateAllowedOriginsQuery struct {
+	Slug           string
+	AllowedOrigins []string
+}
+
+type ListResult struct {
+	Games []Game
+}
+
+// Game owns sessions and API keys. Data of one game is not visible to
+// the API keys of another game.
+type Game struct {
+	Slug      string
+	Name      string
+	CreatedAt time.Time
+	// SigningSecret is the HMAC key of requests from the API keys of the
+	// game. Requests must be signed if it is set.
+	SigningSecret string
+	// AllowedOrigins are the origins the game is served from, any origin if empty.
+	AllowedOrigins []string
+}
+
+// AllowsOrigin reports whether the game is served from origin.
+func (g Game) AllowsOrigin(origin string) bool {
+	if len(g.AllowedOrigins) == 0 {
+		return true
+	}
+
+	for _, o := range g.AllowedOrigins {
+		if o == origin {
+			return true
+		}
+	}
+
+	return false
+}
+
+type gameKey struct{}
+
+// ContextWithGame returns a copy of ctx which holds g, the game of the API key
+// of the request, so that it is only fetched once per request.
+func ContextWithGame(ctx context.Context, g Game) context.Context {
+	return context.WithValue(ctx, gameKey{}, g)
+}
+
+// GameFromContext returns the game in ctx, if any.
+func GameFromContext(ctx context.Context) (Game, bool) {
+	g, ok := ctx.Value(gameKey{}).(Game)
+	return g, ok
+}
+
+// Origin returns the origin of rawURL, the scheme and host of it.
+func Origin(rawURL string) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid url: %w", err)
+	}
+
+	if u.Scheme == "" || u.Host == "" {
+		return "", fmt.Errorf("invalid url %q: scheme and host must be set", rawURL)
+	}
+
+	return strings.ToLower(u.Scheme + "://" + u.Host), nil
+}
@@ -0,0 +1,11 @@
+package level
+
+import "github.com/vediagames/onlooker/errutil"
+
+var (
+	ErrInvalidArgument  = errutil.KindInvalidArgument
+	ErrNotFound         = errutil.KindNotFound
+	ErrConflict         = errutil.KindConflict
+	ErrUnavailable      = errutil.KindUnavailable
+	ErrPermissionDenied = errutil.KindPermissionDenied
+)
@@ -0,0 +1,124 @@
+package level
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/vediagames/onlooker/errutil"
+)
+
+// SharedEventTable is the table events without a dedicated table are stored in.
+const SharedEventTable = "level_events"
+
+var identifierRegexp = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+type EventDefinition struct {
+	Name   Event    `json:"name"`
+	Fields []string `json:"fields"`
+	Table  string   `json:"table"`
+	// Position allows events to be logged with a position, which is stored in
+	// the x and y columns of the table of the event.
+	Position bool `json:"position"`
+}
+
+func (d EventDefinition) Validate() error {
+	var err errutil.Error
+
+	if ve := d.Name.Validate(); ve != nil {
+		err.Add(ve)
+	}
+
+	if d.Table != "" && !identifierRegexp.MatchString(d.Table) {
+		err.Add(fmt.Errorf("invalid table: %q", d.Table))
+	}
+
+	for _, f := range d.Fields {
+		if f == "" {
+			err.Add(fmt.Errorf("field must not be empty"))
+		}
+	}
+
+	return err.Err()
+}
+
+// TableName returns the table the event is stored in.
+func (d EventDefinition) TableName() string {
+	if d.Table == "" {
+		return SharedEventTable
+	}
+
+	return d.Table
+}
+
+// ValidateMetadata checks that metadata only contains the allowed fields.
+// A definition without fields accepts any metadata.
+func (d EventDefinition) ValidateMetadata(metadata map[string]interface{}) error {
+	if len(d.Fields) == 0 {
+		return nil
+	}
+
+	allowed := make(map[string]struct{}, len(d.Fields))
+	for _, f := range d.Fields {
+		allowed[f] = struct{}{}
+	}
+
+	var err errutil.Error
+
+	for k := range metadata {
+		if _, ok := allowed[k]; !ok {
+			err.Add(fmt.Errorf("metadata field %q is not allowed for event %q", k, d.Name))
+		}
+	}
+
+	return err.Err()
+}
+
+var DefaultEventDefinitions = []EventDefinition{
+	{Name: EventDeath, Table: "level_death_events", Position: true},
+	{Name: EventComplete, Table: "level_complete_events"},
+	{Name: EventGrapplingHookUsage, Table: "level_grappling_hook_events", Position: true},
+	{Name: EventRestart},
+	{Name: EventAbandon},
+}
+
+// Registry holds the event types which can be logged for a level.
+// It is read-only after creation and safe for concurrent use.
+type Registry struct {
+	definitions map[Event]EventDefinition
+}
+
+// NewRegistry creates a registry with the default events and the given definitions.
+func NewRegistry(definitions ...EventDefinition) (*Registry, error) {
+	r := &Registry{
+		definitions: make(map[Event]EventDefinition, len(DefaultEventDefinitions)+len(definitions)),
+	}
+
+	for _, d := range append(DefaultEventDefinitions, definitions...) {
+		if ve := d.Validate(); ve != nil {
+			return nil, fmt.Errorf("invalid event definition %q: %w", d.Name, ve)
+		}
+
+		if _, ok := r.definitions[d.Name]; ok {
+			return nil, fmt.Errorf("event %q is already registered", d.Name)
+		}
+
+		r.definitions[d.Name] = d
+	}
+
+	return r, nil
+}
+
+func (r Registry) Get(e Event) (EventDefinition, bool) {
+	d, ok := r.definitions[e]
+	return d, ok
+}
+
+func (r Registry) Definitions() []EventDefinition {
+	defs := make([]EventDefinition, 0, len(r.definitions))
+
+	for _, d := range r.definitions {
+		defs = append(defs, d)
+	}
+
+	return defs
+}
@@ -3,9 +3,36 @@ package level
 import (
 	"context"
 	"fmt"
+	"image"
 	"time"
 
 	"github.com/vediagames/onlooker/errutil"
+	"github.com/vediagames/onlooker/pagination"
+)
+
+// MaxIdempotencyKeyLength is the maximum length of a client supplied idempotency key.
+const MaxIdempotencyKeyLength = 255
+
+// MaxCauseLength is the maximum length of the cause of a death.
+const MaxCauseLength = 255
+
+const (
+	// MaxCoordinate is the maximum absolute value of the coordinates of positions.
+	MaxCoordinate = 1e9
+	// MinCellSize is the minimum size of grid cells, the cells of positions are
+	// then numbered within 64 bit integers.
+	MinCellSize = 1e-3
+)
+
+const (
+	// MaxBackgroundBytes is the maximum size of an uploaded background.
+	MaxBackgroundBytes = 10 << 20
+	// MaxBackgroundSize is the maximum width and height in pixels of a background.
+	// Backgrounds are decoded into memory to draw heatmaps, at 4 bytes per pixel.
+	MaxBackgroundSize = 2048
+	// MinBackgroundScale is the minimum size of a pixel of a background in world
+	// coordinates, so that the pixels of positions are numbered within 64 bit integers.
+	MinBackgroundScale = 1e-3
 )
 
 type Service interface {
@@ -13,13 +40,29 @@ type Service interface {
 	LogDeath(context.Context, LogDeathRequest) (LogDeathResponse, error)
 	LogComplete(context.Context, LogCompleteRequest) (LogCompleteResponse, error)
 	LogGrapplingHookUsage(context.Context, LogGrapplingHookUsageRequest) (LogGrapplingHookUsageResponse, error)
+	LogEvent(context.Context, LogEventRequest) (LogEventResponse, error)
+	LogEvents(context.Context, LogEventsRequest) (LogEventsResponse, error)
+	Get(context.Context, GetRequest) (GetResponse, error)
+	List(context.Context, ListRequest) (ListResponse, error)
+	ListEvents(context.Context, ListEventsRequest) (ListEventsResponse, error)
+	// Grid counts the events with a position of a level per grid cell.
+	Grid(context.Context, GridRequest) (GridResponse, error)
+	// SetBackground replaces the background heatmaps of a level are drawn over.
+	SetBackground(context.Context, SetBackgroundRequest) (SetBackgroundResponse, error)
+	// Heatmap draws the grid of a level over its background if it has one.
+	Heatmap(context.Context, HeatmapRequest) (HeatmapResponse, error)
+	// Ping checks that the store of the service is reachable.
+	Ping(context.Context) error
 }
 
 type CreateRequest struct {
-	SessionUUID string
-	Level       int
-	ClientTime  time.Time
-	Metadata    map[string]interface{}
+	SessionUUID    string
+	Level          int
+	ClientTime     time.Time
+	Metadata       map[string]interface{}
+	IdempotencyKey string
+	// Game restricts the session to a game, any game if empty.
+	Game string
 }
 
 func (r CreateRequest) Validate() error {
@@ -37,6 +80,10 @@ func (r CreateRequest) Validate() error {
 		err.Add(fmt.Errorf("client time must be set"))
 	}
 
+	if len(r.IdempotencyKey) > MaxIdempotencyKeyLength {
+		err.Add(fmt.Errorf("idempotency key must be at most %d characters", MaxIdempotencyKeyLength))
+	}
+
 	return err.Err()
 }
 
@@ -59,9 +106,23 @@ func (r CreateResponse) Validate() error {
 	return err.Err()
 }
 
+// EventRequest is a request which logs an event of a level.
+type EventRequest interface {
+	Validate() error
+	LogEventRequest() LogEventRequest
+}
+
 type LogDeathRequest struct {
-	UUID       string
-	ClientTime time.Time
+	UUID           string
+	ClientTime     time.Time
+	Metadata       map[string]interface{}
+	IdempotencyKey string
+	// Game restricts the level to a game, any game if empty.
+	Game string
+	// Position is where the player died, optional.
+	Position *Position
+	// Cause is what killed the player, optional.
+	Cause string
 }
 
 func (r LogDeathRequest) Validate() error {
@@ -78,6 +139,19 @@ func (r LogDeathRequest) Validate() error {
 	return err.Err()
 }
 
+func (r LogDeathRequest) LogEventRequest() LogEventRequest {
+	return LogEventRequest{
+		UUID:           r.UUID,
+		Event:          EventDeath,
+		ClientTime:     r.ClientTime,
+		Metadata:       r.Metadata,
+		IdempotencyKey: r.IdempotencyKey,
+		Game:           r.Game,
+		Position:       r.Position,
+		Cause:          r.Cause,
+	}
+}
+
 type LogDeathResponse struct {
 	UUID       string
 	ServerTime time.Time
@@ -102,6 +176,10 @@ type LogCompleteRequest struct {
 	ClientTime     time.Time
 	Achievement    Achievement
 	CompletionTime time.Duration
+	Metadata       map[string]interface{}
+	IdempotencyKey string
+	// Game restricts the level to a game, any game if empty.
+	Game string
 }
 
 func (r LogCompleteRequest) Validate() error {
@@ -122,6 +200,25 @@ func (r LogCompleteRequest) Validate() error {
 	return err.Err()
 }
 
+func (r LogCompleteRequest) LogEventRequest() LogEventRequest {
+	metadata := make(map[string]interface{}, len(r.Metadata)+2)
+	for k, v := range r.Metadata {
+		metadata[k] = v
+	}
+
+	metadata["achievement"] = r.Achievement
+	metadata["completion_time"] = r.CompletionTime
+
+	return LogEventRequest{
+		UUID:           r.UUID,
+		Event:          EventComplete,
+		ClientTime:     r.ClientTime,
+		Metadata:       metadata,
+		IdempotencyKey: r.IdempotencyKey,
+		Game:           r.Game,
+	}
+}
+
 type LogCompleteResponse struct {
 	UUID       string
 	ServerTime time.Time
@@ -142,8 +239,14 @@ func (r LogCompleteResponse) Validate() error {
 }
 
 type LogGrapplingHookUsageRequest struct {
-	UUID       string
-	ClientTime time.Time
+	UUID           string
+	ClientTime     time.Time
+	Metadata       map[string]interface{}
+	IdempotencyKey string
+	// Game restricts the level to a game, any game if empty.
+	Game string
+	// Position is where the grappling hook was used, optional.
+	Position *Position
 }
 
 func (r LogGrapplingHookUsageRequest) Validate() error {
@@ -160,6 +263,18 @@ func (r LogGrapplingHookUsageRequest) Validate() error {
 	return err.Err()
 }
 
+func (r LogGrapplingHookUsageRequest) LogEventRequest() LogEventRequest {
+	return LogEventRequest{
+		UUID:           r.UUID,
+		Event:          EventGrapplingHookUsage,
+		ClientTime:     r.ClientTime,
+		Metadata:       r.Metadata,
+		IdempotencyKey: r.IdempotencyKey,
+		Game:           r.Game,
+		Position:       r.Position,
+	}
+}
+
 type LogGrapplingHookUsageResponse struct {
 	UUID       string
 	ServerTime time.Time
@@ -179,6 +294,344 @@ func (r LogGrapplingHookUsageResponse) Validate() error {
 	return err.Err()
 }
 
+type LogEventRequest struct {
+	UUID           string
+	Event          Event
+	ClientTime     time.Time
+	Metadata       map[string]interface{}
+	IdempotencyKey string
+	// Game restricts the level to a game, any game if empty.
+	Game string
+	// Position is where the event happened, only for events with a position.
+	Position *Position
+	// Cause is why the player died, only for deaths.
+	Cause string
+}
+
+func (r LogEventRequest) Validate() error {
+	var err errutil.Error
+
+	if r.UUID == "" {
+		err.Add(fmt.Errorf("uuid must be set"))
+	}
+
+	if ve := r.Event.Validate(); ve != nil {
+		err.Add(ve)
+	}
+
+	if r.ClientTime.IsZero() {
+		err.Add(fmt.Errorf("client time must be set"))
+	}
+
+	if len(r.IdempotencyKey) > MaxIdempotencyKeyLength {
+		err.Add(fmt.Errorf("idempotency key must be at most %d characters", MaxIdempotencyKeyLength))
+	}
+
+	if r.Cause != "" && r.Event != EventDeath {
+		err.Add(fmt.Errorf("cause must only be set for %q events", EventDeath))
+	}
+
+	if len(r.Cause) > MaxCauseLength {
+		err.Add(fmt.Errorf("cause must be at most %d characters", MaxCauseLength))
+	}
+
+	if r.Position != nil {
+		if ve := r.Position.Validate(); ve != nil {
+			err.Add(ve)
+		}
+	}
+
+	return err.Err()
+}
+
+func (r LogEventRequest) LogEventRequest() LogEventRequest {
+	return r
+}
+
+type LogEventResponse struct {
+	UUID       string
+	ServerTime time.Time
+}
+
+func (r LogEventResponse) Validate() error {
+	var err errutil.Error
+
+	if r.UUID == "" {
+		err.Add(fmt.Errorf("uuid must be set"))
+	}
+
+	if r.ServerTime.IsZero() {
+		err.Add(fmt.Errorf("server time must be set"))
+	}
+
+	return err.Err()
+}
+
+// LogEventsRequest logs a batch of events. If Atomic is set, nothing is
+// logged when one of the requests fails, otherwise the failed requests
+// are reported in the response.
+type LogEventsRequest struct {
+	Requests []EventRequest
+	Atomic   bool
+}
+
+func (r LogEventsRequest) Validate() error {
+	var err errutil.Error
+
+	if len(r.Requests) == 0 {
+		err.Add(fmt.Errorf("requests must be set"))
+	}
+
+	return err.Err()
+}
+
+type LogEventsResponse struct {
+	Results []LogEventsResult
+}
+
+type LogEventsResult struct {
+	UUID       string
+	ServerTime time.Time
+	Err        error
+}
+
+type GetRequest struct {
+	UUID string
+	// Game restricts the level to a game, any game if empty.
+	Game string
+}
+
+func (r GetRequest) Validate() error {
+	var err errutil.Error
+
+	if r.UUID == "" {
+		err.Add(fmt.Errorf("uuid must be set"))
+	}
+
+	return err.Err()
+}
+
+type GetResponse struct {
+	Level Level
+}
+
+func (r GetResponse) Validate() error {
+	var err errutil.Error
+
+	if r.Level.UUID == "" {
+		err.Add(fmt.Errorf("level uuid must be set"))
+	}
+
+	return err.Err()
+}
+
+type ListRequest struct {
+	SessionUUID string
+	Cursor      pagination.Cursor
+	Limit       int
+	// Game restricts the session to a game, any game if empty.
+	Game string
+}
+
+func (r ListRequest) Validate() error {
+	var err errutil.Error
+
+	if r.SessionUUID == "" {
+		err.Add(fmt.Errorf("session uuid must be set"))
+	}
+
+	if ve := r.Cursor.Validate(); ve != nil {
+		err.Add(ve)
+	}
+
+	if ve := pagination.ValidateLimit(r.Limit); ve != nil {
+		err.Add(ve)
+	}
+
+	return err.Err()
+}
+
+type ListResponse struct {
+	Levels     []Level
+	NextCursor pagination.Cursor
+}
+
+type ListEventsRequest struct {
+	LevelUUID string
+	Events    []Event
+	From      time.Time
+	To        time.Time
+	Cursor    pagination.Cursor
+	Limit     int
+	// Game restricts the level to a game, any game if empty.
+	Game string
+}
+
+func (r ListEventsRequest) Validate() error {
+	var err errutil.Error
+
+	if r.LevelUUID == "" {
+		err.Add(fmt.Errorf("level uuid must be set"))
+	}
+
+	for _, e := range r.Events {
+		if ve := e.Validate(); ve != nil {
+			err.Add(ve)
+		}
+	}
+
+	if !r.From.IsZero() && !r.To.IsZero() && r.To.Before(r.From) {
+		err.Add(fmt.Errorf("to must be after from"))
+	}
+
+	if ve := r.Cursor.Validate(); ve != nil {
+		err.Add(ve)
+	}
+
+	if ve := pagination.ValidateLimit(r.Limit); ve != nil {
+		err.Add(ve)
+	}
+
+	return err.Err()
+}
+
+type ListEventsResponse struct {
+	Events     []LevelEvent
+	NextCursor pagination.Cursor
+}
+
+type GridRequest struct {
+	Level    int
+	Event    Event
+	CellSize float64
+	// Cause restricts deaths to a cause, any cause if empty.
+	Cause string
+	From  time.Time
+	To    time.Time
+	// Game restricts the sessions to a game, any game if empty.
+	Game string
+}
+
+func (r GridRequest) Validate() error {
+	var err errutil.Error
+
+	if r.Level < 0 {
+		err.Add(fmt.Errorf("level must not be negative"))
+	}
+
+	if ve := r.Event.Validate(); ve != nil {
+		err.Add(ve)
+	}
+
+	if !(r.CellSize >= MinCellSize) {
+		err.Add(fmt.Errorf("cell size must be at least %g", MinCellSize))
+	}
+
+	if r.Cause != "" && r.Event != EventDeath {
+		err.Add(fmt.Errorf("cause must only be set for %q events", EventDeath))
+	}
+
+	if !r.From.IsZero() && !r.To.IsZero() && r.To.Before(r.From) {
+		err.Add(fmt.Errorf("to must be after from"))
+	}
+
+	return err.Err()
+}
+
+type GridResponse struct {
+	Cells []Cell
+}
+
+type SetBackgroundRequest struct {
+	Game  string
+	Level int
+	// Image is a PNG, JPEG or GIF image.
+	Image []byte
+	// Origin is the world coordinates of the top left pixel of the image.
+	Origin Position
+	// Scale is the size of a pixel of the image in world coordinates.
+	Scale float64
+}
+
+func (r SetBackgroundRequest) Validate() error {
+	var err errutil.Error
+
+	if r.Game == "" {
+		err.Add(fmt.Errorf("game must be set"))
+	}
+
+	if r.Level < 0 {
+		err.Add(fmt.Errorf("level must not be negative"))
+	}
+
+	if len(r.Image) == 0 {
+		err.Add(fmt.Errorf("image must be set"))
+	}
+
+	if len(r.Image) > MaxBackgroundBytes {
+		err.Add(fmt.Errorf("image must be at most %d bytes", MaxBackgroundBytes))
+	}
+
+	if ve := r.Origin.Validate(); ve != nil {
+		err.Add(fmt.Errorf("origin: %w", ve))
+	}
+
+	if !(r.Scale >= MinBackgroundScale) {
+		err.Add(fmt.Errorf("scale must be at least %g", MinBackgroundScale))
+	}
+
+	return err.Err()
+}
+
+type SetBackgroundResponse struct {
+	Format    string
+	Width     int
+	Height    int
+	UpdatedAt time.Time
+}
+
+type HeatmapRequest struct {
+	Level    int
+	Event    Event
+	CellSize float64
+	// Cause restricts deaths to a cause, any cause if empty.
+	Cause string
+	From  time.Time
+	To    time.Time
+	// Game is the game of the sessions and the background.
+	Game string
+}
+
+func (r HeatmapRequest) Validate() error {
+	var err errutil.Error
+
+	if ve := r.GridRequest().Validate(); ve != nil {
+		err.Add(ve)
+	}
+
+	if r.Game == "" {
+		err.Add(fmt.Errorf("game must be set"))
+	}
+
+	return err.Err()
+}
+
+func (r HeatmapRequest) GridRequest() GridRequest {
+	return GridRequest{
+		Level:    r.Level,
+		Event:    r.Event,
+		CellSize: r.CellSize,
+		Cause:    r.Cause,
+		From:     r.From,
+		To:       r.To,
+		Game:     r.Game,
+	}
+}
+
+type HeatmapResponse struct {
+	Image image.Image
+}
+
 type Achievement string
 
 const (
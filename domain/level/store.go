@@ -3,21 +3,48 @@ package level
 import (
 	"context"
 	"fmt"
+	"math"
 	"time"
 
 	"github.com/vediagames/onlooker/errutil"
+	"github.com/vediagames/onlooker/pagination"
 )
 
+// Store stores levels, which are attempts of a level in a session, and their events.
+// An attempt is open until an event with an outcome ends it: complete, restart and
+// abandon end the attempt, and so does the end of its session. Later events of an
+// ended attempt return ErrConflict.
 type Store interface {
 	Insert(context.Context, InsertQuery) (InsertResult, error)
+	// InsertEvent inserts the event and ends the attempt if the event has an outcome,
+	// in one transaction. Events of ended attempts return ErrConflict, unless they
+	// replay an idempotency key.
 	InsertEvent(context.Context, InsertEventQuery) (InsertEventResult, error)
+	// InsertEvents inserts a batch of events like InsertEvent, in one transaction.
+	InsertEvents(context.Context, InsertEventsQuery) (InsertEventsResult, error)
+	Get(context.Context, GetQuery) (GetResult, error)
+	List(context.Context, ListQuery) (ListResult, error)
+	ListEvents(context.Context, ListEventsQuery) (ListEventsResult, error)
+	// Grid counts the events with a position of a level per grid cell.
+	Grid(context.Context, GridQuery) (GridResult, error)
+	// SetBackground replaces the background of a level of a game.
+	SetBackground(context.Context, SetBackgroundQuery) (SetBackgroundResult, error)
+	// GetBackground returns the background of a level of a game, ErrNotFound if it has none.
+	GetBackground(context.Context, GetBackgroundQuery) (GetBackgroundResult, error)
+	// Ping checks that the store is reachable.
+	Ping(context.Context) error
+	// Close releases the resources of the store.
+	Close() error
 }
 
 type InsertQuery struct {
-	SessionUUID string
-	Level       int
-	ClientTime  time.Time
-	Metadata    map[string]interface{}
+	SessionUUID    string
+	Level          int
+	ClientTime     time.Time
+	Metadata       map[string]interface{}
+	IdempotencyKey string
+	// Game restricts the session to a game, any game if empty.
+	Game string
 }
 
 type InsertResult struct {
@@ -26,10 +53,19 @@ type InsertResult struct {
 }
 
 type InsertEventQuery struct {
-	UUID       string
-	Event      Event
-	ClientTime time.Time
-	Metadata   map[string]interface{}
+	UUID           string
+	Event          Event
+	ClientTime     time.Time
+	Metadata       map[string]interface{}
+	IdempotencyKey string
+	// Game restricts the level to a game, any game if empty.
+	Game string
+	// Position is where the event happened, optional.
+	Position *Position
+	// Cause is why the player died, only for deaths.
+	Cause string
+	// Outcome ends the attempt of the level with the event, if set.
+	Outcome Outcome
 }
 
 func (q InsertEventQuery) Validate() error {
@@ -39,6 +75,12 @@ func (q InsertEventQuery) Validate() error {
 		err.Add(ve)
 	}
 
+	if q.Outcome != "" {
+		if ve := q.Outcome.Validate(); ve != nil {
+			err.Add(ve)
+		}
+	}
+
 	return err.Err()
 }
 
@@ -47,19 +89,202 @@ type InsertEventResult struct {
 	ServerTime time.Time
 }
 
+// InsertEventsQuery inserts a batch of events in one transaction.
+// If Atomic is set, nothing is inserted when one of the events fails,
+// otherwise the failed events are reported in the result.
+type InsertEventsQuery struct {
+	Events []InsertEventQuery
+	Atomic bool
+}
+
+type InsertEventsResult struct {
+	Results []InsertEventsItemResult
+}
+
+type InsertEventsItemResult struct {
+	UUID       string
+	ServerTime time.Time
+	Err        error
+}
+
+type GetQuery struct {
+	UUID string
+	// Game restricts the level to a game, any game if empty.
+	Game string
+}
+
+type GetResult struct {
+	Level Level
+}
+
+type ListQuery struct {
+	SessionUUID string
+	Cursor      pagination.Cursor
+	Limit       int
+	// Game restricts the session to a game, any game if empty.
+	Game string
+}
+
+type ListResult struct {
+	Levels     []Level
+	NextCursor pagination.Cursor
+}
+
+type ListEventsQuery struct {
+	LevelUUID string
+	Events    []Event
+	From      time.Time
+	To        time.Time
+	Cursor    pagination.Cursor
+	Limit     int
+	// Game restricts the level to a game, any game if empty.
+	Game string
+}
+
+type ListEventsResult struct {
+	Events     []LevelEvent
+	NextCursor pagination.Cursor
+}
+
+type GridQuery struct {
+	Level    int
+	Event    Event
+	CellSize float64
+	// Cause restricts deaths to a cause, any cause if empty.
+	Cause string
+	From  time.Time
+	To    time.Time
+	// Game restricts the sessions to a game, any game if empty.
+	Game string
+}
+
+type GridResult struct {
+	Cells []Cell
+}
+
+// Cell counts the events whose position is within the cell. The cell at X and Y
+// covers the positions from X*size to (X+1)*size and Y*size to (Y+1)*size.
+type Cell struct {
+	X     int
+	Y     int
+	Count int
+}
+
+type SetBackgroundQuery struct {
+	Game   string
+	Level  int
+	Image  []byte
+	Format string
+	Origin Position
+	Scale  float64
+}
+
+type SetBackgroundResult struct {
+	UpdatedAt time.Time
+}
+
+type GetBackgroundQuery struct {
+	Game  string
+	Level int
+	// WithoutImage leaves out the image, to check if the background changed
+	// without loading it.
+	WithoutImage bool
+}
+
+type GetBackgroundResult struct {
+	Background Background
+}
+
+// Background is an image of a level which heatmaps are drawn over. The top left
+// pixel of the image is at Origin in world coordinates and each pixel is Scale
+// world units wide and high.
+type Background struct {
+	Game  string
+	Level int
+	Image []byte
+	// Format is the format of the image as reported by image.Decode.
+	Format    string
+	Origin    Position
+	Scale     float64
+	UpdatedAt time.Time
+}
+
+type Level struct {
+	UUID        string
+	SessionUUID string
+	Level       int
+	ClientTime  time.Time
+	ServerTime  time.Time
+	Metadata    map[string]interface{}
+	// EndedAt is set once the attempt ended, or its session ended first.
+	EndedAt *time.Time
+	// Outcome is set once the attempt ended, attempts of ended sessions are abandoned.
+	Outcome Outcome
+}
+
+// Ended reports whether the attempt is closed and no longer accepts events.
+func (l Level) Ended() bool {
+	return l.EndedAt != nil
+}
+
+type LevelEvent struct {
+	UUID       string
+	LevelUUID  string
+	Event      Event
+	ClientTime time.Time
+	ServerTime time.Time
+	Metadata   map[string]interface{}
+	Position   *Position
+	Cause      string
+}
+
+// Position is a point in the world coordinates of a level.
+type Position struct {
+	X float64
+	Y float64
+}
+
+func (p Position) Validate() error {
+	// NaN fails the comparisons as well.
+	if !(math.Abs(p.X) <= MaxCoordinate && math.Abs(p.Y) <= MaxCoordinate) {
+		return fmt.Errorf("position must be within -%g and %g", MaxCoordinate, MaxCoordinate)
+	}
+
+	return nil
+}
+
 type Event string
 
 func (e Event) Validate() error {
-	switch e {
-	case EventDeath, EventComplete, EventGrapplingHookUsage:
-		return nil
-	default:
+	if !identifierRegexp.MatchString(string(e)) {
 		return fmt.Errorf("invalid event: %q", e)
 	}
+
+	return nil
 }
 
 const (
 	EventDeath              Event = "death"
 	EventComplete           Event = "complete"
 	EventGrapplingHookUsage Event = "grappling_hook_usage"
+	EventRestart            Event = "restart"
+	EventAbandon            Event = "abandon"
+)
+
+// Outcome is how a level attempt ended.
+type Outcome string
+
+const (
+	OutcomeComplete Outcome = "complete"
+	OutcomeRestart  Outcome = "restart"
+	OutcomeAbandon  Outcome = "abandon"
 )
+
+func (o Outcome) Validate() error {
+	switch o {
+	case OutcomeComplete, OutcomeRestart, OutcomeAbandon:
+		return nil
+	default:
+		return fmt.Errorf("invalid outcome: %q", o)
+	}
+}
@@ -0,0 +1,64 @@
+package player
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+
+	"github.com/vediagames/onlooker/errutil"
+)
+
+// MaxIDLength is the maximum length of a client generated player ID.
+const MaxIDLength = 128
+
+var idRegexp = regexp.MustCompile(`^[A-Za-z0-9._:-]+$`)
+
+// ValidateID checks that id is a valid player ID.
+func ValidateID(id string) error {
+	if !idRegexp.MatchString(id) {
+		return fmt.Errorf("player id must only contain letters, digits, dots, colons, underscores and dashes")
+	}
+
+	if len(id) > MaxIDLength {
+		return fmt.Errorf("player id must be at most %d characters", MaxIDLength)
+	}
+
+	return nil
+}
+
+type Service interface {
+	Get(context.Context, GetRequest) (GetResponse, error)
+}
+
+type GetRequest struct {
+	Game string
+	ID   string
+}
+
+func (r GetRequest) Validate() error {
+	var err errutil.Error
+
+	if r.Game == "" {
+		err.Add(fmt.Errorf("game must be set"))
+	}
+
+	if ve := ValidateID(r.ID); ve != nil {
+		err.Add(ve)
+	}
+
+	return err.Err()
+}
+
+type GetResponse struct {
+	Player Player
+}
+
+func (r GetResponse) Validate() error {
+	var err errutil.Error
+
+	if r.Player.ID == "" {
+		err.Add(fmt.Errorf("player id must be set"))
+	}
+
+	return err.Err()
+}
@@ -0,0 +1,45 @@
+package player
+
+import (
+	"context"
+	"time"
+)
+
+type Store interface {
+	// Touch creates the player if it is new and marks it as seen now.
+	Touch(context.Context, TouchQuery) (TouchResult, error)
+	Get(context.Context, GetQuery) (GetResult, error)
+	// Ping checks that the store is reachable.
+	Ping(context.Context) error
+	// Close releases the resources of the store.
+	Close() error
+}
+
+type TouchQuery struct {
+	Game string
+	ID   string
+}
+
+type TouchResult struct {
+	Player Player
+}
+
+type GetQuery struct {
+	Game string
+	ID   string
+}
+
+type GetResult struct {
+	Player Player
+}
+
+// Player is an anonymous player of a game, identified by an ID the client
+// generates once and keeps, so sessions of the same browser can be tied together.
+type Player struct {
+	Game string
+	ID   string
+	// FirstSeenAt is when the first session of the player started.
+	FirstSeenAt time.Time
+	// LastSeenAt is when the last session of the player started.
+	LastSeenAt time.Time
+}
@@ -5,24 +5,46 @@ import (
 	"fmt"
 	"time"
 
+	playerdomain "github.com/vediagames/onlooker/domain/player"
 	"github.com/vediagames/onlooker/errutil"
+	"github.com/vediagames/onlooker/pagination"
 )
 
+// MaxIdempotencyKeyLength is the maximum length of a client supplied idempotency key.
+const MaxIdempotencyKeyLength = 255
+
 type Service interface {
 	Create(context.Context, CreateRequest) (CreateResponse, error)
+	Get(context.Context, GetRequest) (GetResponse, error)
+	// List lists the sessions of a player.
+	List(context.Context, ListRequest) (ListResponse, error)
+	Heartbeat(context.Context, HeartbeatRequest) (HeartbeatResponse, error)
+	End(context.Context, EndRequest) (EndResponse, error)
+	// EndIdle ends the sessions without a heartbeat within the timeout.
+	EndIdle(context.Context, EndIdleRequest) (EndIdleResponse, error)
+	// Ping checks that the store of the service is reachable.
+	Ping(context.Context) error
 }
 
 type CreateRequest struct {
-	ClientTime time.Time
-	IP         string
-	URL        string
-	Timezone   string
-	Metadata   map[string]interface{}
+	Game           string
+	ClientTime     time.Time
+	IP             string
+	URL            string
+	Timezone       string
+	Metadata       map[string]interface{}
+	IdempotencyKey string
+	// PlayerID is the anonymous ID the client generated for the player, optional.
+	PlayerID string
 }
 
 func (r CreateRequest) Validate() error {
 	var err errutil.Error
 
+	if r.Game == "" {
+		err.Add(fmt.Errorf("game must be set"))
+	}
+
 	if r.ClientTime.IsZero() {
 		err.Add(fmt.Errorf("client time must be set"))
 	}
@@ -39,6 +61,16 @@ func (r CreateRequest) Validate() error {
 		err.Add(fmt.Errorf("timezone must be set"))
 	}
 
+	if len(r.IdempotencyKey) > MaxIdempotencyKeyLength {
+		err.Add(fmt.Errorf("idempotency key must be at most %d characters", MaxIdempotencyKeyLength))
+	}
+
+	if r.PlayerID != "" {
+		if ve := playerdomain.ValidateID(r.PlayerID); ve != nil {
+			err.Add(ve)
+		}
+	}
+
 	return err.Err()
 }
 
@@ -60,3 +92,125 @@ func (r CreateResponse) Validate() error {
 
 	return err.Err()
 }
+
+type GetRequest struct {
+	UUID string
+	// Game restricts the session to a game, any game if empty.
+	Game string
+}
+
+func (r GetRequest) Validate() error {
+	var err errutil.Error
+
+	if r.UUID == "" {
+		err.Add(fmt.Errorf("uuid must be set"))
+	}
+
+	return err.Err()
+}
+
+type GetResponse struct {
+	Session Session
+}
+
+func (r GetResponse) Validate() error {
+	var err errutil.Error
+
+	if r.Session.UUID == "" {
+		err.Add(fmt.Errorf("session uuid must be set"))
+	}
+
+	return err.Err()
+}
+
+type ListRequest struct {
+	Game     string
+	PlayerID string
+	Cursor   pagination.Cursor
+	Limit    int
+}
+
+func (r ListRequest) Validate() error {
+	var err errutil.Error
+
+	if r.Game == "" {
+		err.Add(fmt.Errorf("game must be set"))
+	}
+
+	if r.PlayerID == "" {
+		err.Add(fmt.Errorf("player id must be set"))
+	}
+
+	if ve := r.Cursor.Validate(); ve != nil {
+		err.Add(ve)
+	}
+
+	if ve := pagination.ValidateLimit(r.Limit); ve != nil {
+		err.Add(ve)
+	}
+
+	return err.Err()
+}
+
+type ListResponse struct {
+	Sessions   []Session
+	NextCursor pagination.Cursor
+}
+
+type HeartbeatRequest struct {
+	UUID string
+	// Game restricts the session to a game, any game if empty.
+	Game string
+}
+
+func (r HeartbeatRequest) Validate() error {
+	var err errutil.Error
+
+	if r.UUID == "" {
+		err.Add(fmt.Errorf("uuid must be set"))
+	}
+
+	return err.Err()
+}
+
+type HeartbeatResponse struct {
+	LastSeenAt time.Time
+}
+
+type EndRequest struct {
+	UUID string
+	// Game restricts the session to a game, any game if empty.
+	Game string
+}
+
+func (r EndRequest) Validate() error {
+	var err errutil.Error
+
+	if r.UUID == "" {
+		err.Add(fmt.Errorf("uuid must be set"))
+	}
+
+	return err.Err()
+}
+
+type EndResponse struct {
+	EndedAt time.Time
+}
+
+type EndIdleRequest struct {
+	Timeout time.Duration
+}
+
+func (r EndIdleRequest) Validate() error {
+	var err errutil.Error
+
+	if r.Timeout <= 0 {
+		err.Add(fmt.Errorf("timeout must be above 0"))
+	}
+
+	return err.Err()
+}
+
+type EndIdleResponse struct {
+	Count int
+}
@@ -3,21 +3,121 @@ package session
 import (
 	"context"
 	"time"
+
+	"github.com/vediagames/onlooker/pagination"
 )
 
 type Store interface {
+	// Insert inserts a session and touches its player in one transaction, unless
+	// the idempotency key replays an existing session.
 	Insert(context.Context, InsertQuery) (InsertResult, error)
+	Get(context.Context, GetQuery) (GetResult, error)
+	// List lists the sessions of a player.
+	List(context.Context, ListQuery) (ListResult, error)
+	// Heartbeat marks an open session as seen now. Ended sessions return ErrConflict.
+	Heartbeat(context.Context, HeartbeatQuery) (HeartbeatResult, error)
+	// End ends a session now, ending an ended session keeps its end time.
+	End(context.Context, EndQuery) (EndResult, error)
+	// EndIdle ends the open sessions not seen within the timeout at the time they were last seen.
+	EndIdle(context.Context, EndIdleQuery) (EndIdleResult, error)
+	// Ping checks that the store is reachable.
+	Ping(context.Context) error
+	// Close releases the resources of the store.
+	Close() error
 }
 
 type InsertQuery struct {
+	Game           string
+	ClientTime     time.Time
+	IP             string
+	URL            string
+	Timezone       string
+	Metadata       map[string]interface{}
+	IdempotencyKey string
+	// PlayerID ties the session to a player of the game, anonymous if empty.
+	PlayerID string
+	// UnknownOrigin flags sessions whose url is not an allowed origin of the game.
+	UnknownOrigin bool
+}
+
+type InsertResult struct {
+	UUID       string
+	ServerTime time.Time
+}
+
+type GetQuery struct {
+	UUID string
+	// Game restricts the session to a game, any game if empty.
+	Game string
+}
+
+type GetResult struct {
+	Session Session
+}
+
+type ListQuery struct {
+	Game     string
+	PlayerID string
+	Cursor   pagination.Cursor
+	Limit    int
+}
+
+type ListResult struct {
+	Sessions   []Session
+	NextCursor pagination.Cursor
+}
+
+type HeartbeatQuery struct {
+	UUID string
+	// Game restricts the session to a game, any game if empty.
+	Game string
+}
+
+type HeartbeatResult struct {
+	LastSeenAt time.Time
+}
+
+type EndQuery struct {
+	UUID string
+	// Game restricts the session to a game, any game if empty.
+	Game string
+}
+
+type EndResult struct {
+	EndedAt time.Time
+}
+
+type EndIdleQuery struct {
+	Timeout time.Duration
+}
+
+type EndIdleResult struct {
+	Count int
+}
+
+type Session struct {
+	UUID       string
+	Game       string
 	ClientTime time.Time
+	ServerTime time.Time
 	IP         string
 	URL        string
 	Timezone   string
 	Metadata   map[string]interface{}
+	// PlayerID is the player of the session, empty if it is anonymous.
+	PlayerID string
+	// UnknownOrigin is set if the url is not an allowed origin of the game.
+	UnknownOrigin bool
+	LastSeenAt    time.Time
+	// EndedAt is set once the session ended or timed out.
+	EndedAt *time.Time
 }
 
-type InsertResult struct {
-	UUID       string
-	ServerTime time.Time
+// Duration returns how long the session lasted, until it was last seen if it is still open.
+func (s Session) Duration() time.Duration {
+	if s.EndedAt != nil {
+		return s.EndedAt.Sub(s.ServerTime)
+	}
+
+	return s.LastSeenAt.Sub(s.ServerTime)
 }
@@ -5,10 +5,50 @@ import (
 	"strings"
 )
 
+// Kind classifies an error so callers can react to it without
+// knowing which package returned it.
+type Kind string
+
+const (
+	KindInvalidArgument Kind = "invalid_argument"
+	KindNotFound        Kind = "not_found"
+	KindConflict        Kind = "conflict"
+	KindUnavailable     Kind = "unavailable"
+	// KindPermissionDenied is returned when the caller may not access an object.
+	KindPermissionDenied Kind = "permission_denied"
+)
+
+func (k Kind) Error() string {
+	return strings.ReplaceAll(string(k), "_", " ")
+}
+
 type Error struct {
+	kind Kind
 	errs []error
 }
 
+// New creates an error of the given kind from errs.
+func New(kind Kind, errs ...error) Error {
+	return Error{
+		kind: kind,
+		errs: errs,
+	}
+}
+
+// WithKind sets the kind of err. If err is an Error its errors are kept.
+func WithKind(err error, kind Kind) error {
+	if err == nil {
+		return nil
+	}
+
+	if e, ok := err.(Error); ok {
+		e.kind = kind
+		return e
+	}
+
+	return New(kind, err)
+}
+
 func (e *Error) Add(err error) {
 	e.errs = append(e.errs, err)
 }
@@ -21,6 +61,22 @@ func (e Error) Err() error {
 	return e
 }
 
+// Kind returns the kind of the error, empty if it was not set.
+func (e Error) Kind() Kind {
+	return e.kind
+}
+
+// Errors returns the errors which were added.
+func (e Error) Errors() []error {
+	return e.errs
+}
+
+// Is reports whether target is the kind of the error.
+func (e Error) Is(target error) bool {
+	k, ok := target.(Kind)
+	return ok && e.kind != "" && k == e.kind
+}
+
 func (e Error) Error() string {
 	return e.String()
 }
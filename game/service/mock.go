@@ -0,0 +1,43 @@
+package service
+
+import (
+	"context"
+
+	gamedomain "github.com/vediagames/onlooker/domain/game"
+)
+
+type mock struct{}
+
+func NewMock() gamedomain.Service {
+	return &mock{}
+}
+
+func (m mock) Create(ctx context.Context, request gamedomain.CreateRequest) (gamedomain.CreateResponse, error) {
+	//TODO implement me
+	panic("implement me")
+}
+
+func (m mock) Get(ctx context.Context, request gamedomain.GetRequest) (gamedomain.GetResponse, error) {
+	//TODO implement me
+	panic("implement me")
+}
+
+func (m mock) List(ctx context.Context, request gamedomain.ListRequest) (gamedomain.ListResponse, error) {
+	//TODO implement me
+	panic("implement me")
+}
+
+func (m mock) RotateSigningSecret(ctx context.Context, request gamedomain.RotateSigningSecretRequest) (gamedomain.RotateSigningSecretResponse, error) {
+	//TODO implement me
+	panic("implement me")
+}
+
+func (m mock) DisableSigning(ctx context.Context, request gamedomain.DisableSigningRequest) error {
+	//TODO implement me
+	panic("implement me")
+}
+
+func (m mock) SetAllowedOrigins(ctx context.Context, request gamedomain.SetAllowedOriginsRequest) error {
+	//TODO implement me
+	panic("implement me")
+}
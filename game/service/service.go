@@ -0,0 +1,139 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+
+	domain "github.com/vediagames/onlooker/domain/game"
+	"github.com/vediagames/onlooker/errutil"
+)
+
+// signingSecretBytes is the number of random bytes of a signing secret.
+const signingSecretBytes = 32
+
+type service struct {
+	store domain.Store
+}
+
+type Config struct {
+	Store domain.Store
+}
+
+func (c Config) Validate() error {
+	var err errutil.Error
+
+	if c.Store == nil {
+		err.Add(fmt.Errorf("store is empty"))
+	}
+
+	return err.Err()
+}
+
+func New(cfg Config) (domain.Service, error) {
+	if ve := cfg.Validate(); ve != nil {
+		return nil, fmt.Errorf("invalid config: %w", ve)
+	}
+
+	return &service{
+		store: cfg.Store,
+	}, nil
+}
+
+func (s service) Create(ctx context.Context, req domain.CreateRequest) (domain.CreateResponse, error) {
+	if err := req.Validate(); err != nil {
+		return domain.CreateResponse{}, fmt.Errorf("invalid request: %w", errutil.WithKind(err, domain.ErrInvalidArgument))
+	}
+
+	insertRes, err := s.store.Insert(ctx, domain.InsertQuery(req))
+	if err != nil {
+		return domain.CreateResponse{}, fmt.Errorf("failed to insert: %w", err)
+	}
+
+	res := domain.CreateResponse(insertRes)
+
+	if err := res.Validate(); err != nil {
+		return domain.CreateResponse{}, fmt.Errorf("invalid response: %w", err)
+	}
+
+	return res, nil
+}
+
+func (s service) Get(ctx context.Context, req domain.GetRequest) (domain.GetResponse, error) {
+	if err := req.Validate(); err != nil {
+		return domain.GetResponse{}, fmt.Errorf("invalid request: %w", errutil.WithKind(err, domain.ErrInvalidArgument))
+	}
+
+	getRes, err := s.store.Get(ctx, domain.GetQuery(req))
+	if err != nil {
+		return domain.GetResponse{}, fmt.Errorf("failed to get: %w", err)
+	}
+
+	return domain.GetResponse(getRes), nil
+}
+
+func (s service) List(ctx context.Context, req domain.ListRequest) (domain.ListResponse, error) {
+	if err := req.Validate(); err != nil {
+		return domain.ListResponse{}, fmt.Errorf("invalid request: %w", errutil.WithKind(err, domain.ErrInvalidArgument))
+	}
+
+	listRes, err := s.store.List(ctx, domain.ListQuery(req))
+	if err != nil {
+		return domain.ListResponse{}, fmt.Errorf("failed to list: %w", err)
+	}
+
+	return domain.ListResponse(listRes), nil
+}
+
+func (s service) RotateSigningSecret(ctx context.Context, req domain.RotateSigningSecretRequest) (domain.RotateSigningSecretResponse, error) {
+	if err := req.Validate(); err != nil {
+		return domain.RotateSigningSecretResponse{}, fmt.Errorf("invalid request: %w", errutil.WithKind(err, domain.ErrInvalidArgument))
+	}
+
+	b := make([]byte, signingSecretBytes)
+	if _, err := rand.Read(b); err != nil {
+		return domain.RotateSigningSecretResponse{}, fmt.Errorf("failed to generate signing secret: %w", err)
+	}
+
+	secret := hex.EncodeToString(b)
+
+	err := s.store.UpdateSigningSecret(ctx, domain.UpdateSigningSecretQuery{
+		Slug:          req.Slug,
+		SigningSecret: secret,
+	})
+	if err != nil {
+		return domain.RotateSigningSecretResponse{}, fmt.Errorf("failed to update: %w", err)
+	}
+
+	return domain.RotateSigningSecretResponse{
+		SigningSecret: secret,
+	}, nil
+}
+
+func (s service) DisableSigning(ctx context.Context, req domain.DisableSigningRequest) error {
+	if err := req.Validate(); err != nil {
+		return fmt.Errorf("invalid request: %w", errutil.WithKind(err, domain.ErrInvalidArgument))
+	}
+
+	err := s.store.UpdateSigningSecret(ctx, domain.UpdateSigningSecretQuery{
+		Slug: req.Slug,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update: %w", err)
+	}
+
+	return nil
+}
+
+func (s service) SetAllowedOrigins(ctx context.Context, req domain.SetAllowedOriginsRequest) error {
+	if err := req.Validate(); err != nil {
+		return fmt.Errorf("invalid request: %w", errutil.WithKind(err, domain.ErrInvalidArgument))
+	}
+
+	if err := s.store.UpdateAllowedOrigins(ctx, domain.UpdateAllowedOriginsQuery(req)); err != nil {
+		return fmt.Errorf("failed to update: %w", err)
+	}
+
+	return nil
+}
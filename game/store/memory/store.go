@@ -0,0 +1,120 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	domain "github.com/vediagames/onlooker/domain/game"
+)
+
+type store struct {
+	mu    sync.RWMutex
+	games map[string]domain.Game
+}
+
+// New returns a store with the default game, like the games migration.
+func New() domain.Store {
+	return &store{
+		games: map[string]domain.Game{
+			domain.DefaultGame: {
+				Slug:      domain.DefaultGame,
+				Name:      "Default",
+				CreatedAt: time.Now().UTC(),
+			},
+		},
+	}
+}
+
+func (s *store) Insert(ctx context.Context, q domain.InsertQuery) (domain.InsertResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.games[q.Slug]; ok {
+		return domain.InsertResult{}, fmt.Errorf("game %q: %w", q.Slug, domain.ErrConflict)
+	}
+
+	game := domain.Game{
+		Slug:      q.Slug,
+		Name:      q.Name,
+		CreatedAt: time.Now().UTC(),
+	}
+
+	s.games[game.Slug] = game
+
+	return domain.InsertResult{
+		CreatedAt: game.CreatedAt,
+	}, nil
+}
+
+func (s *store) Get(ctx context.Context, q domain.GetQuery) (domain.GetResult, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	game, ok := s.games[q.Slug]
+	if !ok {
+		return domain.GetResult{}, fmt.Errorf("game %q: %w", q.Slug, domain.ErrNotFound)
+	}
+
+	return domain.GetResult{
+		Game: game,
+	}, nil
+}
+
+func (s *store) List(ctx context.Context, q domain.ListQuery) (domain.ListResult, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	games := make([]domain.Game, 0, len(s.games))
+	for _, g := range s.games {
+		games = append(games, g)
+	}
+
+	sort.Slice(games, func(i, j int) bool {
+		return games[i].Slug < games[j].Slug
+	})
+
+	return domain.ListResult{
+		Games: games,
+	}, nil
+}
+
+func (s *store) UpdateSigningSecret(ctx context.Context, q domain.UpdateSigningSecretQuery) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	game, ok := s.games[q.Slug]
+	if !ok {
+		return fmt.Errorf("game %q: %w", q.Slug, domain.ErrNotFound)
+	}
+
+	game.SigningSecret = q.SigningSecret
+	s.games[q.Slug] = game
+
+	return nil
+}
+
+func (s *store) UpdateAllowedOrigins(ctx context.Context, q domain.UpdateAllowedOriginsQuery) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	game, ok := s.games[q.Slug]
+	if !ok {
+		return fmt.Errorf("game %q: %w", q.Slug, domain.ErrNotFound)
+	}
+
+	game.AllowedOrigins = append([]string(nil), q.AllowedOrigins...)
+	s.games[q.Slug] = game
+
+	return nil
+}
+
+func (s *store) Ping(ctx context.Context) error {
+	return nil
+}
+
+func (s *store) Close() error {
+	return nil
+}
@@ -0,0 +1,48 @@
+package store
+
+import (
+	"context"
+
+	domain "github.com/vediagames/onlooker/domain/game"
+)
+
+type mock struct{}
+
+func NewMock() domain.Store {
+	return &mock{}
+}
+
+func (s mock) Insert(ctx context.Context, q domain.InsertQuery) (domain.InsertResult, error) {
+	//TODO implement me
+	panic("implement me")
+}
+
+func (s mock) Get(ctx context.Context, q domain.GetQuery) (domain.GetResult, error) {
+	//TODO implement me
+	panic("implement me")
+}
+
+func (s mock) List(ctx context.Context, q domain.ListQuery) (domain.ListResult, error) {
+	//TODO implement me
+	panic("implement me")
+}
+
+func (s mock) UpdateSigningSecret(ctx context.Context, q domain.UpdateSigningSecretQuery) error {
+	//TODO implement me
+	panic("implement me")
+}
+
+func (s mock) UpdateAllowedOrigins(ctx context.Context, q domain.UpdateAllowedOriginsQuery) error {
+	//TODO implement me
+	panic("implement me")
+}
+
+func (s mock) Ping(ctx context.Context) error {
+	//TODO implement me
+	panic("implement me")
+}
+
+func (s mock) Close() error {
+	//TODO implement me
+	panic("implement me")
+}
@@ -0,0 +1,175 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/vediagames/onlooker/database"
+	domain "github.com/vediagames/onlooker/domain/game"
+	"github.com/vediagames/onlooker/errutil"
+)
+
+type store struct {
+	db *sqlx.DB
+}
+
+type Config struct {
+	DB *sqlx.DB
+}
+
+func (c Config) Validate() error {
+	var err errutil.Error
+
+	if c.DB == nil {
+		err.Add(fmt.Errorf("db is empty"))
+	}
+
+	return err.Err()
+}
+
+func New(cfg Config) (domain.Store, error) {
+	if ve := cfg.Validate(); ve != nil {
+		return nil, fmt.Errorf("invalid config: %w", ve)
+	}
+
+	return &store{
+		db: cfg.DB,
+	}, nil
+}
+
+func (s store) Insert(ctx context.Context, q domain.InsertQuery) (domain.InsertResult, error) {
+	var createdAt time.Time
+
+	err := s.db.GetContext(ctx, &createdAt, `
+		INSERT INTO games (slug, name, created_at)
+		VALUES ($1, $2, now())
+		RETURNING created_at
+	`, q.Slug, q.Name)
+	if err != nil {
+		return domain.InsertResult{}, fmt.Errorf("failed to insert game: %w", database.TranslateError(err))
+	}
+
+	return domain.InsertResult{
+		CreatedAt: createdAt,
+	}, nil
+}
+
+type game struct {
+	Slug           string         `db:"slug"`
+	Name           string         `db:"name"`
+	CreatedAt      time.Time      `db:"created_at"`
+	SigningSecret  sql.NullString `db:"signing_secret"`
+	AllowedOrigins pq.StringArray `db:"allowed_origins"`
+}
+
+func (g game) toDomain() domain.Game {
+	return domain.Game{
+		Slug:           g.Slug,
+		Name:           g.Name,
+		CreatedAt:      g.CreatedAt,
+		SigningSecret:  g.SigningSecret.String,
+		AllowedOrigins: g.AllowedOrigins,
+	}
+}
+
+func (s store) Get(ctx context.Context, q domain.GetQuery) (domain.GetResult, error) {
+	var row game
+
+	err := s.db.GetContext(ctx, &row, `
+		SELECT slug, name, created_at, signing_secret, allowed_origins
+		FROM games
+		WHERE slug = $1
+	`, q.Slug)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.GetResult{}, fmt.Errorf("game %q: %w", q.Slug, domain.ErrNotFound)
+	}
+	if err != nil {
+		return domain.GetResult{}, fmt.Errorf("failed to get game: %w", database.TranslateError(err))
+	}
+
+	return domain.GetResult{
+		Game: row.toDomain(),
+	}, nil
+}
+
+func (s store) List(ctx context.Context, q domain.ListQuery) (domain.ListResult, error) {
+	var rows []game
+
+	err := s.db.SelectContext(ctx, &rows, `
+		SELECT slug, name, created_at, signing_secret, allowed_origins
+		FROM games
+		ORDER BY slug
+	`)
+	if err != nil {
+		return domain.ListResult{}, fmt.Errorf("failed to list games: %w", database.TranslateError(err))
+	}
+
+	games := make([]domain.Game, 0, len(rows))
+	for _, r := range rows {
+		games = append(games, r.toDomain())
+	}
+
+	return domain.ListResult{
+		Games: games,
+	}, nil
+}
+
+func (s store) UpdateSigningSecret(ctx context.Context, q domain.UpdateSigningSecretQuery) error {
+	var slug string
+
+	err := s.db.GetContext(ctx, &slug, `
+		UPDATE games
+		SET signing_secret = $2
+		WHERE slug = $1
+		RETURNING slug
+	`, q.Slug, database.NullString(q.SigningSecret))
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("game %q: %w", q.Slug, domain.ErrNotFound)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update signing secret: %w", database.TranslateError(err))
+	}
+
+	return nil
+}
+
+func (s store) UpdateAllowedOrigins(ctx context.Context, q domain.UpdateAllowedOriginsQuery) error {
+	var slug string
+
+	// A nil array is NULL, the column is not nullable.
+	origins := pq.StringArray{}
+	origins = append(origins, q.AllowedOrigins...)
+
+	err := s.db.GetContext(ctx, &slug, `
+		UPDATE games
+		SET allowed_origins = $2
+		WHERE slug = $1
+		RETURNING slug
+	`, q.Slug, origins)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("game %q: %w", q.Slug, domain.ErrNotFound)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update allowed origins: %w", database.TranslateError(err))
+	}
+
+	return nil
+}
+
+func (s store) Ping(ctx context.Context) error {
+	if err := s.db.PingContext(ctx); err != nil {
+		return fmt.Errorf("failed to ping database: %w", database.TranslateError(err))
+	}
+
+	return nil
+}
+
+// Close does nothing, the database is shared and closed by its owner.
+func (s store) Close() error {
+	return nil
+}
@@ -0,0 +1,190 @@
+package heatmap
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+)
+
+const (
+	// CellPixels is the size in pixels of cells drawn without a background.
+	CellPixels = 16
+	// MaxSize is the maximum width and height in pixels of a heatmap drawn without
+	// a background, the same as the largest background.
+	MaxSize = 2048
+)
+
+// ErrTooLarge is returned if the cells span more than MaxSize pixels.
+var ErrTooLarge = errors.New("heatmap is too large")
+
+// Cell counts the positions within the cell at X and Y of a grid.
+type Cell struct {
+	X     int
+	Y     int
+	Count int
+}
+
+type Options struct {
+	Cells []Cell
+	// CellSize is the size of cells in world coordinates.
+	CellSize float64
+	// Background is drawn under the cells if set, the heatmap then has its bounds.
+	Background image.Image
+	// OriginX and OriginY are the world coordinates of the top left pixel of the background.
+	OriginX float64
+	OriginY float64
+	// Scale is the size of a pixel of the background in world coordinates.
+	Scale float64
+}
+
+// Render draws the cells colored from blue for the fewest to red for the most
+// positions. Without a background the heatmap covers the cells with CellPixels
+// pixels per cell, fewer if it would exceed MaxSize.
+func Render(o Options) (image.Image, error) {
+	if o.Background != nil {
+		return renderOver(o), nil
+	}
+
+	if len(o.Cells) == 0 {
+		return image.NewNRGBA(image.Rect(0, 0, CellPixels, CellPixels)), nil
+	}
+
+	minX, minY := o.Cells[0].X, o.Cells[0].Y
+	maxX, maxY := minX, minY
+
+	for _, c := range o.Cells {
+		if c.X < minX {
+			minX = c.X
+		}
+		if c.X > maxX {
+			maxX = c.X
+		}
+		if c.Y < minY {
+			minY = c.Y
+		}
+		if c.Y > maxY {
+			maxY = c.Y
+		}
+	}
+
+	columns, rows := maxX-minX+1, maxY-minY+1
+	if columns > MaxSize || rows > MaxSize {
+		return nil, fmt.Errorf("%w: cells span %dx%d, increase the cell size", ErrTooLarge, columns, rows)
+	}
+
+	side := columns
+	if rows > side {
+		side = rows
+	}
+
+	pixels := CellPixels
+	if MaxSize/side < pixels {
+		pixels = MaxSize / side
+	}
+	img := image.NewNRGBA(image.Rect(0, 0, columns*pixels, rows*pixels))
+	maxCount := maxCount(o.Cells)
+
+	for _, c := range o.Cells {
+		x, y := (c.X-minX)*pixels, (c.Y-minY)*pixels
+		r := image.Rect(x, y, x+pixels, y+pixels)
+		draw.Draw(img, r, image.NewUniform(cellColor(c.Count, maxCount)), image.Point{}, draw.Over)
+	}
+
+	return img, nil
+}
+
+// renderOver draws the cells over the background, cells outside of it are cut off.
+func renderOver(o Options) image.Image {
+	bounds := o.Background.Bounds()
+
+	img := image.NewNRGBA(bounds)
+	draw.Draw(img, bounds, o.Background, bounds.Min, draw.Src)
+
+	maxCount := maxCount(o.Cells)
+
+	// pixel returns the pixel of the background at the world coordinate v.
+	pixel := func(v, origin float64, base int) int {
+		return base + int(math.Floor((v-origin)/o.Scale))
+	}
+
+	for _, c := range o.Cells {
+		r := image.Rect(
+			pixel(float64(c.X)*o.CellSize, o.OriginX, bounds.Min.X),
+			pixel(float64(c.Y)*o.CellSize, o.OriginY, bounds.Min.Y),
+			pixel(float64(c.X+1)*o.CellSize, o.OriginX, bounds.Min.X),
+			pixel(float64(c.Y+1)*o.CellSize, o.OriginY, bounds.Min.Y),
+		).Intersect(bounds)
+
+		// Cells smaller than a pixel are still drawn.
+		if r.Empty() {
+			p := image.Pt(
+				pixel(float64(c.X)*o.CellSize, o.OriginX, bounds.Min.X),
+				pixel(float64(c.Y)*o.CellSize, o.OriginY, bounds.Min.Y),
+			)
+			if !p.In(bounds) {
+				continue
+			}
+
+			r = image.Rectangle{Min: p, Max: p.Add(image.Pt(1, 1))}
+		}
+
+		draw.Draw(img, r, image.NewUniform(cellColor(c.Count, maxCount)), image.Point{}, draw.Over)
+	}
+
+	return img
+}
+
+func maxCount(cells []Cell) int {
+	var n int
+
+	for _, c := range cells {
+		if c.Count > n {
+			n = c.Count
+		}
+	}
+
+	return n
+}
+
+// gradient is the colors of counts from the fewest to the most.
+var gradient = []color.NRGBA{
+	{R: 0, G: 0, B: 255},
+	{R: 0, G: 255, B: 255},
+	{R: 0, G: 255, B: 0},
+	{R: 255, G: 255, B: 0},
+	{R: 255, G: 0, B: 0},
+}
+
+// cellColor returns the color of a count. Counts are scaled logarithmically so
+// that a few hot spots do not wash out the rest of the map, and more opaque the
+// higher they are.
+func cellColor(count, maxCount int) color.NRGBA {
+	t := 1.0
+	if maxCount > 1 {
+		t = math.Log1p(float64(count)) / math.Log1p(float64(maxCount))
+	}
+
+	pos := t * float64(len(gradient)-1)
+
+	i := int(pos)
+	if i > len(gradient)-2 {
+		i = len(gradient) - 2
+	}
+
+	f := pos - float64(i)
+
+	from, to := gradient[i], gradient[i+1]
+	lerp := func(a, b uint8) uint8 {
+		return uint8(math.Round(float64(a) + (float64(b)-float64(a))*f))
+	}
+
+	return color.NRGBA{
+		R: lerp(from.R, to.R),
+		G: lerp(from.G, to.G),
+		B: lerp(from.B, to.B),
+		A: uint8(math.Round(255 * (0.4 + 0.45*t))),
+	}
+}
@@ -0,0 +1,216 @@
+package service
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"sync"
+	"time"
+
+	domain "github.com/vediagames/onlooker/domain/level"
+	"github.com/vediagames/onlooker/errutil"
+	"github.com/vediagames/onlooker/heatmap"
+)
+
+func (s service) SetBackground(ctx context.Context, req domain.SetBackgroundRequest) (domain.SetBackgroundResponse, error) {
+	if err := req.Validate(); err != nil {
+		return domain.SetBackgroundResponse{}, fmt.Errorf("invalid request: %w", errutil.WithKind(err, domain.ErrInvalidArgument))
+	}
+
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(req.Image))
+	if err != nil {
+		return domain.SetBackgroundResponse{}, fmt.Errorf("invalid request: failed to decode image: %v: %w", err, domain.ErrInvalidArgument)
+	}
+
+	if cfg.Width > domain.MaxBackgroundSize || cfg.Height > domain.MaxBackgroundSize {
+		return domain.SetBackgroundResponse{}, fmt.Errorf(
+			"invalid request: image must be at most %dx%d pixels: %w",
+			domain.MaxBackgroundSize, domain.MaxBackgroundSize, domain.ErrInvalidArgument,
+		)
+	}
+
+	setRes, err := s.store.SetBackground(ctx, domain.SetBackgroundQuery{
+		Game:   req.Game,
+		Level:  req.Level,
+		Image:  req.Image,
+		Format: format,
+		Origin: req.Origin,
+		Scale:  req.Scale,
+	})
+	if err != nil {
+		return domain.SetBackgroundResponse{}, fmt.Errorf("failed to set background: %w", err)
+	}
+
+	return domain.SetBackgroundResponse{
+		Format:    format,
+		Width:     cfg.Width,
+		Height:    cfg.Height,
+		UpdatedAt: setRes.UpdatedAt,
+	}, nil
+}
+
+func (s service) Heatmap(ctx context.Context, req domain.HeatmapRequest) (domain.HeatmapResponse, error) {
+	if err := req.Validate(); err != nil {
+		return domain.HeatmapResponse{}, fmt.Errorf("invalid request: %w", errutil.WithKind(err, domain.ErrInvalidArgument))
+	}
+
+	gridRes, err := s.Grid(ctx, req.GridRequest())
+	if err != nil {
+		return domain.HeatmapResponse{}, err
+	}
+
+	opts := heatmap.Options{
+		Cells:    make([]heatmap.Cell, 0, len(gridRes.Cells)),
+		CellSize: req.CellSize,
+	}
+
+	for _, c := range gridRes.Cells {
+		opts.Cells = append(opts.Cells, heatmap.Cell(c))
+	}
+
+	// The background is fetched without its image first, which is only loaded
+	// if it changed since it was last decoded.
+	getRes, err := s.store.GetBackground(ctx, domain.GetBackgroundQuery{
+		Game:         req.Game,
+		Level:        req.Level,
+		WithoutImage: true,
+	})
+	switch {
+	case errors.Is(err, domain.ErrNotFound):
+		// Levels without a background are drawn on a transparent image.
+	case err != nil:
+		return domain.HeatmapResponse{}, fmt.Errorf("failed to get background: %w", err)
+	default:
+		background, img, err := s.decodeBackground(ctx, getRes.Background)
+		if err != nil {
+			return domain.HeatmapResponse{}, err
+		}
+
+		opts.Background = img
+		opts.OriginX = background.Origin.X
+		opts.OriginY = background.Origin.Y
+		opts.Scale = background.Scale
+	}
+
+	img, err := heatmap.Render(opts)
+	if errors.Is(err, heatmap.ErrTooLarge) {
+		return domain.HeatmapResponse{}, fmt.Errorf("invalid request: %w", errutil.WithKind(err, domain.ErrInvalidArgument))
+	}
+	if err != nil {
+		return domain.HeatmapResponse{}, fmt.Errorf("failed to render heatmap: %w", err)
+	}
+
+	return domain.HeatmapResponse{
+		Image: img,
+	}, nil
+}
+
+// decodeBackground returns the decoded image of b, which is fetched without its
+// image. The image is loaded and decoded unless it is cached since the last update
+// of b, along with the background it was loaded with.
+func (s service) decodeBackground(ctx context.Context, b domain.Background) (domain.Background, image.Image, error) {
+	if img, ok := s.backgrounds.get(b); ok {
+		return b, img, nil
+	}
+
+	getRes, err := s.store.GetBackground(ctx, domain.GetBackgroundQuery{
+		Game:  b.Game,
+		Level: b.Level,
+	})
+	if err != nil {
+		return domain.Background{}, nil, fmt.Errorf("failed to get background: %w", err)
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(getRes.Background.Image))
+	if err != nil {
+		return domain.Background{}, nil, fmt.Errorf("failed to decode background: %w", err)
+	}
+
+	s.backgrounds.add(getRes.Background, img)
+
+	return getRes.Background, img, nil
+}
+
+// backgroundCacheSize is the maximum number of decoded backgrounds kept in memory.
+const backgroundCacheSize = 8
+
+// backgroundCache keeps the most recently used decoded backgrounds, so that the
+// heatmaps of a level do not load and decode its background again until it is
+// updated.
+type backgroundCache struct {
+	mu sync.Mutex
+	// order holds the cached backgrounds from the most to the least recently used.
+	order    *list.List
+	elements map[backgroundKey]*list.Element
+}
+
+type backgroundKey struct {
+	game  string
+	level int
+}
+
+type decodedBackground struct {
+	key       backgroundKey
+	updatedAt time.Time
+	image     image.Image
+}
+
+func newBackgroundCache() *backgroundCache {
+	return &backgroundCache{
+		order:    list.New(),
+		elements: make(map[backgroundKey]*list.Element),
+	}
+}
+
+// get returns the decoded image of b if it was cached since its last update.
+func (c *backgroundCache) get(b domain.Background) (image.Image, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.elements[backgroundKey{game: b.Game, level: b.Level}]
+	if !ok {
+		return nil, false
+	}
+
+	cached := e.Value.(decodedBackground)
+	if !cached.updatedAt.Equal(b.UpdatedAt) {
+		return nil, false
+	}
+
+	c.order.MoveToFront(e)
+
+	return cached.image, true
+}
+
+// add caches the decoded image of b and evicts the least recently used
+// background once the cache is full.
+func (c *backgroundCache) add(b domain.Background, img image.Image) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	cached := decodedBackground{
+		key:       backgroundKey{game: b.Game, level: b.Level},
+		updatedAt: b.UpdatedAt,
+		image:     img,
+	}
+
+	if e, ok := c.elements[cached.key]; ok {
+		e.Value = cached
+		c.order.MoveToFront(e)
+		return
+	}
+
+	c.elements[cached.key] = c.order.PushFront(cached)
+
+	if c.order.Len() > backgroundCacheSize {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.elements, oldest.Value.(decodedBackground).key)
+	}
+}
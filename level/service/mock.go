@@ -31,3 +31,48 @@ func (m mock) LogGrapplingHookUsage(ctx context.Context, request leveldomain.Log
 	//TODO implement me
 	panic("implement me")
 }
+
+func (m mock) LogEvent(ctx context.Context, request leveldomain.LogEventRequest) (leveldomain.LogEventResponse, error) {
+	//TODO implement me
+	panic("implement me")
+}
+
+func (m mock) Get(ctx context.Context, request leveldomain.GetRequest) (leveldomain.GetResponse, error) {
+	//TODO implement me
+	panic("implement me")
+}
+
+func (m mock) List(ctx context.Context, request leveldomain.ListRequest) (leveldomain.ListResponse, error) {
+	//TODO implement me
+	panic("implement me")
+}
+
+func (m mock) ListEvents(ctx context.Context, request leveldomain.ListEventsRequest) (leveldomain.ListEventsResponse, error) {
+	//TODO implement me
+	panic("implement me")
+}
+
+func (m mock) LogEvents(ctx context.Context, request leveldomain.LogEventsRequest) (leveldomain.LogEventsResponse, error) {
+	//TODO implement me
+	panic("implement me")
+}
+
+func (m mock) Grid(ctx context.Context, request leveldomain.GridRequest) (leveldomain.GridResponse, error) {
+	//TODO implement me
+	panic("implement me")
+}
+
+func (m mock) SetBackground(ctx context.Context, request leveldomain.SetBackgroundRequest) (leveldomain.SetBackgroundResponse, error) {
+	//TODO implement me
+	panic("implement me")
+}
+
+func (m mock) Heatmap(ctx context.Context, request leveldomain.HeatmapRequest) (leveldomain.HeatmapResponse, error) {
+	//TODO implement me
+	panic("implement me")
+}
+
+func (m mock) Ping(ctx context.Context) error {
+	//TODO implement me
+	panic("implement me")
+}
@@ -6,14 +6,21 @@ import (
 
 	domain "github.com/vediagames/onlooker/domain/level"
 	"github.com/vediagames/onlooker/errutil"
+	"github.com/vediagames/onlooker/metadata"
+	"github.com/vediagames/onlooker/pagination"
 )
 
 type service struct {
-	store domain.Store
+	store          domain.Store
+	registry       *domain.Registry
+	metadataLimits metadata.Limits
+	backgrounds    *backgroundCache
 }
 
 type Config struct {
-	Store domain.Store
+	Store          domain.Store
+	Registry       *domain.Registry
+	MetadataLimits metadata.Limits
 }
 
 func (c Config) Validate() error {
@@ -23,6 +30,14 @@ func (c Config) Validate() error {
 		err.Add(fmt.Errorf("store is empty"))
 	}
 
+	if c.Registry == nil {
+		err.Add(fmt.Errorf("registry is empty"))
+	}
+
+	if ve := c.MetadataLimits.Validate(); ve != nil {
+		err.Add(fmt.Errorf("invalid metadata limits: %w", ve))
+	}
+
 	return err.Err()
 }
 
@@ -32,13 +47,20 @@ func New(cfg Config) (domain.Service, error) {
 	}
 
 	return &service{
-		store: cfg.Store,
+		store:          cfg.Store,
+		registry:       cfg.Registry,
+		metadataLimits: cfg.MetadataLimits,
+		backgrounds:    newBackgroundCache(),
 	}, nil
 }
 
 func (s service) Create(ctx context.Context, req domain.CreateRequest) (domain.CreateResponse, error) {
 	if err := req.Validate(); err != nil {
-		return domain.CreateResponse{}, fmt.Errorf("invalid request: %w", err)
+		return domain.CreateResponse{}, fmt.Errorf("invalid request: %w", errutil.WithKind(err, domain.ErrInvalidArgument))
+	}
+
+	if err := s.metadataLimits.Check(req.Metadata); err != nil {
+		return domain.CreateResponse{}, fmt.Errorf("invalid request: %w", errutil.WithKind(err, domain.ErrInvalidArgument))
 	}
 
 	newRes, err := s.store.Insert(ctx, domain.InsertQuery(req))
@@ -56,15 +78,12 @@ func (s service) Create(ctx context.Context, req domain.CreateRequest) (domain.C
 }
 
 func (s service) LogDeath(ctx context.Context, req domain.LogDeathRequest) (domain.LogDeathResponse, error) {
-	if err := req.Validate(); err != nil {
-		return domain.LogDeathResponse{}, fmt.Errorf("invalid request: %w", err)
+	q, err := s.insertEventQuery(req)
+	if err != nil {
+		return domain.LogDeathResponse{}, err
 	}
 
-	insertRes, err := s.store.InsertEvent(ctx, domain.InsertEventQuery{
-		UUID:       req.UUID,
-		Event:      domain.EventDeath,
-		ClientTime: req.ClientTime,
-	})
+	insertRes, err := s.store.InsertEvent(ctx, q)
 	if err != nil {
 		return domain.LogDeathResponse{}, fmt.Errorf("failed to insert event: %w", err)
 	}
@@ -79,19 +98,12 @@ func (s service) LogDeath(ctx context.Context, req domain.LogDeathRequest) (doma
 }
 
 func (s service) LogComplete(ctx context.Context, req domain.LogCompleteRequest) (domain.LogCompleteResponse, error) {
-	if err := req.Validate(); err != nil {
-		return domain.LogCompleteResponse{}, fmt.Errorf("invalid request: %w", err)
+	q, err := s.insertEventQuery(req)
+	if err != nil {
+		return domain.LogCompleteResponse{}, err
 	}
 
-	insertRes, err := s.store.InsertEvent(ctx, domain.InsertEventQuery{
-		UUID:       req.UUID,
-		Event:      domain.EventComplete,
-		ClientTime: req.ClientTime,
-		Metadata: map[string]interface{}{
-			"achievement":     req.Achievement,
-			"completion_time": req.CompletionTime,
-		},
-	})
+	insertRes, err := s.store.InsertEvent(ctx, q)
 	if err != nil {
 		return domain.LogCompleteResponse{}, fmt.Errorf("failed to insert event: %w", err)
 	}
@@ -106,15 +118,12 @@ func (s service) LogComplete(ctx context.Context, req domain.LogCompleteRequest)
 }
 
 func (s service) LogGrapplingHookUsage(ctx context.Context, req domain.LogGrapplingHookUsageRequest) (domain.LogGrapplingHookUsageResponse, error) {
-	if err := req.Validate(); err != nil {
-		return domain.LogGrapplingHookUsageResponse{}, fmt.Errorf("invalid request: %w", err)
+	q, err := s.insertEventQuery(req)
+	if err != nil {
+		return domain.LogGrapplingHookUsageResponse{}, err
 	}
 
-	insertRes, err := s.store.InsertEvent(ctx, domain.InsertEventQuery{
-		UUID:       req.UUID,
-		Event:      domain.EventGrapplingHookUsage,
-		ClientTime: req.ClientTime,
-	})
+	insertRes, err := s.store.InsertEvent(ctx, q)
 	if err != nil {
 		return domain.LogGrapplingHookUsageResponse{}, fmt.Errorf("failed to insert event: %w", err)
 	}
@@ -127,3 +136,216 @@ func (s service) LogGrapplingHookUsage(ctx context.Context, req domain.LogGrappl
 
 	return res, nil
 }
+
+func (s service) LogEvent(ctx context.Context, req domain.LogEventRequest) (domain.LogEventResponse, error) {
+	q, err := s.insertEventQuery(req)
+	if err != nil {
+		return domain.LogEventResponse{}, err
+	}
+
+	insertRes, err := s.store.InsertEvent(ctx, q)
+	if err != nil {
+		return domain.LogEventResponse{}, fmt.Errorf("failed to insert event: %w", err)
+	}
+
+	res := domain.LogEventResponse(insertRes)
+
+	if err := res.Validate(); err != nil {
+		return domain.LogEventResponse{}, fmt.Errorf("invalid response: %w", err)
+	}
+
+	return res, nil
+}
+
+func (s service) LogEvents(ctx context.Context, req domain.LogEventsRequest) (domain.LogEventsResponse, error) {
+	if err := req.Validate(); err != nil {
+		return domain.LogEventsResponse{}, fmt.Errorf("invalid request: %w", errutil.WithKind(err, domain.ErrInvalidArgument))
+	}
+
+	res := domain.LogEventsResponse{
+		Results: make([]domain.LogEventsResult, len(req.Requests)),
+	}
+
+	queries := make([]domain.InsertEventQuery, 0, len(req.Requests))
+	indexes := make([]int, 0, len(req.Requests))
+
+	for i, r := range req.Requests {
+		q, err := s.insertEventQuery(r)
+		if err != nil {
+			if req.Atomic {
+				return domain.LogEventsResponse{}, fmt.Errorf("request %d: %w", i, err)
+			}
+
+			res.Results[i].Err = err
+			continue
+		}
+
+		queries = append(queries, q)
+		indexes = append(indexes, i)
+	}
+
+	if len(queries) == 0 {
+		return res, nil
+	}
+
+	insertRes, err := s.store.InsertEvents(ctx, domain.InsertEventsQuery{
+		Events: queries,
+		Atomic: req.Atomic,
+	})
+	if err != nil {
+		return domain.LogEventsResponse{}, fmt.Errorf("failed to insert events: %w", err)
+	}
+
+	if len(insertRes.Results) != len(queries) {
+		return domain.LogEventsResponse{}, fmt.Errorf("invalid response: got %d results for %d events", len(insertRes.Results), len(queries))
+	}
+
+	for j, r := range insertRes.Results {
+		res.Results[indexes[j]] = domain.LogEventsResult(r)
+	}
+
+	return res, nil
+}
+
+// outcomes maps the events which end an attempt to its outcome.
+var outcomes = map[domain.Event]domain.Outcome{
+	domain.EventComplete: domain.OutcomeComplete,
+	domain.EventRestart:  domain.OutcomeRestart,
+	domain.EventAbandon:  domain.OutcomeAbandon,
+}
+
+// insertEventQuery validates req and creates the query to insert its event.
+func (s service) insertEventQuery(req domain.EventRequest) (domain.InsertEventQuery, error) {
+	if err := req.Validate(); err != nil {
+		return domain.InsertEventQuery{}, fmt.Errorf("invalid request: %w", errutil.WithKind(err, domain.ErrInvalidArgument))
+	}
+
+	eventReq := req.LogEventRequest()
+
+	if err := eventReq.Validate(); err != nil {
+		return domain.InsertEventQuery{}, fmt.Errorf("invalid request: %w", errutil.WithKind(err, domain.ErrInvalidArgument))
+	}
+
+	def, ok := s.registry.Get(eventReq.Event)
+	if !ok {
+		return domain.InsertEventQuery{}, fmt.Errorf("event %q: %w", eventReq.Event, domain.ErrNotFound)
+	}
+
+	if err := def.ValidateMetadata(eventReq.Metadata); err != nil {
+		return domain.InsertEventQuery{}, fmt.Errorf("invalid request: %w", errutil.WithKind(err, domain.ErrInvalidArgument))
+	}
+
+	if eventReq.Position != nil && !def.Position {
+		return domain.InsertEventQuery{}, fmt.Errorf("invalid request: event %q has no position: %w", eventReq.Event, domain.ErrInvalidArgument)
+	}
+
+	if err := s.metadataLimits.Check(eventReq.Metadata); err != nil {
+		return domain.InsertEventQuery{}, fmt.Errorf("invalid request: %w", errutil.WithKind(err, domain.ErrInvalidArgument))
+	}
+
+	return domain.InsertEventQuery{
+		UUID:           eventReq.UUID,
+		Event:          eventReq.Event,
+		ClientTime:     eventReq.ClientTime,
+		Metadata:       eventReq.Metadata,
+		IdempotencyKey: eventReq.IdempotencyKey,
+		Game:           eventReq.Game,
+		Position:       eventReq.Position,
+		Cause:          eventReq.Cause,
+		Outcome:        outcomes[eventReq.Event],
+	}, nil
+}
+
+func (s service) Get(ctx context.Context, req domain.GetRequest) (domain.GetResponse, error) {
+	if err := req.Validate(); err != nil {
+		return domain.GetResponse{}, fmt.Errorf("invalid request: %w", errutil.WithKind(err, domain.ErrInvalidArgument))
+	}
+
+	getRes, err := s.store.Get(ctx, domain.GetQuery(req))
+	if err != nil {
+		return domain.GetResponse{}, fmt.Errorf("failed to get: %w", err)
+	}
+
+	res := domain.GetResponse(getRes)
+
+	if err := res.Validate(); err != nil {
+		return domain.GetResponse{}, fmt.Errorf("invalid response: %w", err)
+	}
+
+	return res, nil
+}
+
+func (s service) List(ctx context.Context, req domain.ListRequest) (domain.ListResponse, error) {
+	if err := req.Validate(); err != nil {
+		return domain.ListResponse{}, fmt.Errorf("invalid request: %w", errutil.WithKind(err, domain.ErrInvalidArgument))
+	}
+
+	listRes, err := s.store.List(ctx, domain.ListQuery{
+		SessionUUID: req.SessionUUID,
+		Cursor:      req.Cursor,
+		Limit:       pagination.Limit(req.Limit),
+		Game:        req.Game,
+	})
+	if err != nil {
+		return domain.ListResponse{}, fmt.Errorf("failed to list: %w", err)
+	}
+
+	return domain.ListResponse(listRes), nil
+}
+
+func (s service) ListEvents(ctx context.Context, req domain.ListEventsRequest) (domain.ListEventsResponse, error) {
+	if err := req.Validate(); err != nil {
+		return domain.ListEventsResponse{}, fmt.Errorf("invalid request: %w", errutil.WithKind(err, domain.ErrInvalidArgument))
+	}
+
+	for _, e := range req.Events {
+		if _, ok := s.registry.Get(e); !ok {
+			return domain.ListEventsResponse{}, fmt.Errorf("invalid request: unknown event %q: %w", e, domain.ErrInvalidArgument)
+		}
+	}
+
+	listRes, err := s.store.ListEvents(ctx, domain.ListEventsQuery{
+		LevelUUID: req.LevelUUID,
+		Events:    req.Events,
+		From:      req.From,
+		To:        req.To,
+		Cursor:    req.Cursor,
+		Limit:     pagination.Limit(req.Limit),
+		Game:      req.Game,
+	})
+	if err != nil {
+		return domain.ListEventsResponse{}, fmt.Errorf("failed to list events: %w", err)
+	}
+
+	return domain.ListEventsResponse(listRes), nil
+}
+
+func (s service) Grid(ctx context.Context, req domain.GridRequest) (domain.GridResponse, error) {
+	if err := req.Validate(); err != nil {
+		return domain.GridResponse{}, fmt.Errorf("invalid request: %w", errutil.WithKind(err, domain.ErrInvalidArgument))
+	}
+
+	def, ok := s.registry.Get(req.Event)
+	if !ok {
+		return domain.GridResponse{}, fmt.Errorf("invalid request: unknown event %q: %w", req.Event, domain.ErrInvalidArgument)
+	}
+
+	if !def.Position {
+		return domain.GridResponse{}, fmt.Errorf("invalid request: event %q has no position: %w", req.Event, domain.ErrInvalidArgument)
+	}
+
+	gridRes, err := s.store.Grid(ctx, domain.GridQuery(req))
+	if err != nil {
+		return domain.GridResponse{}, fmt.Errorf("failed to get grid: %w", err)
+	}
+
+	return domain.GridResponse(gridRes), nil
+}
+
+func (s service) Ping(ctx context.Context) error {
+	if err := s.store.Ping(ctx); err != nil {
+		return fmt.Errorf("failed to ping store: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,225 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	gamedomain "github.com/vediagames/onlooker/domain/game"
+	domain "github.com/vediagames/onlooker/domain/level"
+	sessiondomain "github.com/vediagames/onlooker/domain/session"
+	gamememory "github.com/vediagames/onlooker/game/store/memory"
+	"github.com/vediagames/onlooker/level/store/memory"
+	"github.com/vediagames/onlooker/metadata"
+	playermemory "github.com/vediagames/onlooker/player/store/memory"
+	sessionmemory "github.com/vediagames/onlooker/session/store/memory"
+)
+
+func TestService_Create(t *testing.T) {
+	ctx := context.Background()
+	s, sessionStore := newService(t)
+	sessionUUID := insertSession(t, sessionStore)
+
+	tests := []struct {
+		name    string
+		req     domain.CreateRequest
+		wantErr error
+	}{
+		{
+			name: "valid",
+			req:  domain.CreateRequest{SessionUUID: sessionUUID, Level: 1, ClientTime: time.Now()},
+		},
+		{
+			name:    "missing client time",
+			req:     domain.CreateRequest{SessionUUID: sessionUUID, Level: 1},
+			wantErr: domain.ErrInvalidArgument,
+		},
+		{
+			name:    "unknown session",
+			req:     domain.CreateRequest{SessionUUID: "4a7d1ed4-1f3b-4ac7-9d7c-5f9b1b0f3c11", Level: 1, ClientTime: time.Now()},
+			wantErr: domain.ErrNotFound,
+		},
+		{
+			name:    "session of other game",
+			req:     domain.CreateRequest{SessionUUID: sessionUUID, Level: 1, ClientTime: time.Now(), Game: "other"},
+			wantErr: domain.ErrNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := s.Create(ctx, tt.req)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("got %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestService_Create_Idempotency(t *testing.T) {
+	ctx := context.Background()
+	s, sessionStore := newService(t)
+
+	req := domain.CreateRequest{
+		SessionUUID:    insertSession(t, sessionStore),
+		Level:          1,
+		ClientTime:     time.Now(),
+		IdempotencyKey: "key",
+	}
+
+	first, err := s.Create(ctx, req)
+	if err != nil {
+		t.Fatalf("failed to create level: %v", err)
+	}
+
+	replay, err := s.Create(ctx, req)
+	if err != nil {
+		t.Fatalf("failed to replay level: %v", err)
+	}
+
+	if replay.UUID != first.UUID {
+		t.Fatalf("got level %q on replay, want %q", replay.UUID, first.UUID)
+	}
+
+	// Idempotency keys are unique per session.
+	req.SessionUUID = insertSession(t, sessionStore)
+
+	other, err := s.Create(ctx, req)
+	if err != nil {
+		t.Fatalf("failed to create level of other session: %v", err)
+	}
+
+	if other.UUID == first.UUID {
+		t.Fatalf("got level %q of other session, want a new level", other.UUID)
+	}
+}
+
+func TestService_EndedAttempt(t *testing.T) {
+	ctx := context.Background()
+	s, sessionStore := newService(t)
+
+	createRes, err := s.Create(ctx, domain.CreateRequest{
+		SessionUUID: insertSession(t, sessionStore),
+		Level:       1,
+		ClientTime:  time.Now(),
+	})
+	if err != nil {
+		t.Fatalf("failed to create level: %v", err)
+	}
+
+	if _, err := s.LogDeath(ctx, domain.LogDeathRequest{UUID: createRes.UUID, ClientTime: time.Now()}); err != nil {
+		t.Fatalf("failed to log death: %v", err)
+	}
+
+	complete := domain.LogCompleteRequest{
+		UUID:           createRes.UUID,
+		ClientTime:     time.Now(),
+		Achievement:    domain.AchievementThreeStars,
+		CompletionTime: time.Minute,
+		IdempotencyKey: "key",
+	}
+
+	completeRes, err := s.LogComplete(ctx, complete)
+	if err != nil {
+		t.Fatalf("failed to log complete: %v", err)
+	}
+
+	// A replay of the event which ended the attempt returns the event.
+	replay, err := s.LogComplete(ctx, complete)
+	if err != nil {
+		t.Fatalf("failed to replay complete: %v", err)
+	}
+
+	if replay.UUID != completeRes.UUID {
+		t.Fatalf("got event %q on replay, want %q", replay.UUID, completeRes.UUID)
+	}
+
+	if _, err := s.LogDeath(ctx, domain.LogDeathRequest{UUID: createRes.UUID, ClientTime: time.Now()}); !errors.Is(err, domain.ErrConflict) {
+		t.Fatalf("death after complete: got %v, want %v", err, domain.ErrConflict)
+	}
+
+	getRes, err := s.Get(ctx, domain.GetRequest{UUID: createRes.UUID})
+	if err != nil {
+		t.Fatalf("failed to get level: %v", err)
+	}
+
+	if !getRes.Level.Ended() || getRes.Level.Outcome != domain.OutcomeComplete {
+		t.Fatalf("got level ended %t with outcome %q, want ended with %q", getRes.Level.Ended(), getRes.Level.Outcome, domain.OutcomeComplete)
+	}
+}
+
+func TestService_EndedSession(t *testing.T) {
+	ctx := context.Background()
+	s, sessionStore := newService(t)
+	sessionUUID := insertSession(t, sessionStore)
+
+	createRes, err := s.Create(ctx, domain.CreateRequest{
+		SessionUUID: sessionUUID,
+		Level:       1,
+		ClientTime:  time.Now(),
+	})
+	if err != nil {
+		t.Fatalf("failed to create level: %v", err)
+	}
+
+	if _, err := sessionStore.End(ctx, sessiondomain.EndQuery{UUID: sessionUUID}); err != nil {
+		t.Fatalf("failed to end session: %v", err)
+	}
+
+	if _, err := s.LogDeath(ctx, domain.LogDeathRequest{UUID: createRes.UUID, ClientTime: time.Now()}); !errors.Is(err, domain.ErrConflict) {
+		t.Fatalf("death after end of session: got %v, want %v", err, domain.ErrConflict)
+	}
+}
+
+func newService(t *testing.T) (domain.Service, sessiondomain.Store) {
+	t.Helper()
+
+	sessionStore, err := sessionmemory.New(sessionmemory.Config{
+		GameStore:   gamememory.New(),
+		PlayerStore: playermemory.New(),
+	})
+	if err != nil {
+		t.Fatalf("failed to create session store: %v", err)
+	}
+
+	store, err := memory.New(memory.Config{
+		SessionStore: sessionStore,
+	})
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+
+	registry, err := domain.NewRegistry()
+	if err != nil {
+		t.Fatalf("failed to create registry: %v", err)
+	}
+
+	s, err := New(Config{
+		Store:          store,
+		Registry:       registry,
+		MetadataLimits: metadata.Limits{MaxSize: 1024, MaxDepth: 4},
+	})
+	if err != nil {
+		t.Fatalf("failed to create service: %v", err)
+	}
+
+	return s, sessionStore
+}
+
+func insertSession(t *testing.T, sessionStore sessiondomain.Store) string {
+	t.Helper()
+
+	res, err := sessionStore.Insert(context.Background(), sessiondomain.InsertQuery{
+		Game:       gamedomain.DefaultGame,
+		ClientTime: time.Now(),
+		IP:         "127.0.0.1",
+		URL:        "https://example.com/game",
+		Timezone:   "UTC",
+	})
+	if err != nil {
+		t.Fatalf("failed to insert session: %v", err)
+	}
+
+	return res.UUID
+}
@@ -0,0 +1,162 @@
+package instrumented
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	domain "github.com/vediagames/onlooker/domain/level"
+	"github.com/vediagames/onlooker/errutil"
+	"github.com/vediagames/onlooker/metrics"
+)
+
+const storeName = "level"
+
+// store records the latency and errors of the queries of the wrapped store,
+// and counts the stored events.
+type store struct {
+	store   domain.Store
+	metrics *metrics.Metrics
+}
+
+type Config struct {
+	Store   domain.Store
+	Metrics *metrics.Metrics
+}
+
+func (c Config) Validate() error {
+	var err errutil.Error
+
+	if c.Store == nil {
+		err.Add(fmt.Errorf("store is empty"))
+	}
+
+	if c.Metrics == nil {
+		err.Add(fmt.Errorf("metrics is empty"))
+	}
+
+	return err.Err()
+}
+
+func New(cfg Config) (domain.Store, error) {
+	if ve := cfg.Validate(); ve != nil {
+		return nil, fmt.Errorf("invalid config: %w", ve)
+	}
+
+	return &store{
+		store:   cfg.Store,
+		metrics: cfg.Metrics,
+	}, nil
+}
+
+func (s store) Insert(ctx context.Context, q domain.InsertQuery) (domain.InsertResult, error) {
+	start := time.Now()
+
+	res, err := s.store.Insert(ctx, q)
+	s.metrics.ObserveStoreQuery(storeName, "insert", start, err)
+
+	return res, err
+}
+
+func (s store) InsertEvent(ctx context.Context, q domain.InsertEventQuery) (domain.InsertEventResult, error) {
+	start := time.Now()
+
+	res, err := s.store.InsertEvent(ctx, q)
+	s.metrics.ObserveStoreQuery(storeName, "insert_event", start, err)
+
+	if err == nil {
+		s.metrics.AddEventsIngested(string(q.Event), 1)
+	}
+
+	return res, err
+}
+
+func (s store) InsertEvents(ctx context.Context, q domain.InsertEventsQuery) (domain.InsertEventsResult, error) {
+	start := time.Now()
+
+	res, err := s.store.InsertEvents(ctx, q)
+	s.metrics.ObserveStoreQuery(storeName, "insert_events", start, err)
+
+	if err == nil && len(res.Results) == len(q.Events) {
+		counts := make(map[domain.Event]int)
+
+		for i, r := range res.Results {
+			if r.Err == nil {
+				counts[q.Events[i].Event]++
+			}
+		}
+
+		for event, count := range counts {
+			s.metrics.AddEventsIngested(string(event), count)
+		}
+	}
+
+	return res, err
+}
+
+func (s store) Get(ctx context.Context, q domain.GetQuery) (domain.GetResult, error) {
+	start := time.Now()
+
+	res, err := s.store.Get(ctx, q)
+	s.metrics.ObserveStoreQuery(storeName, "get", start, err)
+
+	return res, err
+}
+
+func (s store) List(ctx context.Context, q domain.ListQuery) (domain.ListResult, error) {
+	start := time.Now()
+
+	res, err := s.store.List(ctx, q)
+	s.metrics.ObserveStoreQuery(storeName, "list", start, err)
+
+	return res, err
+}
+
+func (s store) ListEvents(ctx context.Context, q domain.ListEventsQuery) (domain.ListEventsResult, error) {
+	start := time.Now()
+
+	res, err := s.store.ListEvents(ctx, q)
+	s.metrics.ObserveStoreQuery(storeName, "list_events", start, err)
+
+	return res, err
+}
+
+func (s store) Grid(ctx context.Context, q domain.GridQuery) (domain.GridResult, error) {
+	start := time.Now()
+
+	res, err := s.store.Grid(ctx, q)
+	s.metrics.ObserveStoreQuery(storeName, "grid", start, err)
+
+	return res, err
+}
+
+func (s store) SetBackground(ctx context.Context, q domain.SetBackgroundQuery) (domain.SetBackgroundResult, error) {
+	start := time.Now()
+
+	res, err := s.store.SetBackground(ctx, q)
+	s.metrics.ObserveStoreQuery(storeName, "set_background", start, err)
+
+	return res, err
+}
+
+func (s store) GetBackground(ctx context.Context, q domain.GetBackgroundQuery) (domain.GetBackgroundResult, error) {
+	start := time.Now()
+
+	res, err := s.store.GetBackground(ctx, q)
+	s.metrics.ObserveStoreQuery(storeName, "get_background", start, err)
+
+	return res, err
+}
+
+func (s store) Ping(ctx context.Context) error {
+	start := time.Now()
+
+	err := s.store.Ping(ctx)
+	s.metrics.ObserveStoreQuery(storeName, "ping", start, err)
+
+	return err
+}
+
+func (s store) Close() error {
+	return s.store.Close()
+}
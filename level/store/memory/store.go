@@ -0,0 +1,555 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	domain "github.com/vediagames/onlooker/domain/level"
+	sessiondomain "github.com/vediagames/onlooker/domain/session"
+	"github.com/vediagames/onlooker/errutil"
+	"github.com/vediagames/onlooker/pagination"
+)
+
+type store struct {
+	sessionStore sessiondomain.Store
+
+	mu     sync.RWMutex
+	levels map[string]domain.Level
+	// games maps level UUIDs to the game of their session.
+	games  map[string]string
+	events []domain.LevelEvent
+	// levelKeys and eventKeys map idempotency keys to inserted objects. Keys
+	// are unique per session for levels and per level and event for events.
+	levelKeys map[string]domain.InsertResult
+	eventKeys map[string]domain.InsertEventResult
+	// backgrounds maps levels of games to their background.
+	backgrounds map[backgroundKey]domain.Background
+}
+
+type backgroundKey struct {
+	game  string
+	level int
+}
+
+type Config struct {
+	// SessionStore is used to check that the session of a level exists.
+	SessionStore sessiondomain.Store
+}
+
+func (c Config) Validate() error {
+	var err errutil.Error
+
+	if c.SessionStore == nil {
+		err.Add(fmt.Errorf("session store is empty"))
+	}
+
+	return err.Err()
+}
+
+func New(cfg Config) (domain.Store, error) {
+	if ve := cfg.Validate(); ve != nil {
+		return nil, fmt.Errorf("invalid config: %w", ve)
+	}
+
+	return &store{
+		sessionStore: cfg.SessionStore,
+		levels:       make(map[string]domain.Level),
+		games:        make(map[string]string),
+		levelKeys:    make(map[string]domain.InsertResult),
+		eventKeys:    make(map[string]domain.InsertEventResult),
+		backgrounds:  make(map[backgroundKey]domain.Background),
+	}, nil
+}
+
+func (s *store) Insert(ctx context.Context, q domain.InsertQuery) (domain.InsertResult, error) {
+	sessionRes, err := s.sessionStore.Get(ctx, sessiondomain.GetQuery{
+		UUID: q.SessionUUID,
+		Game: q.Game,
+	})
+	if errors.Is(err, sessiondomain.ErrNotFound) {
+		return domain.InsertResult{}, fmt.Errorf("failed to insert level: session %q: %w", q.SessionUUID, domain.ErrNotFound)
+	}
+	if err != nil {
+		return domain.InsertResult{}, fmt.Errorf("failed to get session: %w", err)
+	}
+
+	level := domain.Level{
+		UUID:        uuid.NewString(),
+		SessionUUID: q.SessionUUID,
+		Level:       q.Level,
+		ClientTime:  q.ClientTime,
+		ServerTime:  time.Now().UTC(),
+		Metadata:    copyMetadata(q.Metadata),
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := q.SessionUUID + "/" + q.IdempotencyKey
+
+	if res, ok := s.levelKeys[key]; ok && q.IdempotencyKey != "" {
+		return res, nil
+	}
+
+	s.levels[level.UUID] = level
+	s.games[level.UUID] = sessionRes.Session.Game
+
+	res := domain.InsertResult{
+		UUID:       level.UUID,
+		ServerTime: level.ServerTime,
+	}
+
+	if q.IdempotencyKey != "" {
+		s.levelKeys[key] = res
+	}
+
+	return res, nil
+}
+
+func (s *store) InsertEvent(ctx context.Context, q domain.InsertEventQuery) (domain.InsertEventResult, error) {
+	if err := q.Validate(); err != nil {
+		return domain.InsertEventResult{}, fmt.Errorf("invalid query: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.exists(q.UUID, q.Game) {
+		return domain.InsertEventResult{}, fmt.Errorf("failed to insert event: level %q: %w", q.UUID, domain.ErrNotFound)
+	}
+
+	if res, ok := s.eventKeys[eventKey(q)]; ok && q.IdempotencyKey != "" {
+		return res, nil
+	}
+
+	ended, err := s.ended(ctx, q.UUID)
+	if err != nil {
+		return domain.InsertEventResult{}, err
+	}
+
+	if ended {
+		return domain.InsertEventResult{}, fmt.Errorf("level %q has ended: %w", q.UUID, domain.ErrConflict)
+	}
+
+	return s.insertEvent(q, time.Now().UTC()), nil
+}
+
+// insertEvent inserts the event unless one with the same idempotency key exists,
+// and ends the attempt if the event has an outcome. It must be called with the lock held.
+func (s *store) insertEvent(q domain.InsertEventQuery, serverTime time.Time) domain.InsertEventResult {
+	key := eventKey(q)
+
+	if res, ok := s.eventKeys[key]; ok && q.IdempotencyKey != "" {
+		return res
+	}
+
+	event := domain.LevelEvent{
+		UUID:       uuid.NewString(),
+		LevelUUID:  q.UUID,
+		Event:      q.Event,
+		ClientTime: q.ClientTime,
+		ServerTime: serverTime,
+		Metadata:   copyMetadata(q.Metadata),
+		Position:   copyPosition(q.Position),
+		Cause:      q.Cause,
+	}
+
+	s.events = append(s.events, event)
+
+	if q.Outcome != "" {
+		level := s.levels[q.UUID]
+		level.EndedAt = &serverTime
+		level.Outcome = q.Outcome
+		s.levels[q.UUID] = level
+	}
+
+	res := domain.InsertEventResult{
+		UUID:       event.UUID,
+		ServerTime: event.ServerTime,
+	}
+
+	if q.IdempotencyKey != "" {
+		s.eventKeys[key] = res
+	}
+
+	return res
+}
+
+func (s *store) InsertEvents(ctx context.Context, q domain.InsertEventsQuery) (domain.InsertEventsResult, error) {
+	res := domain.InsertEventsResult{
+		Results: make([]domain.InsertEventsItemResult, len(q.Events)),
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	valid := make([]int, 0, len(q.Events))
+
+	// ended holds whether the attempts of the levels of the batch ended, before
+	// or by an earlier event of the batch, and keys the idempotency keys of the
+	// events to insert, whose duplicates are replays.
+	ended := make(map[string]bool)
+	keys := make(map[string]bool)
+
+	for i, e := range q.Events {
+		err := e.Validate()
+		if err != nil {
+			err = errutil.WithKind(err, domain.ErrInvalidArgument)
+		} else if !s.exists(e.UUID, e.Game) {
+			err = fmt.Errorf("level %q: %w", e.UUID, domain.ErrNotFound)
+		} else {
+			err = s.checkBatchOpen(ctx, e, ended, keys)
+		}
+
+		if err != nil {
+			if q.Atomic {
+				return domain.InsertEventsResult{}, fmt.Errorf("event %d: %w", i, err)
+			}
+
+			res.Results[i].Err = err
+			continue
+		}
+
+		valid = append(valid, i)
+	}
+
+	serverTime := time.Now().UTC()
+
+	for _, i := range valid {
+		r := s.insertEvent(q.Events[i], serverTime)
+
+		res.Results[i] = domain.InsertEventsItemResult{
+			UUID:       r.UUID,
+			ServerTime: r.ServerTime,
+		}
+	}
+
+	return res, nil
+}
+
+// checkBatchOpen returns ErrConflict if the attempt of an event of a batch has
+// ended, unless the event replays an idempotency key. It must be called with the lock held.
+func (s *store) checkBatchOpen(ctx context.Context, e domain.InsertEventQuery, ended, keys map[string]bool) error {
+	key := eventKey(e)
+	if _, ok := s.eventKeys[key]; e.IdempotencyKey != "" && (ok || keys[key]) {
+		return nil
+	}
+
+	isEnded, ok := ended[e.UUID]
+	if !ok {
+		var err error
+		if isEnded, err = s.ended(ctx, e.UUID); err != nil {
+			return err
+		}
+	}
+
+	if isEnded {
+		return fmt.Errorf("level %q has ended: %w", e.UUID, domain.ErrConflict)
+	}
+
+	ended[e.UUID] = e.Outcome != ""
+
+	if e.IdempotencyKey != "" {
+		keys[key] = true
+	}
+
+	return nil
+}
+
+func (s *store) Get(ctx context.Context, q domain.GetQuery) (domain.GetResult, error) {
+	s.mu.RLock()
+	level, ok := s.levels[q.UUID]
+	ok = ok && s.exists(q.UUID, q.Game)
+	s.mu.RUnlock()
+
+	if !ok {
+		return domain.GetResult{}, fmt.Errorf("level %q: %w", q.UUID, domain.ErrNotFound)
+	}
+
+	level.Metadata = copyMetadata(level.Metadata)
+
+	level, err := s.withSessionEnd(ctx, level)
+	if err != nil {
+		return domain.GetResult{}, err
+	}
+
+	return domain.GetResult{
+		Level: level,
+	}, nil
+}
+
+func (s *store) List(ctx context.Context, q domain.ListQuery) (domain.ListResult, error) {
+	s.mu.RLock()
+
+	levels := make([]domain.Level, 0)
+	for _, l := range s.levels {
+		if l.SessionUUID != q.SessionUUID || !s.exists(l.UUID, q.Game) || !after(l.ServerTime, l.UUID, q.Cursor) {
+			continue
+		}
+
+		l.Metadata = copyMetadata(l.Metadata)
+		levels = append(levels, l)
+	}
+
+	s.mu.RUnlock()
+
+	for i, l := range levels {
+		l, err := s.withSessionEnd(ctx, l)
+		if err != nil {
+			return domain.ListResult{}, err
+		}
+
+		levels[i] = l
+	}
+
+	sort.Slice(levels, func(i, j int) bool {
+		return less(levels[i].ServerTime, levels[i].UUID, levels[j].ServerTime, levels[j].UUID)
+	})
+
+	var res domain.ListResult
+
+	if len(levels) > q.Limit {
+		levels = levels[:q.Limit]
+		last := levels[len(levels)-1]
+		res.NextCursor = pagination.Cursor{ServerTime: last.ServerTime, UUID: last.UUID}
+	}
+
+	res.Levels = levels
+
+	return res, nil
+}
+
+func (s *store) ListEvents(ctx context.Context, q domain.ListEventsQuery) (domain.ListEventsResult, error) {
+	filter := make(map[domain.Event]struct{}, len(q.Events))
+	for _, e := range q.Events {
+		filter[e] = struct{}{}
+	}
+
+	s.mu.RLock()
+
+	events := make([]domain.LevelEvent, 0)
+	for _, e := range s.events {
+		if e.LevelUUID != q.LevelUUID || !s.exists(e.LevelUUID, q.Game) || !after(e.ServerTime, e.UUID, q.Cursor) {
+			continue
+		}
+
+		if _, ok := filter[e.Event]; len(filter) > 0 && !ok {
+			continue
+		}
+
+		if !q.From.IsZero() && e.ServerTime.Before(q.From) {
+			continue
+		}
+
+		if !q.To.IsZero() && !e.ServerTime.Before(q.To) {
+			continue
+		}
+
+		e.Metadata = copyMetadata(e.Metadata)
+		e.Position = copyPosition(e.Position)
+		events = append(events, e)
+	}
+
+	s.mu.RUnlock()
+
+	sort.Slice(events, func(i, j int) bool {
+		return less(events[i].ServerTime, events[i].UUID, events[j].ServerTime, events[j].UUID)
+	})
+
+	var res domain.ListEventsResult
+
+	if len(events) > q.Limit {
+		events = events[:q.Limit]
+		last := events[len(events)-1]
+		res.NextCursor = pagination.Cursor{ServerTime: last.ServerTime, UUID: last.UUID}
+	}
+
+	res.Events = events
+
+	return res, nil
+}
+
+func (s *store) Grid(ctx context.Context, q domain.GridQuery) (domain.GridResult, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	counts := make(map[domain.Cell]int)
+
+	for _, e := range s.events {
+		if e.Event != q.Event || e.Position == nil || s.levels[e.LevelUUID].Level != q.Level || !s.exists(e.LevelUUID, q.Game) {
+			continue
+		}
+
+		if q.Cause != "" && e.Cause != q.Cause {
+			continue
+		}
+
+		if !q.From.IsZero() && e.ServerTime.Before(q.From) {
+			continue
+		}
+
+		if !q.To.IsZero() && !e.ServerTime.Before(q.To) {
+			continue
+		}
+
+		counts[domain.Cell{
+			X: int(math.Floor(e.Position.X / q.CellSize)),
+			Y: int(math.Floor(e.Position.Y / q.CellSize)),
+		}]++
+	}
+
+	cells := make([]domain.Cell, 0, len(counts))
+	for c, n := range counts {
+		c.Count = n
+		cells = append(cells, c)
+	}
+
+	sort.Slice(cells, func(i, j int) bool {
+		if cells[i].X == cells[j].X {
+			return cells[i].Y < cells[j].Y
+		}
+
+		return cells[i].X < cells[j].X
+	})
+
+	return domain.GridResult{
+		Cells: cells,
+	}, nil
+}
+
+func (s *store) SetBackground(ctx context.Context, q domain.SetBackgroundQuery) (domain.SetBackgroundResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	background := domain.Background{
+		Game:      q.Game,
+		Level:     q.Level,
+		Image:     append([]byte(nil), q.Image...),
+		Format:    q.Format,
+		Origin:    q.Origin,
+		Scale:     q.Scale,
+		UpdatedAt: time.Now().UTC(),
+	}
+
+	s.backgrounds[backgroundKey{game: q.Game, level: q.Level}] = background
+
+	return domain.SetBackgroundResult{
+		UpdatedAt: background.UpdatedAt,
+	}, nil
+}
+
+func (s *store) GetBackground(ctx context.Context, q domain.GetBackgroundQuery) (domain.GetBackgroundResult, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	background, ok := s.backgrounds[backgroundKey{game: q.Game, level: q.Level}]
+	if !ok {
+		return domain.GetBackgroundResult{}, fmt.Errorf("background of level %d: %w", q.Level, domain.ErrNotFound)
+	}
+
+	if q.WithoutImage {
+		background.Image = nil
+	}
+
+	return domain.GetBackgroundResult{
+		Background: background,
+	}, nil
+}
+
+// withSessionEnd abandons the open attempt if its session ended.
+func (s *store) withSessionEnd(ctx context.Context, level domain.Level) (domain.Level, error) {
+	if level.Ended() {
+		return level, nil
+	}
+
+	sessionRes, err := s.sessionStore.Get(ctx, sessiondomain.GetQuery{UUID: level.SessionUUID})
+	if err != nil {
+		return domain.Level{}, fmt.Errorf("failed to get session: %w", err)
+	}
+
+	if sessionRes.Session.EndedAt != nil {
+		level.EndedAt = sessionRes.Session.EndedAt
+		level.Outcome = domain.OutcomeAbandon
+	}
+
+	return level, nil
+}
+
+// ended reports whether the attempt of the level ended, or its session ended.
+// It must be called with the lock held.
+func (s *store) ended(ctx context.Context, levelUUID string) (bool, error) {
+	level, err := s.withSessionEnd(ctx, s.levels[levelUUID])
+	if err != nil {
+		return false, err
+	}
+
+	return level.Ended(), nil
+}
+
+// eventKey identifies the idempotency key of an event, which is unique per level and event.
+func eventKey(e domain.InsertEventQuery) string {
+	return e.UUID + "/" + string(e.Event) + "/" + e.IdempotencyKey
+}
+
+// exists reports whether the level exists and belongs to game, any game if empty.
+// It must be called with the lock held.
+func (s *store) exists(levelUUID, game string) bool {
+	if _, ok := s.levels[levelUUID]; !ok {
+		return false
+	}
+
+	return game == "" || s.games[levelUUID] == game
+}
+
+func less(t1 time.Time, uuid1 string, t2 time.Time, uuid2 string) bool {
+	if t1.Equal(t2) {
+		return uuid1 < uuid2
+	}
+
+	return t1.Before(t2)
+}
+
+func after(t time.Time, uuid string, c pagination.Cursor) bool {
+	if c.IsZero() {
+		return true
+	}
+
+	return less(c.ServerTime, c.UUID, t, uuid)
+}
+
+func copyMetadata(m map[string]interface{}) map[string]interface{} {
+	if m == nil {
+		return nil
+	}
+
+	c := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		c[k] = v
+	}
+
+	return c
+}
+
+func copyPosition(p *domain.Position) *domain.Position {
+	if p == nil {
+		return nil
+	}
+
+	c := *p
+
+	return &c
+}
+
+func (s *store) Ping(ctx context.Context) error {
+	return nil
+}
+
+func (s *store) Close() error {
+	return nil
+}
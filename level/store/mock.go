@@ -21,3 +21,48 @@ func (s mock) InsertEvent(ctx context.Context, q domain.InsertEventQuery) (domai
 	//TODO implement me
 	panic("implement me")
 }
+
+func (s mock) Get(ctx context.Context, q domain.GetQuery) (domain.GetResult, error) {
+	//TODO implement me
+	panic("implement me")
+}
+
+func (s mock) List(ctx context.Context, q domain.ListQuery) (domain.ListResult, error) {
+	//TODO implement me
+	panic("implement me")
+}
+
+func (s mock) ListEvents(ctx context.Context, q domain.ListEventsQuery) (domain.ListEventsResult, error) {
+	//TODO implement me
+	panic("implement me")
+}
+
+func (s mock) InsertEvents(ctx context.Context, q domain.InsertEventsQuery) (domain.InsertEventsResult, error) {
+	//TODO implement me
+	panic("implement me")
+}
+
+func (s mock) Grid(ctx context.Context, q domain.GridQuery) (domain.GridResult, error) {
+	//TODO implement me
+	panic("implement me")
+}
+
+func (s mock) SetBackground(ctx context.Context, q domain.SetBackgroundQuery) (domain.SetBackgroundResult, error) {
+	//TODO implement me
+	panic("implement me")
+}
+
+func (s mock) GetBackground(ctx context.Context, q domain.GetBackgroundQuery) (domain.GetBackgroundResult, error) {
+	//TODO implement me
+	panic("implement me")
+}
+
+func (s mock) Ping(ctx context.Context) error {
+	//TODO implement me
+	panic("implement me")
+}
+
+func (s mock) Close() error {
+	//TODO implement me
+	panic("implement me")
+}
@@ -2,29 +2,41 @@ package postgresql
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
+	"github.com/vediagames/onlooker/database"
 	domain "github.com/vediagames/onlooker/domain/level"
 	"github.com/vediagames/onlooker/errutil"
+	"github.com/vediagames/onlooker/pagination"
 )
 
 type store struct {
-	db *sqlx.DB
+	db       *sqlx.DB
+	registry *domain.Registry
 }
 
 type Config struct {
-	ConnectionString string
+	DB       *sqlx.DB
+	Registry *domain.Registry
 }
 
 func (c Config) Validate() error {
 	var err errutil.Error
 
-	if c.ConnectionString == "" {
-		err.Add(fmt.Errorf("connection string is empty"))
+	if c.DB == nil {
+		err.Add(fmt.Errorf("db is empty"))
+	}
+
+	if c.Registry == nil {
+		err.Add(fmt.Errorf("registry is empty"))
 	}
 
 	return err.Err()
@@ -35,20 +47,9 @@ func New(cfg Config) (domain.Store, error) {
 		return nil, fmt.Errorf("invalid config: %w", ve)
 	}
 
-	db, err := sqlx.Open("postgres", cfg.ConnectionString)
-	if err != nil {
-		return nil, fmt.Errorf("failed to open database: %v", err)
-	}
-
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-
-	if err = db.PingContext(ctx); err != nil {
-		return nil, fmt.Errorf("failed to ping database: %v", err)
-	}
-
 	return &store{
-		db: db,
+		db:       cfg.DB,
+		registry: cfg.Registry,
 	}, nil
 }
 
@@ -65,13 +66,19 @@ func (s store) Insert(ctx context.Context, q domain.InsertQuery) (domain.InsertR
 		return domain.InsertResult{}, fmt.Errorf("failed to marshal metadata: %w", err)
 	}
 
-	err = s.db.Get(&res, `
-		INSERT INTO levels (session_uuid, client_time, server_time, level, metadata) 
-		VALUES ($1, $2, now(), $3, $4)
+	err = s.db.GetContext(ctx, &res, `
+		INSERT INTO levels (session_uuid, client_time, server_time, level, metadata, idempotency_key) 
+		SELECT $1::uuid, $2::timestamp, now(), $3::int, $4::jsonb, $5::text
+		WHERE $6::text = '' OR EXISTS (SELECT 1 FROM sessions WHERE uuid = $1::uuid AND game = $6::text)
+		ON CONFLICT (session_uuid, idempotency_key) WHERE idempotency_key IS NOT NULL
+		DO UPDATE SET idempotency_key = EXCLUDED.idempotency_key
 		RETURNING uuid, server_time
-	`, q.SessionUUID, q.ClientTime, q.Level, metadata)
+	`, q.SessionUUID, q.ClientTime, q.Level, metadata, database.NullString(q.IdempotencyKey), q.Game)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.InsertResult{}, fmt.Errorf("failed to insert level: session %q: %w", q.SessionUUID, domain.ErrNotFound)
+	}
 	if err != nil {
-		return domain.InsertResult{}, fmt.Errorf("failed to insert level: %v", err)
+		return domain.InsertResult{}, fmt.Errorf("failed to insert level: %w", database.TranslateError(err))
 	}
 
 	return domain.InsertResult{
@@ -80,13 +87,16 @@ func (s store) Insert(ctx context.Context, q domain.InsertQuery) (domain.InsertR
 	}, nil
 }
 
-var eventTableMap = map[domain.Event]string{
-	domain.EventComplete:           "level_complete_events",
-	domain.EventDeath:              "level_death_events",
-	domain.EventGrapplingHookUsage: "level_grappling_hook_events",
-}
-
 func (s store) InsertEvent(ctx context.Context, q domain.InsertEventQuery) (domain.InsertEventResult, error) {
+	if err := q.Validate(); err != nil {
+		return domain.InsertEventResult{}, fmt.Errorf("invalid query: %w", err)
+	}
+
+	def, ok := s.registry.Get(q.Event)
+	if !ok {
+		return domain.InsertEventResult{}, fmt.Errorf("event %q: %w", q.Event, domain.ErrNotFound)
+	}
+
 	var res insertResult
 
 	metadata, err := json.Marshal(q.Metadata)
@@ -94,15 +104,68 @@ func (s store) InsertEvent(ctx context.Context, q domain.InsertEventQuery) (doma
 		return domain.InsertEventResult{}, fmt.Errorf("failed to marshal metadata: %w", err)
 	}
 
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return domain.InsertEventResult{}, fmt.Errorf("failed to begin transaction: %w", database.TranslateError(err))
+	}
+	defer tx.Rollback()
+
+	levels, err := lockLevels(ctx, tx, []string{q.UUID})
+	if err != nil {
+		return domain.InsertEventResult{}, err
+	}
+
+	l, ok := levels[normalizeUUID(q.UUID)]
+	if !ok || (q.Game != "" && l.Game != q.Game) {
+		return domain.InsertEventResult{}, fmt.Errorf("failed to insert event: level %q: %w", q.UUID, domain.ErrNotFound)
+	}
+
+	if q.IdempotencyKey != "" {
+		existing, err := selectKeyedEvents(ctx, tx, def.TableName(), []domain.InsertEventQuery{q})
+		if err != nil {
+			return domain.InsertEventResult{}, err
+		}
+
+		if e, ok := existing[eventKey(q.UUID, q.Event, q.IdempotencyKey)]; ok {
+			return domain.InsertEventResult{
+				UUID:       e.UUID,
+				ServerTime: e.ServerTime,
+			}, nil
+		}
+	}
+
+	if l.Ended {
+		return domain.InsertEventResult{}, fmt.Errorf("level %q has ended: %w", q.UUID, domain.ErrConflict)
+	}
+
+	args := []interface{}{q.UUID, q.ClientTime, metadata, database.NullString(q.IdempotencyKey)}
+	columns := "level_uuid, client_time, server_time, metadata, idempotency_key"
+	values := "$1::uuid, $2::timestamp, now(), $3::jsonb, $4::text"
+
+	for _, c := range eventColumns(def, q) {
+		args = append(args, c.Value)
+		columns += ", " + c.Name
+		values += fmt.Sprintf(", $%d::%s", len(args), c.Type)
+	}
+
 	sqlQuery := fmt.Sprintf(`
-		INSERT INTO %s (level_uuid, client_time, server_time, metadata) 
-		VALUES ($1, $2, now(), $3)
+		INSERT INTO %s (%s) 
+		VALUES (%s)
 		RETURNING uuid, server_time
-	`, eventTableMap[q.Event])
+	`, def.TableName(), columns, values)
 
-	err = s.db.Get(&res, sqlQuery, q.UUID, q.ClientTime, metadata)
-	if err != nil {
-		return domain.InsertEventResult{}, fmt.Errorf("failed to insert event: %v", err)
+	if err := tx.GetContext(ctx, &res, sqlQuery, args...); err != nil {
+		return domain.InsertEventResult{}, fmt.Errorf("failed to insert event: %w", database.TranslateError(err))
+	}
+
+	if q.Outcome != "" {
+		if err := endLevels(ctx, tx, map[string]domain.Outcome{l.UUID: q.Outcome}); err != nil {
+			return domain.InsertEventResult{}, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return domain.InsertEventResult{}, fmt.Errorf("failed to commit transaction: %w", database.TranslateError(err))
 	}
 
 	return domain.InsertEventResult{
@@ -110,3 +173,833 @@ func (s store) InsertEvent(ctx context.Context, q domain.InsertEventQuery) (doma
 		ServerTime: res.ServerTime,
 	}, nil
 }
+
+// eventColumn is a column of the table of an event besides the columns all event tables have.
+type eventColumn struct {
+	Name  string
+	Type  string
+	Value interface{}
+}
+
+// eventColumns returns the columns of the table of def besides the columns all
+// event tables have, with their values for e. The shared table has the event
+// and the position columns, tables of events with a position have position columns
+// and the table of deaths has their cause.
+func eventColumns(def domain.EventDefinition, e domain.InsertEventQuery) []eventColumn {
+	shared := def.TableName() == domain.SharedEventTable
+
+	var columns []eventColumn
+
+	if shared {
+		columns = append(columns, eventColumn{Name: "event", Type: "text", Value: string(e.Event)})
+	}
+
+	if shared || def.Position {
+		var x, y sql.NullFloat64
+		if e.Position != nil {
+			x = sql.NullFloat64{Float64: e.Position.X, Valid: true}
+			y = sql.NullFloat64{Float64: e.Position.Y, Valid: true}
+		}
+
+		columns = append(columns,
+			eventColumn{Name: "x", Type: "float8", Value: x},
+			eventColumn{Name: "y", Type: "float8", Value: y},
+		)
+	}
+
+	if def.Name == domain.EventDeath {
+		columns = append(columns, eventColumn{Name: "cause", Type: "text", Value: database.NullString(e.Cause)})
+	}
+
+	return columns
+}
+
+// eventSelectColumns returns the position and cause columns of the table of def
+// for selects, which are null if the table does not have them.
+func eventSelectColumns(def domain.EventDefinition) string {
+	columns := "NULL::float8 AS x, NULL::float8 AS y"
+	if def.TableName() == domain.SharedEventTable || def.Position {
+		columns = "x, y"
+	}
+
+	if def.Name == domain.EventDeath {
+		return columns + ", cause"
+	}
+
+	return columns + ", NULL::text AS cause"
+}
+
+// lockedLevel is a level locked by lockLevels.
+type lockedLevel struct {
+	UUID string `db:"uuid"`
+	Game string `db:"game"`
+	// Ended is set if the attempt or its session ended.
+	Ended bool `db:"ended"`
+}
+
+// lockLevels locks the levels with the UUIDs until the end of tx, so that the
+// events of a level are inserted and end its attempt one transaction at a time.
+// It returns the existing levels by UUID.
+func lockLevels(ctx context.Context, tx *sqlx.Tx, uuids []string) (map[string]lockedLevel, error) {
+	var rows []lockedLevel
+
+	err := tx.SelectContext(ctx, &rows, `
+		SELECT l.uuid, s.game, COALESCE(l.ended_at, s.ended_at) IS NOT NULL AS ended
+		FROM levels l
+		JOIN sessions s ON s.uuid = l.session_uuid
+		WHERE l.uuid = ANY($1::uuid[])
+		ORDER BY l.uuid
+		FOR UPDATE OF l
+	`, pq.Array(uuids))
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock levels: %w", database.TranslateError(err))
+	}
+
+	levels := make(map[string]lockedLevel, len(rows))
+	for _, r := range rows {
+		levels[r.UUID] = r
+	}
+
+	return levels, nil
+}
+
+// endLevels ends the attempts of the levels with their outcome, by UUID.
+func endLevels(ctx context.Context, tx *sqlx.Tx, outcomes map[string]domain.Outcome) error {
+	if len(outcomes) == 0 {
+		return nil
+	}
+
+	uuids := make([]string, 0, len(outcomes))
+	values := make([]string, 0, len(outcomes))
+
+	for id, o := range outcomes {
+		uuids = append(uuids, id)
+		values = append(values, string(o))
+	}
+
+	_, err := tx.ExecContext(ctx, `
+		UPDATE levels l
+		SET ended_at = now(), outcome = v.outcome
+		FROM unnest($1::uuid[], $2::text[]) AS v(uuid, outcome)
+		WHERE l.uuid = v.uuid AND l.ended_at IS NULL
+	`, pq.Array(uuids), pq.Array(values))
+	if err != nil {
+		return fmt.Errorf("failed to end levels: %w", database.TranslateError(err))
+	}
+
+	return nil
+}
+
+type keyedEvent struct {
+	UUID           string         `db:"uuid"`
+	ServerTime     time.Time      `db:"server_time"`
+	LevelUUID      string         `db:"level_uuid"`
+	Event          sql.NullString `db:"event"`
+	IdempotencyKey string         `db:"idempotency_key"`
+}
+
+// selectKeyedEvents returns the events of table with the idempotency keys of
+// events, by eventKey. The events must all belong in table and have a key.
+func selectKeyedEvents(ctx context.Context, tx *sqlx.Tx, table string, events []domain.InsertEventQuery) (map[string]keyedEvent, error) {
+	levelUUIDs := make([]string, 0, len(events))
+	keys := make([]string, 0, len(events))
+
+	for _, e := range events {
+		levelUUIDs = append(levelUUIDs, e.UUID)
+		keys = append(keys, e.IdempotencyKey)
+	}
+
+	event := "NULL::text"
+	if table == domain.SharedEventTable {
+		event = "event"
+	}
+
+	var rows []keyedEvent
+
+	err := tx.SelectContext(ctx, &rows, fmt.Sprintf(`
+		SELECT uuid, server_time, level_uuid, %s AS event, idempotency_key
+		FROM %s
+		WHERE level_uuid = ANY($1::uuid[]) AND idempotency_key = ANY($2::text[])
+	`, event, table), pq.Array(levelUUIDs), pq.Array(keys))
+	if err != nil {
+		return nil, fmt.Errorf("failed to select idempotency keys: %w", database.TranslateError(err))
+	}
+
+	existing := make(map[string]keyedEvent, len(rows))
+
+	for _, r := range rows {
+		// Dedicated tables hold one event, which is the event of all events.
+		e := events[0].Event
+		if r.Event.Valid {
+			e = domain.Event(r.Event.String)
+		}
+
+		existing[eventKey(r.LevelUUID, e, r.IdempotencyKey)] = r
+	}
+
+	return existing, nil
+}
+
+// insertEventsChunkSize limits the rows of one INSERT statement to stay
+// below the maximum number of parameters.
+const insertEventsChunkSize = 1000
+
+func (s store) InsertEvents(ctx context.Context, q domain.InsertEventsQuery) (domain.InsertEventsResult, error) {
+	res := domain.InsertEventsResult{
+		Results: make([]domain.InsertEventsItemResult, len(q.Events)),
+	}
+
+	fail := func(i int, err error) error {
+		if q.Atomic {
+			return fmt.Errorf("event %d: %w", i, err)
+		}
+
+		res.Results[i].Err = err
+
+		return nil
+	}
+
+	levelUUIDs := make([]string, 0, len(q.Events))
+	pending := make([]int, 0, len(q.Events))
+
+	for i, e := range q.Events {
+		if err := e.Validate(); err != nil {
+			if err := fail(i, errutil.WithKind(err, domain.ErrInvalidArgument)); err != nil {
+				return domain.InsertEventsResult{}, err
+			}
+			continue
+		}
+
+		if _, ok := s.registry.Get(e.Event); !ok {
+			if err := fail(i, fmt.Errorf("event %q: %w", e.Event, domain.ErrNotFound)); err != nil {
+				return domain.InsertEventsResult{}, err
+			}
+			continue
+		}
+
+		if _, err := uuid.Parse(e.UUID); err != nil {
+			if err := fail(i, errutil.WithKind(fmt.Errorf("invalid level uuid %q", e.UUID), domain.ErrInvalidArgument)); err != nil {
+				return domain.InsertEventsResult{}, err
+			}
+			continue
+		}
+
+		levelUUIDs = append(levelUUIDs, e.UUID)
+		pending = append(pending, i)
+	}
+
+	if len(pending) == 0 {
+		return res, nil
+	}
+
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return domain.InsertEventsResult{}, fmt.Errorf("failed to begin transaction: %w", database.TranslateError(err))
+	}
+	defer tx.Rollback()
+
+	levels, err := lockLevels(ctx, tx, levelUUIDs)
+	if err != nil {
+		return domain.InsertEventsResult{}, err
+	}
+
+	// Events with an idempotency key replay the event inserted with it, if any.
+	keyed := make(map[string][]domain.InsertEventQuery)
+	for _, i := range pending {
+		if e := q.Events[i]; e.IdempotencyKey != "" {
+			def, _ := s.registry.Get(e.Event)
+			keyed[def.TableName()] = append(keyed[def.TableName()], e)
+		}
+	}
+
+	existing := make(map[string]keyedEvent)
+	for table, events := range keyed {
+		rows, err := selectKeyedEvents(ctx, tx, table, events)
+		if err != nil {
+			return domain.InsertEventsResult{}, err
+		}
+
+		for k, r := range rows {
+			existing[k] = r
+		}
+	}
+
+	var tables []string
+	byTable := make(map[string][]int)
+
+	// duplicates maps events to an earlier event of the batch with the same
+	// idempotency key, they can not be inserted by the same statement.
+	duplicates := make(map[int]int)
+	keys := make(map[string]int)
+
+	// ended holds whether the attempts of the levels of the batch ended, before
+	// or by an earlier event of the batch, and outcomes the attempts to end.
+	ended := make(map[string]bool)
+	outcomes := make(map[string]domain.Outcome)
+
+	for _, i := range pending {
+		e := q.Events[i]
+		id := normalizeUUID(e.UUID)
+
+		l, ok := levels[id]
+		if !ok || (e.Game != "" && l.Game != e.Game) {
+			if err := fail(i, fmt.Errorf("level %q: %w", e.UUID, domain.ErrNotFound)); err != nil {
+				return domain.InsertEventsResult{}, err
+			}
+			continue
+		}
+
+		k := eventKey(e.UUID, e.Event, e.IdempotencyKey)
+		if e.IdempotencyKey != "" {
+			if r, ok := existing[k]; ok {
+				res.Results[i] = domain.InsertEventsItemResult{
+					UUID:       r.UUID,
+					ServerTime: r.ServerTime,
+				}
+				continue
+			}
+
+			if first, ok := keys[k]; ok {
+				duplicates[i] = first
+				continue
+			}
+		}
+
+		isEnded, ok := ended[id]
+		if !ok {
+			isEnded = l.Ended
+		}
+
+		if isEnded {
+			if err := fail(i, fmt.Errorf("level %q has ended: %w", e.UUID, domain.ErrConflict)); err != nil {
+				return domain.InsertEventsResult{}, err
+			}
+			continue
+		}
+
+		ended[id] = e.Outcome != ""
+		if e.Outcome != "" {
+			outcomes[id] = e.Outcome
+		}
+
+		if e.IdempotencyKey != "" {
+			keys[k] = i
+		}
+
+		def, _ := s.registry.Get(e.Event)
+		table := def.TableName()
+
+		if _, ok := byTable[table]; !ok {
+			tables = append(tables, table)
+		}
+
+		byTable[table] = append(byTable[table], i)
+	}
+
+	for _, table := range tables {
+		indexes := byTable[table]
+
+		for start := 0; start < len(indexes); start += insertEventsChunkSize {
+			end := start + insertEventsChunkSize
+			if end > len(indexes) {
+				end = len(indexes)
+			}
+
+			if err := s.insertEventsChunk(ctx, tx, table, q.Events, indexes[start:end], res.Results); err != nil {
+				return domain.InsertEventsResult{}, err
+			}
+		}
+	}
+
+	if err := endLevels(ctx, tx, outcomes); err != nil {
+		return domain.InsertEventsResult{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return domain.InsertEventsResult{}, fmt.Errorf("failed to commit transaction: %w", database.TranslateError(err))
+	}
+
+	for i, first := range duplicates {
+		res.Results[i] = res.Results[first]
+	}
+
+	return res, nil
+}
+
+// insertEventsChunk inserts the events at indexes into table with one
+// statement and writes their UUID and server time into results.
+func (s store) insertEventsChunk(ctx context.Context, tx *sqlx.Tx, table string, events []domain.InsertEventQuery, indexes []int, results []domain.InsertEventsItemResult) error {
+	columns := "uuid, level_uuid, client_time, server_time, metadata, idempotency_key"
+
+	// The events of a table have the same columns, which are taken from the first.
+	def, _ := s.registry.Get(events[indexes[0]].Event)
+	for _, c := range eventColumns(def, events[indexes[0]]) {
+		columns += ", " + c.Name
+	}
+
+	values := make([]string, 0, len(indexes))
+	args := make([]interface{}, 0, len(indexes)*8)
+	byUUID := make(map[string]int, len(indexes))
+
+	for _, i := range indexes {
+		e := events[i]
+
+		metadata, err := json.Marshal(e.Metadata)
+		if err != nil {
+			return fmt.Errorf("event %d: failed to marshal metadata: %w", i, err)
+		}
+
+		id := uuid.NewString()
+		byUUID[id] = i
+
+		args = append(args, id, e.UUID, e.ClientTime, metadata, database.NullString(e.IdempotencyKey))
+		n := len(args)
+		value := fmt.Sprintf("($%d, $%d, $%d, now(), $%d, $%d", n-4, n-3, n-2, n-1, n)
+
+		def, _ := s.registry.Get(e.Event)
+		for _, c := range eventColumns(def, e) {
+			args = append(args, c.Value)
+			value += fmt.Sprintf(", $%d::%s", len(args), c.Type)
+		}
+
+		values = append(values, value+")")
+	}
+
+	sqlQuery := fmt.Sprintf(`
+		INSERT INTO %s (%s)
+		VALUES %s
+		RETURNING uuid, server_time
+	`, table, columns, strings.Join(values, ", "))
+
+	var rows []insertResult
+
+	if err := tx.SelectContext(ctx, &rows, sqlQuery, args...); err != nil {
+		return fmt.Errorf("failed to insert events: %w", database.TranslateError(err))
+	}
+
+	for _, r := range rows {
+		i, ok := byUUID[r.UUID]
+		if !ok {
+			return fmt.Errorf("failed to insert events: unexpected uuid %q", r.UUID)
+		}
+
+		results[i] = domain.InsertEventsItemResult{
+			UUID:       r.UUID,
+			ServerTime: r.ServerTime,
+		}
+	}
+
+	return nil
+}
+
+// eventKey identifies the idempotency key of an event, which is unique per level
+// and event.
+func eventKey(levelUUID string, event domain.Event, idempotencyKey string) string {
+	return normalizeUUID(levelUUID) + "/" + string(event) + "/" + idempotencyKey
+}
+
+// normalizeUUID returns id as it is returned by the database, if it is valid.
+func normalizeUUID(id string) string {
+	if u, err := uuid.Parse(id); err == nil {
+		return u.String()
+	}
+
+	return id
+}
+
+type level struct {
+	UUID        string         `db:"uuid"`
+	SessionUUID string         `db:"session_uuid"`
+	Level       int            `db:"level"`
+	ClientTime  time.Time      `db:"client_time"`
+	ServerTime  time.Time      `db:"server_time"`
+	Metadata    []byte         `db:"metadata"`
+	EndedAt     *time.Time     `db:"ended_at"`
+	Outcome     sql.NullString `db:"outcome"`
+}
+
+func (l level) toDomain() (domain.Level, error) {
+	var metadata map[string]interface{}
+
+	if len(l.Metadata) > 0 {
+		if err := json.Unmarshal(l.Metadata, &metadata); err != nil {
+			return domain.Level{}, fmt.Errorf("failed to unmarshal metadata: %w", err)
+		}
+	}
+
+	return domain.Level{
+		UUID:        l.UUID,
+		SessionUUID: l.SessionUUID,
+		Level:       l.Level,
+		ClientTime:  l.ClientTime,
+		ServerTime:  l.ServerTime,
+		Metadata:    metadata,
+		EndedAt:     l.EndedAt,
+		Outcome:     domain.Outcome(l.Outcome.String),
+	}, nil
+}
+
+// selectLevels selects levels with their session, open attempts of ended sessions are abandoned.
+const selectLevels = `
+	SELECT l.uuid, l.session_uuid, l.level, l.client_time, l.server_time, l.metadata,
+		COALESCE(l.ended_at, s.ended_at) AS ended_at,
+		COALESCE(l.outcome, CASE WHEN s.ended_at IS NOT NULL THEN 'abandon' END) AS outcome
+	FROM levels l
+	JOIN sessions s ON s.uuid = l.session_uuid`
+
+func (s store) Get(ctx context.Context, q domain.GetQuery) (domain.GetResult, error) {
+	var l level
+
+	err := s.db.GetContext(ctx, &l, selectLevels+`
+		WHERE l.uuid = $1 AND ($2 = '' OR s.game = $2)
+	`, q.UUID, q.Game)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.GetResult{}, fmt.Errorf("level %q: %w", q.UUID, domain.ErrNotFound)
+	}
+	if err != nil {
+		return domain.GetResult{}, fmt.Errorf("failed to get level: %w", database.TranslateError(err))
+	}
+
+	res, err := l.toDomain()
+	if err != nil {
+		return domain.GetResult{}, err
+	}
+
+	return domain.GetResult{
+		Level: res,
+	}, nil
+}
+
+func (s store) List(ctx context.Context, q domain.ListQuery) (domain.ListResult, error) {
+	args := []interface{}{q.SessionUUID}
+	sqlQuery := selectLevels + `
+		WHERE l.session_uuid = $1`
+
+	if q.Game != "" {
+		args = append(args, q.Game)
+		sqlQuery += fmt.Sprintf(" AND s.game = $%d", len(args))
+	}
+
+	if !q.Cursor.IsZero() {
+		args = append(args, q.Cursor.ServerTime, q.Cursor.UUID)
+		sqlQuery += fmt.Sprintf(" AND (l.server_time, l.uuid) > ($%d, $%d::uuid)", len(args)-1, len(args))
+	}
+
+	args = append(args, q.Limit+1)
+	sqlQuery += fmt.Sprintf(" ORDER BY l.server_time, l.uuid LIMIT $%d", len(args))
+
+	var rows []level
+
+	if err := s.db.SelectContext(ctx, &rows, sqlQuery, args...); err != nil {
+		return domain.ListResult{}, fmt.Errorf("failed to list levels: %w", database.TranslateError(err))
+	}
+
+	var res domain.ListResult
+
+	if len(rows) > q.Limit {
+		rows = rows[:q.Limit]
+		last := rows[len(rows)-1]
+		res.NextCursor = pagination.Cursor{ServerTime: last.ServerTime, UUID: last.UUID}
+	}
+
+	res.Levels = make([]domain.Level, 0, len(rows))
+
+	for _, r := range rows {
+		l, err := r.toDomain()
+		if err != nil {
+			return domain.ListResult{}, err
+		}
+
+		res.Levels = append(res.Levels, l)
+	}
+
+	return res, nil
+}
+
+type levelEvent struct {
+	UUID       string          `db:"uuid"`
+	LevelUUID  string          `db:"level_uuid"`
+	Event      string          `db:"event"`
+	ClientTime time.Time       `db:"client_time"`
+	ServerTime time.Time       `db:"server_time"`
+	Metadata   []byte          `db:"metadata"`
+	X          sql.NullFloat64 `db:"x"`
+	Y          sql.NullFloat64 `db:"y"`
+	Cause      sql.NullString  `db:"cause"`
+}
+
+func (e levelEvent) toDomain() (domain.LevelEvent, error) {
+	var metadata map[string]interface{}
+
+	if len(e.Metadata) > 0 {
+		if err := json.Unmarshal(e.Metadata, &metadata); err != nil {
+			return domain.LevelEvent{}, fmt.Errorf("failed to unmarshal metadata: %w", err)
+		}
+	}
+
+	var position *domain.Position
+	if e.X.Valid && e.Y.Valid {
+		position = &domain.Position{X: e.X.Float64, Y: e.Y.Float64}
+	}
+
+	return domain.LevelEvent{
+		UUID:       e.UUID,
+		LevelUUID:  e.LevelUUID,
+		Event:      domain.Event(e.Event),
+		ClientTime: e.ClientTime,
+		ServerTime: e.ServerTime,
+		Metadata:   metadata,
+		Position:   position,
+		Cause:      e.Cause.String,
+	}, nil
+}
+
+func (s store) ListEvents(ctx context.Context, q domain.ListEventsQuery) (domain.ListEventsResult, error) {
+	events := q.Events
+	if len(events) == 0 {
+		for _, d := range s.registry.Definitions() {
+			events = append(events, d.Name)
+		}
+	}
+
+	args := []interface{}{q.LevelUUID}
+	selects := make([]string, 0, len(events))
+	shared := make([]string, 0, len(events))
+
+	for _, e := range events {
+		def, ok := s.registry.Get(e)
+		if !ok {
+			return domain.ListEventsResult{}, fmt.Errorf("event %q: %w", e, domain.ErrInvalidArgument)
+		}
+
+		if def.TableName() == domain.SharedEventTable {
+			shared = append(shared, string(e))
+			continue
+		}
+
+		args = append(args, string(e))
+		selects = append(selects, fmt.Sprintf(`
+			SELECT uuid, level_uuid, $%d::text AS event, client_time, server_time, metadata, %s
+			FROM %s
+			WHERE level_uuid = $1`, len(args), eventSelectColumns(def), def.TableName()))
+	}
+
+	if len(shared) > 0 {
+		args = append(args, pq.Array(shared))
+		selects = append(selects, fmt.Sprintf(`
+			SELECT uuid, level_uuid, event, client_time, server_time, metadata, x, y, NULL::text AS cause
+			FROM level_events
+			WHERE level_uuid = $1 AND event = ANY($%d)`, len(args)))
+	}
+
+	sqlQuery := fmt.Sprintf(`
+		SELECT uuid, level_uuid, event, client_time, server_time, metadata, x, y, cause
+		FROM (%s) e
+		WHERE true`, strings.Join(selects, " UNION ALL "))
+
+	if q.Game != "" {
+		args = append(args, q.Game)
+		sqlQuery += " AND " + levelInGame(1, len(args))
+	}
+
+	if !q.From.IsZero() {
+		args = append(args, q.From.UTC())
+		sqlQuery += fmt.Sprintf(" AND server_time >= $%d", len(args))
+	}
+
+	if !q.To.IsZero() {
+		args = append(args, q.To.UTC())
+		sqlQuery += fmt.Sprintf(" AND server_time < $%d", len(args))
+	}
+
+	if !q.Cursor.IsZero() {
+		args = append(args, q.Cursor.ServerTime, q.Cursor.UUID)
+		sqlQuery += fmt.Sprintf(" AND (server_time, uuid) > ($%d, $%d::uuid)", len(args)-1, len(args))
+	}
+
+	args = append(args, q.Limit+1)
+	sqlQuery += fmt.Sprintf(" ORDER BY server_time, uuid LIMIT $%d", len(args))
+
+	var rows []levelEvent
+
+	if err := s.db.SelectContext(ctx, &rows, sqlQuery, args...); err != nil {
+		return domain.ListEventsResult{}, fmt.Errorf("failed to list events: %w", database.TranslateError(err))
+	}
+
+	var res domain.ListEventsResult
+
+	if len(rows) > q.Limit {
+		rows = rows[:q.Limit]
+		last := rows[len(rows)-1]
+		res.NextCursor = pagination.Cursor{ServerTime: last.ServerTime, UUID: last.UUID}
+	}
+
+	res.Events = make([]domain.LevelEvent, 0, len(rows))
+
+	for _, r := range rows {
+		e, err := r.toDomain()
+		if err != nil {
+			return domain.ListEventsResult{}, err
+		}
+
+		res.Events = append(res.Events, e)
+	}
+
+	return res, nil
+}
+
+func (s store) Grid(ctx context.Context, q domain.GridQuery) (domain.GridResult, error) {
+	def, ok := s.registry.Get(q.Event)
+	if !ok {
+		return domain.GridResult{}, fmt.Errorf("event %q: %w", q.Event, domain.ErrInvalidArgument)
+	}
+
+	args := []interface{}{q.Level, q.CellSize}
+	sqlQuery := fmt.Sprintf(`
+		SELECT floor(e.x / $2)::bigint AS x, floor(e.y / $2)::bigint AS y, COUNT(*) AS count
+		FROM %s e
+		JOIN levels l ON l.uuid = e.level_uuid
+		JOIN sessions s ON s.uuid = l.session_uuid
+		WHERE l.level = $1 AND e.x IS NOT NULL AND e.y IS NOT NULL`, def.TableName())
+
+	if def.TableName() == domain.SharedEventTable {
+		args = append(args, string(q.Event))
+		sqlQuery += fmt.Sprintf(" AND e.event = $%d", len(args))
+	}
+
+	if q.Cause != "" {
+		args = append(args, q.Cause)
+		sqlQuery += fmt.Sprintf(" AND e.cause = $%d", len(args))
+	}
+
+	if q.Game != "" {
+		args = append(args, q.Game)
+		sqlQuery += fmt.Sprintf(" AND s.game = $%d", len(args))
+	}
+
+	if !q.From.IsZero() {
+		args = append(args, q.From.UTC())
+		sqlQuery += fmt.Sprintf(" AND e.server_time >= $%d", len(args))
+	}
+
+	if !q.To.IsZero() {
+		args = append(args, q.To.UTC())
+		sqlQuery += fmt.Sprintf(" AND e.server_time < $%d", len(args))
+	}
+
+	sqlQuery += " GROUP BY 1, 2 ORDER BY 1, 2"
+
+	var rows []cell
+
+	if err := s.db.SelectContext(ctx, &rows, sqlQuery, args...); err != nil {
+		return domain.GridResult{}, fmt.Errorf("failed to select grid: %w", database.TranslateError(err))
+	}
+
+	res := domain.GridResult{
+		Cells: make([]domain.Cell, 0, len(rows)),
+	}
+
+	for _, r := range rows {
+		res.Cells = append(res.Cells, domain.Cell(r))
+	}
+
+	return res, nil
+}
+
+func (s store) SetBackground(ctx context.Context, q domain.SetBackgroundQuery) (domain.SetBackgroundResult, error) {
+	var updatedAt time.Time
+
+	err := s.db.GetContext(ctx, &updatedAt, `
+		INSERT INTO level_backgrounds (game, level, image, format, origin_x, origin_y, scale, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, now())
+		ON CONFLICT (game, level)
+		DO UPDATE SET image      = EXCLUDED.image,
+		              format     = EXCLUDED.format,
+		              origin_x   = EXCLUDED.origin_x,
+		              origin_y   = EXCLUDED.origin_y,
+		              scale      = EXCLUDED.scale,
+		              updated_at = EXCLUDED.updated_at
+		RETURNING updated_at
+	`, q.Game, q.Level, q.Image, q.Format, q.Origin.X, q.Origin.Y, q.Scale)
+	if err != nil {
+		return domain.SetBackgroundResult{}, fmt.Errorf("failed to upsert background: %w", database.TranslateError(err))
+	}
+
+	return domain.SetBackgroundResult{
+		UpdatedAt: updatedAt,
+	}, nil
+}
+
+type background struct {
+	Game      string    `db:"game"`
+	Level     int       `db:"level"`
+	Image     []byte    `db:"image"`
+	Format    string    `db:"format"`
+	OriginX   float64   `db:"origin_x"`
+	OriginY   float64   `db:"origin_y"`
+	Scale     float64   `db:"scale"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+func (s store) GetBackground(ctx context.Context, q domain.GetBackgroundQuery) (domain.GetBackgroundResult, error) {
+	var row background
+
+	image := "image"
+	if q.WithoutImage {
+		image = "NULL::bytea AS image"
+	}
+
+	err := s.db.GetContext(ctx, &row, `
+		SELECT game, level, `+image+`, format, origin_x, origin_y, scale, updated_at
+		FROM level_backgrounds
+		WHERE game = $1 AND level = $2
+	`, q.Game, q.Level)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.GetBackgroundResult{}, fmt.Errorf("background of level %d: %w", q.Level, domain.ErrNotFound)
+	}
+	if err != nil {
+		return domain.GetBackgroundResult{}, fmt.Errorf("failed to get background: %w", database.TranslateError(err))
+	}
+
+	return domain.GetBackgroundResult{
+		Background: domain.Background{
+			Game:      row.Game,
+			Level:     row.Level,
+			Image:     row.Image,
+			Format:    row.Format,
+			Origin:    domain.Position{X: row.OriginX, Y: row.OriginY},
+			Scale:     row.Scale,
+			UpdatedAt: row.UpdatedAt,
+		},
+	}, nil
+}
+
+type cell struct {
+	X     int `db:"x"`
+	Y     int `db:"y"`
+	Count int `db:"count"`
+}
+
+// levelInGame returns a condition which is true if the level whose UUID is
+// parameter uuidParam belongs to the game in parameter gameParam, or the game is empty.
+func levelInGame(uuidParam, gameParam int) string {
+	return fmt.Sprintf(`($%[2]d::text = '' OR EXISTS (
+		SELECT 1
+		FROM levels l
+		JOIN sessions s ON s.uuid = l.session_uuid
+		WHERE l.uuid = $%[1]d::uuid AND s.game = $%[2]d::text
+	))`, uuidParam, gameParam)
+}
+
+func (s store) Ping(ctx context.Context) error {
+	if err := s.db.PingContext(ctx); err != nil {
+		return fmt.Errorf("failed to ping database: %w", database.TranslateError(err))
+	}
+
+	return nil
+}
+
+// Close does nothing, the database is shared and closed by its owner.
+func (s store) Close() error {
+	return nil
+}
@@ -1,21 +1,61 @@
 package main
 
 import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"net/http"
+	"net/url"
 	"os"
+	"os/signal"
+	"strings"
+	"syscall"
 	"time"
 
+	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
 	"github.com/rs/zerolog"
 	"github.com/spf13/viper"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
+	analyticsservice "github.com/vediagames/onlooker/analytics/service"
+	analyticspostgresql "github.com/vediagames/onlooker/analytics/store/postgresql"
+	apikeyservice "github.com/vediagames/onlooker/apikey/service"
+	apikeymemory "github.com/vediagames/onlooker/apikey/store/memory"
+	apikeypostgresql "github.com/vediagames/onlooker/apikey/store/postgresql"
 	"github.com/vediagames/onlooker/controller"
+	"github.com/vediagames/onlooker/database"
 	_ "github.com/vediagames/onlooker/docs"
+	analyticsdomain "github.com/vediagames/onlooker/domain/analytics"
+	apikeydomain "github.com/vediagames/onlooker/domain/apikey"
+	gamedomain "github.com/vediagames/onlooker/domain/game"
+	leveldomain "github.com/vediagames/onlooker/domain/level"
+	playerdomain "github.com/vediagames/onlooker/domain/player"
+	sessiondomain "github.com/vediagames/onlooker/domain/session"
+	gameservice "github.com/vediagames/onlooker/game/service"
+	gamememory "github.com/vediagames/onlooker/game/store/memory"
+	gamepostgresql "github.com/vediagames/onlooker/game/store/postgresql"
 	levelservice "github.com/vediagames/onlooker/level/service"
+	levelinstrumented "github.com/vediagames/onlooker/level/store/instrumented"
+	levelmemory "github.com/vediagames/onlooker/level/store/memory"
 	levelpostgresql "github.com/vediagames/onlooker/level/store/postgresql"
+	"github.com/vediagames/onlooker/metadata"
+	"github.com/vediagames/onlooker/metrics"
+	playerservice "github.com/vediagames/onlooker/player/service"
+	playermemory "github.com/vediagames/onlooker/player/store/memory"
+	playerpostgresql "github.com/vediagames/onlooker/player/store/postgresql"
+	"github.com/vediagames/onlooker/ratelimit"
 	sessionservice "github.com/vediagames/onlooker/session/service"
+	sessioninstrumented "github.com/vediagames/onlooker/session/store/instrumented"
+	sessionmemory "github.com/vediagames/onlooker/session/store/memory"
 	sessionpostgresql "github.com/vediagames/onlooker/session/store/postgresql"
+	"github.com/vediagames/onlooker/signature"
 )
 
 // @title        Onlooker Rest API
@@ -37,6 +77,11 @@ import (
 // @name                        Authorization
 // @description                 Token to access the API.
 
+const (
+	storePostgreSQL = "postgresql"
+	storeMemory     = "memory"
+)
+
 func main() {
 	logger := zerolog.New(os.Stdout).With().
 		Timestamp().
@@ -52,133 +97,925 @@ func main() {
 
 	apiToken := viper.GetString("API_TOKEN")
 
-	if !viper.IsSet("PSQL_CONNECTION_STRING") {
-		logger.Fatal().Msg("PSQL_CONNECTION_STRING is not set")
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if !viper.IsSet("PSQL_CONNECTION_STRING") {
+			logger.Fatal().Msg("PSQL_CONNECTION_STRING is not set")
+		}
+
+		db, err := database.Open(newDatabaseConfig())
+		if err != nil {
+			logger.Fatal().Err(err).Msgf("failed to open database: %s", err)
+		}
+		defer db.Close()
+
+		migrator, err := newMigrator(db)
+		if err != nil {
+			logger.Fatal().Err(err).Msgf("failed to create migrator: %s", err)
+		}
+
+		if err := runMigrate(context.Background(), &logger, migrator, os.Args[2:]); err != nil {
+			logger.Fatal().Err(err).Msgf("failed to migrate: %s", err)
+		}
+
+		return
 	}
 
 	if !viper.IsSet("SECURE") {
 		logger.Fatal().Msg("SECURE is not set")
 	}
 
-	psqlConnString := viper.GetString("PSQL_CONNECTION_STRING")
+	viper.SetDefault("STORE", storePostgreSQL)
+
+	storeType := viper.GetString("STORE")
+
+	eventRegistry, err := newEventRegistry(viper.GetString("EVENT_DEFINITIONS_PATH"))
+	if err != nil {
+		logger.Fatal().Err(err).Msgf("failed to create event registry: %s", err)
+	}
+
+	var (
+		db             *sqlx.DB
+		levelStore     leveldomain.Store
+		sessionStore   sessiondomain.Store
+		analyticsStore analyticsdomain.Store
+		apiKeyStore    apikeydomain.Store
+		gameStore      gamedomain.Store
+		playerStore    playerdomain.Store
+	)
+
+	switch storeType {
+	case storePostgreSQL:
+		if !viper.IsSet("PSQL_CONNECTION_STRING") {
+			logger.Fatal().Msg("PSQL_CONNECTION_STRING is not set")
+		}
+
+		db, err = database.Open(newDatabaseConfig())
+		if err != nil {
+			logger.Fatal().Err(err).Msgf("failed to open database: %s", err)
+		}
+
+		if viper.GetBool("AUTO_MIGRATE") {
+			migrator, err := newMigrator(db)
+			if err != nil {
+				logger.Fatal().Err(err).Msgf("failed to create migrator: %s", err)
+			}
+
+			if err := runMigrate(context.Background(), &logger, migrator, []string{"up"}); err != nil {
+				logger.Fatal().Err(err).Msgf("failed to migrate: %s", err)
+			}
+		}
+
+		levelStore, err = levelpostgresql.New(levelpostgresql.Config{
+			DB:       db,
+			Registry: eventRegistry,
+		})
+		if err != nil {
+			logger.Fatal().Err(err).Msgf("failed to create level store: %s", err)
+		}
+
+		sessionStore, err = sessionpostgresql.New(sessionpostgresql.Config{
+			DB: db,
+		})
+		if err != nil {
+			logger.Fatal().Err(err).Msgf("failed to create session store: %s", err)
+		}
+
+		analyticsStore, err = analyticspostgresql.New(analyticspostgresql.Config{
+			DB: db,
+		})
+		if err != nil {
+			logger.Fatal().Err(err).Msgf("failed to create analytics store: %s", err)
+		}
+
+		apiKeyStore, err = apikeypostgresql.New(apikeypostgresql.Config{
+			DB: db,
+		})
+		if err != nil {
+			logger.Fatal().Err(err).Msgf("failed to create api key store: %s", err)
+		}
+
+		gameStore, err = gamepostgresql.New(gamepostgresql.Config{
+			DB: db,
+		})
+		if err != nil {
+			logger.Fatal().Err(err).Msgf("failed to create game store: %s", err)
+		}
+
+		playerStore, err = playerpostgresql.New(playerpostgresql.Config{
+			DB: db,
+		})
+		if err != nil {
+			logger.Fatal().Err(err).Msgf("failed to create player store: %s", err)
+		}
+	case storeMemory:
+		gameStore = gamememory.New()
+		playerStore = playermemory.New()
+
+		sessionStore, err = sessionmemory.New(sessionmemory.Config{
+			GameStore:   gameStore,
+			PlayerStore: playerStore,
+		})
+		if err != nil {
+			logger.Fatal().Err(err).Msgf("failed to create session store: %s", err)
+		}
+
+		apiKeyStore, err = apikeymemory.New(apikeymemory.Config{
+			GameStore: gameStore,
+		})
+		if err != nil {
+			logger.Fatal().Err(err).Msgf("failed to create api key store: %s", err)
+		}
+
+		levelStore, err = levelmemory.New(levelmemory.Config{
+			SessionStore: sessionStore,
+		})
+		if err != nil {
+			logger.Fatal().Err(err).Msgf("failed to create level store: %s", err)
+		}
+
+		logger.Warn().Msg("using in-memory stores, data is lost on restart and analytics are disabled")
+	default:
+		logger.Fatal().Msgf("invalid STORE %q, must be %q or %q", storeType, storePostgreSQL, storeMemory)
+	}
+
+	m, err := metrics.New()
+	if err != nil {
+		logger.Fatal().Err(err).Msgf("failed to create metrics: %s", err)
+	}
 
-	levelStore, err := levelpostgresql.New(levelpostgresql.Config{
-		ConnectionString: psqlConnString,
+	levelStore, err = levelinstrumented.New(levelinstrumented.Config{
+		Store:   levelStore,
+		Metrics: m,
 	})
 	if err != nil {
-		logger.Fatal().Err(err).Msgf("failed to create level store: %s", err)
+		logger.Fatal().Err(err).Msgf("failed to create instrumented level store: %s", err)
 	}
 
-	levelService, err := levelservice.New(levelservice.Config{
-		Store: levelStore,
+	sessionStore, err = sessioninstrumented.New(sessioninstrumented.Config{
+		Store:   sessionStore,
+		Metrics: m,
 	})
 	if err != nil {
-		logger.Fatal().Err(err).Msgf("failed to create level service: %s", err)
+		logger.Fatal().Err(err).Msgf("failed to create instrumented session store: %s", err)
 	}
 
-	sessionStore, err := sessionpostgresql.New(sessionpostgresql.Config{
-		ConnectionString: psqlConnString,
+	viper.SetDefault("METADATA_MAX_SIZE", metadata.DefaultMaxSize)
+	viper.SetDefault("METADATA_MAX_DEPTH", metadata.DefaultMaxDepth)
+
+	metadataLimits := metadata.Limits{
+		MaxSize:  viper.GetInt("METADATA_MAX_SIZE"),
+		MaxDepth: viper.GetInt("METADATA_MAX_DEPTH"),
+	}
+
+	levelService, err := levelservice.New(levelservice.Config{
+		Store:          levelStore,
+		Registry:       eventRegistry,
+		MetadataLimits: metadataLimits,
 	})
 	if err != nil {
-		logger.Fatal().Err(err).Msgf("failed to create session store: %s", err)
+		logger.Fatal().Err(err).Msgf("failed to create level service: %s", err)
 	}
 
 	sessionService, err := sessionservice.New(sessionservice.Config{
-		Store: sessionStore,
+		Store:                sessionStore,
+		GameStore:            gameStore,
+		MetadataLimits:       metadataLimits,
+		RejectUnknownOrigins: viper.GetBool("REJECT_UNKNOWN_ORIGINS"),
 	})
 	if err != nil {
 		logger.Fatal().Err(err).Msgf("failed to create session service: %s", err)
 	}
 
+	apiKeyService, err := apikeyservice.New(apikeyservice.Config{
+		Store: apiKeyStore,
+	})
+	if err != nil {
+		logger.Fatal().Err(err).Msgf("failed to create api key service: %s", err)
+	}
+
+	gameService, err := gameservice.New(gameservice.Config{
+		Store: gameStore,
+	})
+	if err != nil {
+		logger.Fatal().Err(err).Msgf("failed to create game service: %s", err)
+	}
+
+	playerService, err := playerservice.New(playerservice.Config{
+		Store: playerStore,
+	})
+	if err != nil {
+		logger.Fatal().Err(err).Msgf("failed to create player service: %s", err)
+	}
+
+	var analyticsService analyticsdomain.Service
+
+	if analyticsStore != nil {
+		analyticsService, err = analyticsservice.New(analyticsservice.Config{
+			Store: analyticsStore,
+		})
+		if err != nil {
+			logger.Fatal().Err(err).Msgf("failed to create analytics service: %s", err)
+		}
+	}
+
+	viper.SetDefault("SIGNATURE_WINDOW", 5*time.Minute)
+
+	verifier, err := signature.New(signature.Config{
+		Window: viper.GetDuration("SIGNATURE_WINDOW"),
+	})
+	if err != nil {
+		logger.Fatal().Err(err).Msgf("failed to create signature verifier: %s", err)
+	}
+
+	sessionsLimit, err := newRateLimit("SESSIONS",
+		ratelimit.Config{Rate: 1, Burst: 20},
+		ratelimit.Config{},
+	)
+	if err != nil {
+		logger.Fatal().Err(err).Msgf("failed to create sessions rate limit: %s", err)
+	}
+
+	levelsLimit, err := newRateLimit("LEVELS",
+		ratelimit.Config{Rate: 5, Burst: 50},
+		ratelimit.Config{Rate: 1, Burst: 10},
+	)
+	if err != nil {
+		logger.Fatal().Err(err).Msgf("failed to create levels rate limit: %s", err)
+	}
+
+	eventsLimit, err := newRateLimit("EVENTS",
+		ratelimit.Config{Rate: 50, Burst: 500},
+		ratelimit.Config{Rate: 10, Burst: 100},
+	)
+	if err != nil {
+		logger.Fatal().Err(err).Msgf("failed to create events rate limit: %s", err)
+	}
+
+	// Heatmaps are rendered on every request, so they are limited per IP like ingestion.
+	heatmapsLimit, err := newRateLimit("HEATMAPS",
+		ratelimit.Config{Rate: 1, Burst: 10},
+		ratelimit.Config{},
+	)
+	if err != nil {
+		logger.Fatal().Err(err).Msgf("failed to create heatmaps rate limit: %s", err)
+	}
+
 	c := controller.New(controller.Config{
-		LevelService:   levelService,
-		SessionService: sessionService,
+		LevelService:     levelService,
+		SessionService:   sessionService,
+		AnalyticsService: analyticsService,
+		APIKeyService:    apiKeyService,
+		GameService:      gameService,
+		PlayerService:    playerService,
+		Metrics:          m,
 	})
 
+	viper.SetDefault("CORS_ALLOWED_ORIGINS", "*")
+
+	corsMiddleware, err := newCORS(viper.GetString("CORS_ALLOWED_ORIGINS"))
+	if err != nil {
+		logger.Fatal().Err(err).Msgf("failed to create cors middleware: %s", err)
+	}
+
+	viper.SetDefault("TRUSTED_PROXIES", "")
+
 	r := gin.New()
+
+	// CF-Connecting-IP and X-Forwarded-For are only trusted from the proxies
+	// in TRUSTED_PROXIES, other requests are identified by their remote address.
+	var trustedProxies []string
+	for _, p := range strings.Split(viper.GetString("TRUSTED_PROXIES"), ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			trustedProxies = append(trustedProxies, p)
+		}
+	}
+
+	r.RemoteIPHeaders = []string{"CF-Connecting-IP", "X-Forwarded-For"}
+	if err := r.SetTrustedProxies(trustedProxies); err != nil {
+		logger.Fatal().Err(err).Msgf("failed to set trusted proxies: %s", err)
+	}
+
 	r.Use(gin.Recovery())
 	r.Use(loggerMiddleware(&logger))
-	r.Use(corsMiddleware())
+	r.Use(m.Middleware())
+	r.Use(corsMiddleware)
 
+	// Health checks are registered before the auth middleware so the
+	// orchestrator can reach them without a token.
+	r.GET("/healthz", c.Healthz)
+	r.GET("/readyz", c.Readyz)
+
+	// The auth middleware is part of the scope middlewares rather than used by
+	// the router, so that rate limits per IP run before it.
+	auth := principalMiddleware(apikeydomain.AdminPrincipal)
 	if viper.GetBool("SECURE") {
-		r.Use(authMiddleware(apiToken))
+		auth = authMiddleware(apiToken, apiKeyService)
 	}
 
+	ingest := chain(auth, requireScope(apikeydomain.ScopeIngest))
+	gamePolicy := gameMiddleware(verifier, gameService)
+	gameOrigin := gameMiddleware(nil, gameService)
+	read := chain(auth, requireScope(apikeydomain.ScopeRead))
+	admin := chain(auth, requireScope(apikeydomain.ScopeAdmin))
+
 	v1 := r.Group("/api/v1")
 
-	v1.GET("/hello", c.Hello)
+	v1.GET("/hello", auth, c.Hello)
 
 	session := v1.Group("/session")
-	session.POST("/", c.CreateSession)
+	session.POST("/", sessionsLimit.IP, ingest, gamePolicy, c.CreateSession)
+	session.GET("/:uuid", read, c.GetSession)
+	session.GET("/:uuid/levels", read, c.ListSessionLevels)
+	// Heartbeats are not signed, navigator.sendBeacon cannot set the signature
+	// headers. Browsers still send the Origin, which is checked.
+	session.POST("/:uuid/heartbeat", eventsLimit.IP, ingest, eventsLimit.Session(pathSession), gameOrigin, c.HeartbeatSession)
+	session.POST("/:uuid/end", eventsLimit.IP, ingest, eventsLimit.Session(pathSession), gameOrigin, c.EndSession)
+
+	player := v1.Group("/player")
+	player.GET("/:id", read, c.GetPlayer)
+	player.GET("/:id/sessions", read, c.ListPlayerSessions)
 
 	level := v1.Group("/level")
-	level.POST("/", c.CreateLevel)
+	level.POST("/", levelsLimit.IP, ingest, gamePolicy, levelsLimit.Session(bodySession), c.CreateLevel)
+	level.GET("/:uuid", read, c.GetLevel)
+	level.GET("/:uuid/events", read, c.ListLevelEvents)
+
+	levels := v1.Group("/levels")
+	levels.GET("/:level/grid", read, c.GetLevelGrid)
+	levels.GET("/:level/heatmap.png", heatmapsLimit.IP, read, c.GetLevelHeatmap)
+	levels.PUT("/:level/background", admin, c.SetLevelBackground)
 
-	levelEvent := level.Group("/event")
+	levelSessions := eventsLimit.Session(levelSession(levelService))
+
+	levelEvent := level.Group("/event", eventsLimit.IP, ingest, gamePolicy, levelSessions)
 	levelEvent.POST("/death", c.HandleEventDeath)
 	levelEvent.POST("/complete", c.HandleEventComplete)
 	levelEvent.POST("/grappling-hook-usage", c.HandleEventUseGrapplingHook)
+	levelEvent.POST("/:name", c.HandleEvent)
 
-	levelEvents := level.Group("/events")
+	levelEvents := level.Group("/events", eventsLimit.IP, ingest, gamePolicy, levelSessions)
 	levelEvents.POST("/death", c.HandleEventsDeath)
 	levelEvents.POST("/complete", c.HandleEventsComplete)
 	levelEvents.POST("/grappling-hook-usage", c.HandleEventsUseGrapplingHook)
 
-	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
+	// Mixed batches can create their sessions, they are only limited per IP.
+	v1.POST("/events", eventsLimit.IP, ingest, gamePolicy, c.HandleEvents)
+
+	if analyticsService != nil {
+		analytics := v1.Group("/analytics", read)
+		analytics.GET("/levels", c.GetLevelFunnel)
+		analytics.GET("/retention", c.GetRetention)
+	}
+
+	adminKeys := v1.Group("/admin/keys", admin)
+	adminKeys.POST("/", c.CreateAPIKey)
+	adminKeys.GET("/", c.ListAPIKeys)
+	adminKeys.DELETE("/:uuid", c.RevokeAPIKey)
+
+	adminGames := v1.Group("/admin/games", admin)
+	adminGames.POST("/", c.CreateGame)
+	adminGames.GET("/", c.ListGames)
+	adminGames.POST("/:slug/signing-secret", c.RotateGameSigningSecret)
+	adminGames.DELETE("/:slug/signing-secret", c.DisableGameSigning)
+	adminGames.PUT("/:slug/origins", c.SetGameAllowedOrigins)
+
+	r.GET("/swagger/*any", auth, ginSwagger.WrapHandler(swaggerFiles.Handler))
+	r.GET("/metrics", admin, gin.WrapH(m.Handler()))
+
+	viper.SetDefault("HTTP_READ_TIMEOUT", 10*time.Second)
+	viper.SetDefault("HTTP_WRITE_TIMEOUT", 30*time.Second)
+	viper.SetDefault("HTTP_IDLE_TIMEOUT", 60*time.Second)
+	viper.SetDefault("SHUTDOWN_TIMEOUT", 15*time.Second)
+
+	srv := &http.Server{
+		Addr:         fmt.Sprintf(":%s", port),
+		Handler:      r,
+		ReadTimeout:  viper.GetDuration("HTTP_READ_TIMEOUT"),
+		WriteTimeout: viper.GetDuration("HTTP_WRITE_TIMEOUT"),
+		IdleTimeout:  viper.GetDuration("HTTP_IDLE_TIMEOUT"),
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	viper.SetDefault("SESSION_TIMEOUT", 30*time.Minute)
+	viper.SetDefault("SESSION_TIMEOUT_INTERVAL", time.Minute)
+
+	sessionTimeout := viper.GetDuration("SESSION_TIMEOUT")
+	sessionTimeoutInterval := viper.GetDuration("SESSION_TIMEOUT_INTERVAL")
+
+	if sessionTimeout > 0 && sessionTimeoutInterval <= 0 {
+		logger.Fatal().Msgf("invalid SESSION_TIMEOUT_INTERVAL %s, must be above 0", sessionTimeoutInterval)
+	}
+
+	endIdleDone := make(chan struct{})
+
+	go func() {
+		defer close(endIdleDone)
+
+		if sessionTimeout > 0 {
+			endIdleSessions(ctx, &logger, sessionService, sessionTimeout, sessionTimeoutInterval)
+		}
+	}()
+
+	serverErr := make(chan error, 1)
+
+	go func() {
+		logger.Info().
+			Str("protocol", "http").
+			Str("port", port).
+			Msgf("starting server on port %s", port)
+
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			serverErr <- err
+		}
+	}()
+
+	select {
+	case err := <-serverErr:
+		logger.Fatal().Err(err).Msgf("failed to run the server: %s", err)
+	case <-ctx.Done():
+	}
+
+	stop()
+
+	shutdownTimeout := viper.GetDuration("SHUTDOWN_TIMEOUT")
+
+	logger.Info().Msgf("shutting down, waiting up to %s for in-flight requests", shutdownTimeout)
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		logger.Error().Err(err).Msgf("failed to shut down the server: %s", err)
+	}
+
+	<-endIdleDone
+
+	closeStores(&logger, map[string]interface{ Close() error }{
+		"level":     levelStore,
+		"session":   sessionStore,
+		"analytics": analyticsStore,
+		"api key":   apiKeyStore,
+		"game":      gameStore,
+		"player":    playerStore,
+	})
+
+	if db != nil {
+		if err := db.Close(); err != nil {
+			logger.Error().Err(err).Msgf("failed to close database: %s", err)
+		}
+	}
+
+	logger.Info().Msg("server stopped")
+}
+
+// endIdleSessions ends the sessions without a heartbeat within timeout
+// every interval, until ctx is done.
+func endIdleSessions(ctx context.Context, logger *zerolog.Logger, sessionService sessiondomain.Service, timeout, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		res, err := sessionService.EndIdle(ctx, sessiondomain.EndIdleRequest{
+			Timeout: timeout,
+		})
+		if err != nil {
+			logger.Error().Err(err).Msgf("failed to end idle sessions: %s", err)
+			continue
+		}
+
+		if res.Count > 0 {
+			logger.Info().Int("count", res.Count).Msgf("ended %d idle sessions", res.Count)
+		}
+	}
+}
+
+// closeStores closes the stores after the server stopped handling requests.
+func closeStores(logger *zerolog.Logger, stores map[string]interface{ Close() error }) {
+	for name, s := range stores {
+		if s == nil {
+			continue
+		}
+
+		if err := s.Close(); err != nil {
+			logger.Error().Err(err).Msgf("failed to close %s store: %s", name, err)
+		}
+	}
+}
+
+// newDatabaseConfig reads the configuration of the shared connection pool.
+func newDatabaseConfig() database.Config {
+	viper.SetDefault("DB_MAX_OPEN_CONNS", 20)
+	viper.SetDefault("DB_MAX_IDLE_CONNS", 10)
+	viper.SetDefault("DB_CONN_MAX_LIFETIME", 30*time.Minute)
+	viper.SetDefault("DB_STATEMENT_TIMEOUT", 30*time.Second)
+
+	return database.Config{
+		ConnectionString: viper.GetString("PSQL_CONNECTION_STRING"),
+		MaxOpenConns:     viper.GetInt("DB_MAX_OPEN_CONNS"),
+		MaxIdleConns:     viper.GetInt("DB_MAX_IDLE_CONNS"),
+		ConnMaxLifetime:  viper.GetDuration("DB_CONN_MAX_LIFETIME"),
+		StatementTimeout: viper.GetDuration("DB_STATEMENT_TIMEOUT"),
+	}
+}
+
+// rateLimit limits the requests of a route group per IP and per session.
+type rateLimit struct {
+	// IP limits requests per IP, it runs before the auth middleware so that
+	// throttled requests cost no key lookup.
+	IP      gin.HandlerFunc
+	session *ratelimit.Limiter
+}
+
+// Session limits requests per session, with the session resolved by key from
+// the request. Requests without a session are only limited per IP.
+func (l rateLimit) Session(key ratelimit.KeyFunc) gin.HandlerFunc {
+	return l.session.Middleware(key)
+}
+
+// newRateLimit creates the limits of a route group per IP and per session. The
+// defaults are overridden by the RATE_LIMIT_<GROUP>_{IP,SESSION}_{RATE,BURST} settings.
+func newRateLimit(group string, ip, session ratelimit.Config) (rateLimit, error) {
+	prefix := "RATE_LIMIT_" + group
+
+	viper.SetDefault(prefix+"_IP_RATE", ip.Rate)
+	viper.SetDefault(prefix+"_IP_BURST", ip.Burst)
+	viper.SetDefault(prefix+"_SESSION_RATE", session.Rate)
+	viper.SetDefault(prefix+"_SESSION_BURST", session.Burst)
+
+	ipLimiter, err := ratelimit.New(ratelimit.Config{
+		Rate:  viper.GetFloat64(prefix + "_IP_RATE"),
+		Burst: viper.GetInt(prefix + "_IP_BURST"),
+	})
+	if err != nil {
+		return rateLimit{}, fmt.Errorf("failed to create ip limiter: %w", err)
+	}
+
+	sessionLimiter, err := ratelimit.New(ratelimit.Config{
+		Rate:  viper.GetFloat64(prefix + "_SESSION_RATE"),
+		Burst: viper.GetInt(prefix + "_SESSION_BURST"),
+	})
+	if err != nil {
+		return rateLimit{}, fmt.Errorf("failed to create session limiter: %w", err)
+	}
+
+	return rateLimit{
+		IP: ipLimiter.Middleware(func(ctx *gin.Context) string {
+			return ctx.GetString(controller.KeyRealIP.String())
+		}),
+		session: sessionLimiter,
+	}, nil
+}
+
+// pathSession returns the session in the path of session routes.
+func pathSession(ctx *gin.Context) string {
+	id, err := uuid.Parse(ctx.Param("uuid"))
+	if err != nil {
+		return ""
+	}
+
+	return id.String()
+}
+
+// sessionBody holds the fields of ingestion bodies which point at their session,
+// directly or through their level.
+type sessionBody struct {
+	SessionUUID string `json:"session_uuid"`
+	UUID        string `json:"uuid"`
+	Requests    []struct {
+		UUID string `json:"uuid"`
+	} `json:"requests"`
+}
+
+// readSessionBody decodes the body of the request into a sessionBody and
+// restores it for the next handlers.
+func readSessionBody(ctx *gin.Context) (sessionBody, error) {
+	var body sessionBody
+
+	// One byte more than allowed is read to tell too large bodies apart, the
+	// rest is left for the next handlers.
+	b, err := io.ReadAll(io.LimitReader(ctx.Request.Body, signature.MaxBodySize+1))
+	ctx.Request.Body = readCloser{
+		Reader: io.MultiReader(bytes.NewReader(b), ctx.Request.Body),
+		Closer: ctx.Request.Body,
+	}
+	if err != nil {
+		return sessionBody{}, fmt.Errorf("failed to read body: %w", err)
+	}
+
+	if err := json.Unmarshal(b, &body); err != nil {
+		return sessionBody{}, fmt.Errorf("failed to decode body: %w", err)
+	}
+
+	return body, nil
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
 
-	logger.Info().
-		Str("protocol", "http").
-		Str("port", port).
-		Msgf("starting server on port %s", port)
+// bodySession returns the session_uuid of the body of level routes.
+func bodySession(ctx *gin.Context) string {
+	body, err := readSessionBody(ctx)
+	if err != nil {
+		return ""
+	}
 
-	if err := r.Run(fmt.Sprintf(":%s", port)); err != nil {
-		logger.Fatal().Err(err).Msgf("failed to run the server: %w", err)
+	id, err := uuid.Parse(body.SessionUUID)
+	if err != nil {
+		return ""
 	}
+
+	return id.String()
 }
 
-func authMiddleware(apiToken string) gin.HandlerFunc {
+// levelSession returns a KeyFunc resolving the session of the level of event
+// routes, of the first event for batches. Levels of other games have no session.
+func levelSession(levelService leveldomain.Service) ratelimit.KeyFunc {
+	return func(ctx *gin.Context) string {
+		body, err := readSessionBody(ctx)
+		if err != nil {
+			return ""
+		}
+
+		levelUUID := body.UUID
+		if len(body.Requests) > 0 {
+			levelUUID = body.Requests[0].UUID
+		}
+
+		if _, err := uuid.Parse(levelUUID); err != nil {
+			return ""
+		}
+
+		principal, _ := apikeydomain.PrincipalFromContext(ctx.Request.Context())
+
+		res, err := levelService.Get(ctx.Request.Context(), leveldomain.GetRequest{
+			UUID: levelUUID,
+			Game: principal.Game,
+		})
+		if err != nil {
+			return ""
+		}
+
+		return res.Level.SessionUUID
+	}
+}
+
+// newEventRegistry creates the level event registry with the definitions
+// from the JSON file at path, if set.
+func newEventRegistry(path string) (*leveldomain.Registry, error) {
+	if path == "" {
+		return leveldomain.NewRegistry()
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open event definitions: %w", err)
+	}
+	defer f.Close()
+
+	var definitions []leveldomain.EventDefinition
+	if err := json.NewDecoder(f).Decode(&definitions); err != nil {
+		return nil, fmt.Errorf("failed to decode event definitions: %w", err)
+	}
+
+	return leveldomain.NewRegistry(definitions...)
+}
+
+// accessTokenParam is the query parameter to pass the token in, if the
+// Authorization header cannot be set.
+const accessTokenParam = "access_token"
+
+// accessTokenRoutes are the routes accepting the token in accessTokenParam:
+// navigator.sendBeacon and links opened in a browser cannot set headers.
+var accessTokenRoutes = map[string]bool{
+	"/api/v1/session/:uuid/heartbeat":   true,
+	"/api/v1/session/:uuid/end":         true,
+	"/api/v1/levels/:level/heatmap.png": true,
+}
+
+// authMiddleware resolves the bearer token to the principal of the request.
+// The admin token is granted every scope, other tokens must be API keys.
+// Tokens in the query are only accepted on accessTokenRoutes and only for API
+// keys with a single scope other than admin, since URLs end up in logs and
+// browser histories.
+func authMiddleware(adminToken string, apiKeyService apikeydomain.Service) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		auth := ctx.GetHeader("Authorization")
+
+		var inQuery bool
+		if token := ctx.Query(accessTokenParam); auth == "" && token != "" {
+			if !accessTokenRoutes[ctx.FullPath()] {
+				ctx.AbortWithError(401, fmt.Errorf("%s is not accepted on this route", accessTokenParam))
+				return
+			}
+
+			auth = "Bearer " + token
+			inQuery = true
+		}
+
 		if auth == "" {
 			ctx.AbortWithError(401, fmt.Errorf("missing authorization header"))
 			return
 		}
 
-		if auth != fmt.Sprintf("Bearer %s", apiToken) {
+		token := strings.TrimPrefix(auth, "Bearer ")
+		if token == auth || token == "" {
+			ctx.AbortWithError(403, fmt.Errorf("invalid token"))
+			return
+		}
+
+		if adminToken != "" && subtle.ConstantTimeCompare([]byte(token), []byte(adminToken)) == 1 {
+			if inQuery {
+				ctx.AbortWithError(403, fmt.Errorf("admin token is not accepted in %s", accessTokenParam))
+				return
+			}
+
+			setPrincipal(ctx, apikeydomain.AdminPrincipal)
+			return
+		}
+
+		res, err := apiKeyService.Authenticate(ctx.Request.Context(), apikeydomain.AuthenticateRequest{
+			Key: token,
+		})
+		switch {
+		case errors.Is(err, apikeydomain.ErrNotFound), errors.Is(err, apikeydomain.ErrInvalidArgument):
 			ctx.AbortWithError(403, fmt.Errorf("invalid token"))
 			return
+		case err != nil:
+			ctx.AbortWithError(503, fmt.Errorf("failed to authenticate: %w", err))
+			return
+		}
+
+		if inQuery && (len(res.Principal.Scopes) != 1 || res.Principal.HasScope(apikeydomain.ScopeAdmin)) {
+			ctx.AbortWithError(403, fmt.Errorf("only keys with a single scope other than admin are accepted in %s", accessTokenParam))
+			return
+		}
+
+		setPrincipal(ctx, res.Principal)
+	}
+}
+
+// principalMiddleware sets the same principal on every request, for when auth is disabled.
+func principalMiddleware(principal apikeydomain.Principal) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		setPrincipal(ctx, principal)
+	}
+}
+
+func setPrincipal(ctx *gin.Context, principal apikeydomain.Principal) {
+	ctx.Request = ctx.Request.WithContext(
+		apikeydomain.ContextWithPrincipal(ctx.Request.Context(), principal),
+	)
+}
+
+// gameMiddleware enforces the policies of the game of the API key: browsers
+// must send the request from an allowed origin of the game and, if the game
+// has a signing secret, the request must be signed with it, see signature.Sign.
+// Without a verifier only the origin is checked.
+func gameMiddleware(verifier *signature.Verifier, gameService gamedomain.Service) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		principal, _ := apikeydomain.PrincipalFromContext(ctx.Request.Context())
+		if principal.Game == "" {
+			return
+		}
+
+		res, err := gameService.Get(ctx.Request.Context(), gamedomain.GetRequest{
+			Slug: principal.Game,
+		})
+		if err != nil {
+			ctx.AbortWithError(503, fmt.Errorf("failed to get game: %w", err))
+			return
+		}
+
+		ctx.Request = ctx.Request.WithContext(
+			gamedomain.ContextWithGame(ctx.Request.Context(), res.Game),
+		)
+
+		if origin := ctx.GetHeader("Origin"); origin != "" && !res.Game.AllowsOrigin(origin) {
+			ctx.AbortWithError(403, fmt.Errorf("origin %q is not allowed by game %q", origin, principal.Game))
+			return
+		}
+
+		if verifier == nil || res.Game.SigningSecret == "" {
+			return
+		}
+
+		// One byte more than allowed is read to tell too large bodies apart.
+		body, err := io.ReadAll(io.LimitReader(ctx.Request.Body, signature.MaxBodySize+1))
+		if err != nil {
+			ctx.AbortWithError(400, fmt.Errorf("failed to read body: %w", err))
+			return
+		}
+
+		if len(body) > signature.MaxBodySize {
+			ctx.AbortWithError(413, fmt.Errorf("body is larger than %d bytes", signature.MaxBodySize))
+			return
+		}
+
+		ctx.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		err = verifier.Verify(
+			res.Game.SigningSecret,
+			ctx.GetHeader(signature.HeaderTimestamp),
+			ctx.GetHeader(signature.HeaderSignature),
+			ctx.Request.Method,
+			ctx.Request.URL.Path,
+			body,
+		)
+		if err != nil {
+			ctx.AbortWithError(401, err)
+			return
 		}
 	}
 }
 
-func corsMiddleware() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
-		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
-		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT")
+// chain runs the handlers in order until one aborts.
+func chain(handlers ...gin.HandlerFunc) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		for _, h := range handlers {
+			if h(ctx); ctx.IsAborted() {
+				return
+			}
+		}
+	}
+}
 
-		if c.Request.Method == "OPTIONS" {
-			c.AbortWithStatus(204)
+// requireScope rejects requests whose principal was not granted scope.
+func requireScope(scope apikeydomain.Scope) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		principal, ok := apikeydomain.PrincipalFromContext(ctx.Request.Context())
+		if !ok || !principal.HasScope(scope) {
+			ctx.AbortWithError(403, fmt.Errorf("missing scope %q", scope))
 			return
 		}
 	}
 }
 
+// newCORS creates the CORS middleware for allowedOrigins, a comma separated
+// list of origins or * for any origin without credentials. The allowed
+// origins of a game are checked on top of it once the API key of the request
+// is known.
+func newCORS(allowedOrigins string) (gin.HandlerFunc, error) {
+	cfg := cors.Config{
+		AllowMethods: []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
+		AllowHeaders: []string{
+			"Origin", "Content-Type", "Content-Length", "Accept", "Accept-Encoding", "Authorization",
+			"Cache-Control", "X-CSRF-Token", "X-Requested-With",
+			controller.HeaderIdempotencyKey,
+			signature.HeaderTimestamp, signature.HeaderSignature,
+		},
+		ExposeHeaders:    []string{"Retry-After"},
+		AllowCredentials: true,
+		MaxAge:           12 * time.Hour,
+	}
+
+	for _, o := range strings.Split(allowedOrigins, ",") {
+		o = strings.TrimSpace(o)
+
+		switch o {
+		case "":
+		case "*":
+			// Any origin is only allowed without credentials, credentials are
+			// reserved for the origins listed explicitly.
+			cfg.AllowAllOrigins = true
+			cfg.AllowCredentials = false
+		default:
+			cfg.AllowOrigins = append(cfg.AllowOrigins, o)
+		}
+	}
+
+	if cfg.AllowAllOrigins {
+		cfg.AllowOrigins = nil
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+
+	return cors.New(cfg), nil
+}
+
 func loggerMiddleware(logger *zerolog.Logger) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		start := time.Now()
 
-		ip := ctx.GetHeader("CF-Connecting-IP")
-		if ip == "" {
-			ip = ctx.ClientIP()
-		}
+		// The client IP is only taken from headers set by trusted proxies.
+		ip := ctx.ClientIP()
 
 		ctx.Set(controller.KeyRealIP.String(), ip)
 
 		l := logger.With().
 			Str("method", ctx.Request.Method).
-			Str("url", ctx.Request.RequestURI).
+			Str("url", redactURL(ctx.Request.URL)).
 			Interface("client_ip", ip).
 			Logger()
 
@@ -196,9 +1033,24 @@ func loggerMiddleware(logger *zerolog.Logger) gin.HandlerFunc {
 		if ctx.Err() != nil {
 			l.Error().
 				Err(ctx.Err()).
-				Msgf("failed request: %w", ctx.Err())
+				Msgf("failed request: %s", ctx.Err())
 		}
 
 		l.Info().TimeDiff("latency", time.Now(), start).Msg("finished request")
 	}
 }
+
+// redactURL returns the request URI of u without the access token.
+func redactURL(u *url.URL) string {
+	q := u.Query()
+	if !q.Has(accessTokenParam) {
+		return u.RequestURI()
+	}
+
+	q.Set(accessTokenParam, "REDACTED")
+
+	redacted := *u
+	redacted.RawQuery = q.Encode()
+
+	return redacted.RequestURI()
+}
@@ -0,0 +1,82 @@
+package metadata
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/vediagames/onlooker/errutil"
+)
+
+const (
+	DefaultMaxSize  = 4096
+	DefaultMaxDepth = 3
+)
+
+// Limits restricts the client metadata which is stored with sessions, levels and events.
+type Limits struct {
+	// MaxSize is the maximum size of the JSON encoded metadata in bytes.
+	MaxSize int
+	// MaxDepth is the maximum nesting of objects and arrays, the metadata object itself included.
+	MaxDepth int
+}
+
+func (l Limits) Validate() error {
+	var err errutil.Error
+
+	if l.MaxSize <= 0 {
+		err.Add(fmt.Errorf("max size must be above 0"))
+	}
+
+	if l.MaxDepth <= 0 {
+		err.Add(fmt.Errorf("max depth must be above 0"))
+	}
+
+	return err.Err()
+}
+
+// Check returns an error if metadata exceeds the limits.
+func (l Limits) Check(metadata map[string]interface{}) error {
+	if len(metadata) == 0 {
+		return nil
+	}
+
+	var err errutil.Error
+
+	b, me := json.Marshal(metadata)
+	if me != nil {
+		return fmt.Errorf("metadata must be valid JSON: %w", me)
+	}
+
+	if len(b) > l.MaxSize {
+		err.Add(fmt.Errorf("metadata must be at most %d bytes, got %d", l.MaxSize, len(b)))
+	}
+
+	if d := depth(metadata); d > l.MaxDepth {
+		err.Add(fmt.Errorf("metadata must be nested at most %d levels, got %d", l.MaxDepth, d))
+	}
+
+	return err.Err()
+}
+
+func depth(v interface{}) int {
+	max := 0
+
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for _, e := range v {
+			if d := depth(e); d > max {
+				max = d
+			}
+		}
+	case []interface{}:
+		for _, e := range v {
+			if d := depth(e); d > max {
+				max = d
+			}
+		}
+	default:
+		return 0
+	}
+
+	return max + 1
+}
@@ -0,0 +1,150 @@
+package metrics
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "onlooker"
+
+// Metrics holds the collectors exposed on the metrics endpoint.
+// A nil *Metrics is valid and records nothing.
+type Metrics struct {
+	registry *prometheus.Registry
+
+	requests        *prometheus.CounterVec
+	requestDuration *prometheus.HistogramVec
+	eventsIngested  *prometheus.CounterVec
+	batchSize       *prometheus.HistogramVec
+	storeDuration   *prometheus.HistogramVec
+	storeErrors     *prometheus.CounterVec
+}
+
+func New() (*Metrics, error) {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "requests_total",
+			Help:      "Number of handled HTTP requests.",
+		}, []string{"method", "route", "status"}),
+		requestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "request_duration_seconds",
+			Help:      "Latency of handled HTTP requests.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+		eventsIngested: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "level",
+			Name:      "events_ingested_total",
+			Help:      "Number of stored level events.",
+		}, []string{"event"}),
+		batchSize: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "batch_size",
+			Help:      "Number of items in batch requests.",
+			Buckets:   prometheus.ExponentialBuckets(1, 2, 12),
+		}, []string{"route"}),
+		storeDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "store",
+			Name:      "query_duration_seconds",
+			Help:      "Latency of store queries.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"store", "method"}),
+		storeErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "store",
+			Name:      "query_errors_total",
+			Help:      "Number of failed store queries.",
+		}, []string{"store", "method"}),
+	}
+
+	for _, c := range []prometheus.Collector{
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.requests,
+		m.requestDuration,
+		m.eventsIngested,
+		m.batchSize,
+		m.storeDuration,
+		m.storeErrors,
+	} {
+		if err := m.registry.Register(c); err != nil {
+			return nil, fmt.Errorf("failed to register collector: %w", err)
+		}
+	}
+
+	return m, nil
+}
+
+// Handler serves the collected metrics.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// Middleware records the count and latency of requests per route and status.
+// Requests which match no route are recorded with an empty route.
+func (m *Metrics) Middleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if m == nil {
+			return
+		}
+
+		start := time.Now()
+
+		ctx.Next()
+
+		labels := prometheus.Labels{
+			"method": ctx.Request.Method,
+			"route":  ctx.FullPath(),
+			"status": strconv.Itoa(ctx.Writer.Status()),
+		}
+
+		m.requests.With(labels).Inc()
+		m.requestDuration.With(labels).Observe(time.Since(start).Seconds())
+	}
+}
+
+// ObserveBatchSize records the number of items in a batch request to route.
+func (m *Metrics) ObserveBatchSize(route string, size int) {
+	if m == nil {
+		return
+	}
+
+	m.batchSize.WithLabelValues(route).Observe(float64(size))
+}
+
+// AddEventsIngested adds count stored events of type event.
+func (m *Metrics) AddEventsIngested(event string, count int) {
+	if m == nil || count == 0 {
+		return
+	}
+
+	m.eventsIngested.WithLabelValues(event).Add(float64(count))
+}
+
+// ObserveStoreQuery records the latency of a store query started at start,
+// and counts it as failed if err is set.
+func (m *Metrics) ObserveStoreQuery(store, method string, start time.Time, err error) {
+	if m == nil {
+		return
+	}
+
+	m.storeDuration.WithLabelValues(store, method).Observe(time.Since(start).Seconds())
+
+	if err != nil {
+		m.storeErrors.WithLabelValues(store, method).Inc()
+	}
+}
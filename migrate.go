@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog"
+	"github.com/vediagames/onlooker/database"
+	"github.com/vediagames/onlooker/db"
+)
+
+// newMigrator creates a migrator for the embedded schema.
+func newMigrator(sqlDB *sqlx.DB) (*database.Migrator, error) {
+	return database.NewMigrator(database.MigratorConfig{
+		DB:  sqlDB,
+		FS:  db.Schema,
+		Dir: "schema",
+	})
+}
+
+// runMigrate runs the migrate subcommand: migrate up|down [n]|status.
+func runMigrate(ctx context.Context, logger *zerolog.Logger, migrator *database.Migrator, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: migrate up|down [n]|status")
+	}
+
+	switch args[0] {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		for _, m := range applied {
+			logger.Info().Uint64("version", m.Version).Msgf("applied %d_%s", m.Version, m.Name)
+		}
+		if err != nil {
+			return err
+		}
+
+		logger.Info().Msgf("applied %d migrations", len(applied))
+	case "down":
+		n := 1
+
+		if len(args) > 1 {
+			var err error
+			if n, err = strconv.Atoi(args[1]); err != nil || n < 1 {
+				return fmt.Errorf("invalid number of migrations: %q", args[1])
+			}
+		}
+
+		rolledBack, err := migrator.Down(ctx, n)
+		for _, m := range rolledBack {
+			logger.Info().Uint64("version", m.Version).Msgf("rolled back %d_%s", m.Version, m.Name)
+		}
+		if err != nil {
+			return err
+		}
+
+		logger.Info().Msgf("rolled back %d migrations", len(rolledBack))
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+
+		for _, s := range statuses {
+			logger.Info().
+				Uint64("version", s.Version).
+				Bool("applied", s.Applied).
+				Msgf("%d_%s", s.Version, s.Name)
+		}
+	default:
+		return fmt.Errorf("unknown migrate command: %q", args[0])
+	}
+
+	return nil
+}
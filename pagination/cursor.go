@@ -0,0 +1,107 @@
+package pagination
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/vediagames/onlooker/errutil"
+)
+
+const (
+	DefaultLimit = 50
+	MaxLimit     = 500
+)
+
+// Cursor points at the last row of a page ordered by server time and UUID.
+type Cursor struct {
+	ServerTime time.Time
+	UUID       string
+}
+
+func (c Cursor) IsZero() bool {
+	return c.ServerTime.IsZero() && c.UUID == ""
+}
+
+// Validate checks that a cursor which is not zero points at a row, so it can be
+// compared with the columns of the row.
+func (c Cursor) Validate() error {
+	if c.IsZero() {
+		return nil
+	}
+
+	var err errutil.Error
+
+	if c.ServerTime.IsZero() {
+		err.Add(fmt.Errorf("cursor server time must be set"))
+	}
+
+	if _, ve := uuid.Parse(c.UUID); ve != nil {
+		err.Add(fmt.Errorf("cursor uuid %q is not a valid uuid", c.UUID))
+	}
+
+	return err.Err()
+}
+
+func (c Cursor) String() string {
+	if c.IsZero() {
+		return ""
+	}
+
+	return base64.RawURLEncoding.EncodeToString(
+		[]byte(fmt.Sprintf("%s|%s", c.ServerTime.UTC().Format(time.RFC3339Nano), c.UUID)),
+	)
+}
+
+// Parse decodes a cursor created by Cursor.String. An empty string is a zero cursor.
+func Parse(s string) (Cursor, error) {
+	if s == "" {
+		return Cursor{}, nil
+	}
+
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return Cursor{}, fmt.Errorf("invalid cursor: %w", err)
+	}
+
+	parts := strings.SplitN(string(b), "|", 2)
+	if len(parts) != 2 {
+		return Cursor{}, fmt.Errorf("invalid cursor: %q", s)
+	}
+
+	t, err := time.Parse(time.RFC3339Nano, parts[0])
+	if err != nil {
+		return Cursor{}, fmt.Errorf("invalid cursor: %w", err)
+	}
+
+	return Cursor{
+		ServerTime: t,
+		UUID:       parts[1],
+	}, nil
+}
+
+// ValidateLimit checks that limit is within 0 and MaxLimit.
+func ValidateLimit(limit int) error {
+	var err errutil.Error
+
+	if limit < 0 {
+		err.Add(fmt.Errorf("limit must be above 0"))
+	}
+
+	if limit > MaxLimit {
+		err.Add(fmt.Errorf("limit must be at most %d", MaxLimit))
+	}
+
+	return err.Err()
+}
+
+// Limit returns limit or DefaultLimit if it is not set.
+func Limit(limit int) int {
+	if limit == 0 {
+		return DefaultLimit
+	}
+
+	return limit
+}
@@ -0,0 +1,18 @@
+package service
+
+import (
+	"context"
+
+	playerdomain "github.com/vediagames/onlooker/domain/player"
+)
+
+type mock struct{}
+
+func NewMock() playerdomain.Service {
+	return &mock{}
+}
+
+func (m mock) Get(ctx context.Context, request playerdomain.GetRequest) (playerdomain.GetResponse, error) {
+	//TODO implement me
+	panic("implement me")
+}
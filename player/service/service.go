@@ -0,0 +1,56 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	domain "github.com/vediagames/onlooker/domain/player"
+	"github.com/vediagames/onlooker/errutil"
+)
+
+type service struct {
+	store domain.Store
+}
+
+type Config struct {
+	Store domain.Store
+}
+
+func (c Config) Validate() error {
+	var err errutil.Error
+
+	if c.Store == nil {
+		err.Add(fmt.Errorf("store is empty"))
+	}
+
+	return err.Err()
+}
+
+func New(cfg Config) (domain.Service, error) {
+	if ve := cfg.Validate(); ve != nil {
+		return nil, fmt.Errorf("invalid config: %w", ve)
+	}
+
+	return &service{
+		store: cfg.Store,
+	}, nil
+}
+
+func (s service) Get(ctx context.Context, req domain.GetRequest) (domain.GetResponse, error) {
+	if err := req.Validate(); err != nil {
+		return domain.GetResponse{}, fmt.Errorf("invalid request: %w", errutil.WithKind(err, domain.ErrInvalidArgument))
+	}
+
+	getRes, err := s.store.Get(ctx, domain.GetQuery(req))
+	if err != nil {
+		return domain.GetResponse{}, fmt.Errorf("failed to get: %w", err)
+	}
+
+	res := domain.GetResponse(getRes)
+
+	if err := res.Validate(); err != nil {
+		return domain.GetResponse{}, fmt.Errorf("invalid response: %w", err)
+	}
+
+	return res, nil
+}
@@ -0,0 +1,71 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	domain "github.com/vediagames/onlooker/domain/player"
+)
+
+type store struct {
+	mu sync.RWMutex
+	// players maps games to the players of the game by ID.
+	players map[string]map[string]domain.Player
+}
+
+func New() domain.Store {
+	return &store{
+		players: make(map[string]map[string]domain.Player),
+	}
+}
+
+func (s *store) Touch(ctx context.Context, q domain.TouchQuery) (domain.TouchResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.players[q.Game]; !ok {
+		s.players[q.Game] = make(map[string]domain.Player)
+	}
+
+	now := time.Now().UTC()
+
+	player, ok := s.players[q.Game][q.ID]
+	if !ok {
+		player = domain.Player{
+			Game:        q.Game,
+			ID:          q.ID,
+			FirstSeenAt: now,
+		}
+	}
+
+	player.LastSeenAt = now
+	s.players[q.Game][q.ID] = player
+
+	return domain.TouchResult{
+		Player: player,
+	}, nil
+}
+
+func (s *store) Get(ctx context.Context, q domain.GetQuery) (domain.GetResult, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	player, ok := s.players[q.Game][q.ID]
+	if !ok {
+		return domain.GetResult{}, fmt.Errorf("player %q: %w", q.ID, domain.ErrNotFound)
+	}
+
+	return domain.GetResult{
+		Player: player,
+	}, nil
+}
+
+func (s *store) Ping(ctx context.Context) error {
+	return nil
+}
+
+func (s *store) Close() error {
+	return nil
+}
@@ -0,0 +1,33 @@
+package store
+
+import (
+	"context"
+
+	domain "github.com/vediagames/onlooker/domain/player"
+)
+
+type mock struct{}
+
+func NewMock() domain.Store {
+	return &mock{}
+}
+
+func (s mock) Touch(ctx context.Context, q domain.TouchQuery) (domain.TouchResult, error) {
+	//TODO implement me
+	panic("implement me")
+}
+
+func (s mock) Get(ctx context.Context, q domain.GetQuery) (domain.GetResult, error) {
+	//TODO implement me
+	panic("implement me")
+}
+
+func (s mock) Ping(ctx context.Context) error {
+	//TODO implement me
+	panic("implement me")
+}
+
+func (s mock) Close() error {
+	//TODO implement me
+	panic("implement me")
+}
@@ -0,0 +1,101 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/vediagames/onlooker/database"
+	domain "github.com/vediagames/onlooker/domain/player"
+	"github.com/vediagames/onlooker/errutil"
+)
+
+type store struct {
+	db *sqlx.DB
+}
+
+type Config struct {
+	DB *sqlx.DB
+}
+
+func (c Config) Validate() error {
+	var err errutil.Error
+
+	if c.DB == nil {
+		err.Add(fmt.Errorf("db is empty"))
+	}
+
+	return err.Err()
+}
+
+func New(cfg Config) (domain.Store, error) {
+	if ve := cfg.Validate(); ve != nil {
+		return nil, fmt.Errorf("invalid config: %w", ve)
+	}
+
+	return &store{
+		db: cfg.DB,
+	}, nil
+}
+
+type player struct {
+	Game        string    `db:"game"`
+	ID          string    `db:"id"`
+	FirstSeenAt time.Time `db:"first_seen_at"`
+	LastSeenAt  time.Time `db:"last_seen_at"`
+}
+
+func (s store) Touch(ctx context.Context, q domain.TouchQuery) (domain.TouchResult, error) {
+	var row player
+
+	err := s.db.GetContext(ctx, &row, `
+		INSERT INTO players (game, id, first_seen_at, last_seen_at)
+		VALUES ($1, $2, now(), now())
+		ON CONFLICT (game, id)
+		DO UPDATE SET last_seen_at = EXCLUDED.last_seen_at
+		RETURNING game, id, first_seen_at, last_seen_at
+	`, q.Game, q.ID)
+	if err != nil {
+		return domain.TouchResult{}, fmt.Errorf("failed to touch player: %w", database.TranslateError(err))
+	}
+
+	return domain.TouchResult{
+		Player: domain.Player(row),
+	}, nil
+}
+
+func (s store) Get(ctx context.Context, q domain.GetQuery) (domain.GetResult, error) {
+	var row player
+
+	err := s.db.GetContext(ctx, &row, `
+		SELECT game, id, first_seen_at, last_seen_at
+		FROM players
+		WHERE game = $1 AND id = $2
+	`, q.Game, q.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.GetResult{}, fmt.Errorf("player %q: %w", q.ID, domain.ErrNotFound)
+	}
+	if err != nil {
+		return domain.GetResult{}, fmt.Errorf("failed to get player: %w", database.TranslateError(err))
+	}
+
+	return domain.GetResult{
+		Player: domain.Player(row),
+	}, nil
+}
+
+func (s store) Ping(ctx context.Context) error {
+	if err := s.db.PingContext(ctx); err != nil {
+		return fmt.Errorf("failed to ping database: %w", database.TranslateError(err))
+	}
+
+	return nil
+}
+
+// Close does nothing, the database is shared and closed by its owner.
+func (s store) Close() error {
+	return nil
+}
@@ -0,0 +1,136 @@
+package ratelimit
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vediagames/onlooker/errutil"
+)
+
+// pruneInterval is how often buckets which are full again are forgotten.
+const pruneInterval = time.Minute
+
+type Config struct {
+	// Rate is the number of requests per second allowed per key, 0 disables the limit.
+	Rate float64
+	// Burst is the number of requests allowed per key at once.
+	Burst int
+}
+
+func (c Config) Validate() error {
+	var err errutil.Error
+
+	if c.Rate < 0 {
+		err.Add(fmt.Errorf("rate must not be negative"))
+	}
+
+	if c.Rate > 0 && c.Burst < 1 {
+		err.Add(fmt.Errorf("burst must be at least 1"))
+	}
+
+	return err.Err()
+}
+
+// Limiter is a token bucket per key, kept in memory. Every instance of the
+// server limits on its own.
+type Limiter struct {
+	rate  float64
+	burst float64
+	now   func() time.Time
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastPrune time.Time
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+func New(cfg Config) (*Limiter, error) {
+	if ve := cfg.Validate(); ve != nil {
+		return nil, fmt.Errorf("invalid config: %w", ve)
+	}
+
+	return &Limiter{
+		rate:    cfg.Rate,
+		burst:   float64(cfg.Burst),
+		now:     time.Now,
+		buckets: make(map[string]*bucket),
+	}, nil
+}
+
+// Allow takes a token from the bucket of key. If the bucket is empty, it
+// returns false and how long until the next token.
+func (l *Limiter) Allow(key string) (bool, time.Duration) {
+	if l.rate == 0 {
+		return true, 0
+	}
+
+	now := l.now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.prune(now)
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: l.burst, last: now}
+		l.buckets[key] = b
+	}
+
+	b.tokens = math.Min(l.burst, b.tokens+now.Sub(b.last).Seconds()*l.rate)
+	b.last = now
+
+	if b.tokens < 1 {
+		return false, time.Duration((1 - b.tokens) / l.rate * float64(time.Second))
+	}
+
+	b.tokens--
+
+	return true, 0
+}
+
+// prune forgets the buckets which are full again, at most once per interval.
+// It must be called with the lock held.
+func (l *Limiter) prune(now time.Time) {
+	if now.Sub(l.lastPrune) < pruneInterval {
+		return
+	}
+
+	for key, b := range l.buckets {
+		if b.tokens+now.Sub(b.last).Seconds()*l.rate >= l.burst {
+			delete(l.buckets, key)
+		}
+	}
+
+	l.lastPrune = now
+}
+
+// KeyFunc returns the key a request is limited on. Requests with an empty key are not limited.
+type KeyFunc func(ctx *gin.Context) string
+
+// Middleware rejects requests over the limit of their key with 429 and a
+// Retry-After header in seconds.
+func (l *Limiter) Middleware(key KeyFunc) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		k := key(ctx)
+		if k == "" {
+			return
+		}
+
+		ok, retryAfter := l.Allow(k)
+		if ok {
+			return
+		}
+
+		ctx.Header("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+		ctx.AbortWithError(429, fmt.Errorf("rate limit of %q exceeded", k))
+	}
+}
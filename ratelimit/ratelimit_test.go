@@ -0,0 +1,182 @@
+package ratelimit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestLimiter_Allow(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+
+	type call struct {
+		key            string
+		after          time.Duration
+		wantOK         bool
+		wantRetryAfter time.Duration
+	}
+
+	tests := []struct {
+		name  string
+		cfg   Config
+		calls []call
+	}{
+		{
+			name: "burst",
+			cfg:  Config{Rate: 1, Burst: 2},
+			calls: []call{
+				{key: "a", wantOK: true},
+				{key: "a", wantOK: true},
+				{key: "a", wantOK: false, wantRetryAfter: time.Second},
+			},
+		},
+		{
+			name: "refill",
+			cfg:  Config{Rate: 2, Burst: 1},
+			calls: []call{
+				{key: "a", wantOK: true},
+				{key: "a", wantOK: false, wantRetryAfter: 500 * time.Millisecond},
+				{key: "a", after: 250 * time.Millisecond, wantOK: false, wantRetryAfter: 250 * time.Millisecond},
+				{key: "a", after: 500 * time.Millisecond, wantOK: true},
+			},
+		},
+		{
+			name: "refill up to burst",
+			cfg:  Config{Rate: 1, Burst: 2},
+			calls: []call{
+				{key: "a", wantOK: true},
+				{key: "a", after: time.Hour, wantOK: true},
+				{key: "a", wantOK: true},
+				{key: "a", wantOK: false, wantRetryAfter: time.Second},
+			},
+		},
+		{
+			name: "keys",
+			cfg:  Config{Rate: 1, Burst: 1},
+			calls: []call{
+				{key: "a", wantOK: true},
+				{key: "a", wantOK: false, wantRetryAfter: time.Second},
+				{key: "b", wantOK: true},
+			},
+		},
+		{
+			name: "disabled",
+			cfg:  Config{},
+			calls: []call{
+				{key: "a", wantOK: true},
+				{key: "a", wantOK: true},
+				{key: "a", wantOK: true},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := newLimiter(t, tt.cfg)
+
+			now := start
+			l.now = func() time.Time { return now }
+
+			for i, c := range tt.calls {
+				now = now.Add(c.after)
+
+				ok, retryAfter := l.Allow(c.key)
+				if ok != c.wantOK || retryAfter != c.wantRetryAfter {
+					t.Fatalf("call %d: got %t, %v, want %t, %v", i, ok, retryAfter, c.wantOK, c.wantRetryAfter)
+				}
+			}
+		})
+	}
+}
+
+func TestLimiter_Allow_Prune(t *testing.T) {
+	l := newLimiter(t, Config{Rate: 1, Burst: 1})
+
+	now := time.Unix(1700000000, 0)
+	l.now = func() time.Time { return now }
+
+	l.Allow("a")
+
+	now = now.Add(pruneInterval)
+	l.Allow("b")
+
+	if _, ok := l.buckets["a"]; ok {
+		t.Fatalf("got bucket of a after it was full again, want it pruned")
+	}
+}
+
+func TestLimiter_Middleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		key            string
+		requests       int
+		wantCode       int
+		wantRetryAfter string
+	}{
+		{name: "within burst", key: "a", requests: 2, wantCode: 200},
+		{name: "over burst", key: "a", requests: 3, wantCode: 429, wantRetryAfter: "2"},
+		{name: "empty key", key: "", requests: 3, wantCode: 200},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := newLimiter(t, Config{Rate: 0.5, Burst: 2})
+
+			r := gin.New()
+			r.GET("/", l.Middleware(func(*gin.Context) string { return tt.key }), func(ctx *gin.Context) {
+				ctx.Status(200)
+			})
+
+			var w *httptest.ResponseRecorder
+			for i := 0; i < tt.requests; i++ {
+				w = httptest.NewRecorder()
+				r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+			}
+
+			if w.Code != tt.wantCode {
+				t.Fatalf("got code %d, want %d", w.Code, tt.wantCode)
+			}
+
+			if got := w.Header().Get("Retry-After"); got != tt.wantRetryAfter {
+				t.Fatalf("got Retry-After %q, want %q", got, tt.wantRetryAfter)
+			}
+		})
+	}
+}
+
+func TestConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     Config
+		wantErr bool
+	}{
+		{name: "valid", cfg: Config{Rate: 1, Burst: 1}},
+		{name: "disabled", cfg: Config{}},
+		{name: "negative rate", cfg: Config{Rate: -1, Burst: 1}, wantErr: true},
+		{name: "no burst", cfg: Config{Rate: 1}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.cfg.Validate(); (err != nil) != tt.wantErr {
+				t.Fatalf("got %v, want error %t", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func newLimiter(t *testing.T, cfg Config) *Limiter {
+	t.Helper()
+
+	l, err := New(cfg)
+	if err != nil {
+		t.Fatalf("failed to create limiter: %v", err)
+	}
+
+	return l
+}
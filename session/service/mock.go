@@ -16,3 +16,33 @@ func (m mock) Create(ctx context.Context, request sessiondomain.CreateRequest) (
 	//TODO implement me
 	panic("implement me")
 }
+
+func (m mock) Get(ctx context.Context, request sessiondomain.GetRequest) (sessiondomain.GetResponse, error) {
+	//TODO implement me
+	panic("implement me")
+}
+
+func (m mock) List(ctx context.Context, request sessiondomain.ListRequest) (sessiondomain.ListResponse, error) {
+	//TODO implement me
+	panic("implement me")
+}
+
+func (m mock) Heartbeat(ctx context.Context, request sessiondomain.HeartbeatRequest) (sessiondomain.HeartbeatResponse, error) {
+	//TODO implement me
+	panic("implement me")
+}
+
+func (m mock) End(ctx context.Context, request sessiondomain.EndRequest) (sessiondomain.EndResponse, error) {
+	//TODO implement me
+	panic("implement me")
+}
+
+func (m mock) EndIdle(ctx context.Context, request sessiondomain.EndIdleRequest) (sessiondomain.EndIdleResponse, error) {
+	//TODO implement me
+	panic("implement me")
+}
+
+func (m mock) Ping(ctx context.Context) error {
+	//TODO implement me
+	panic("implement me")
+}
@@ -4,16 +4,29 @@ import (
 	"context"
 	"fmt"
 
+	gamedomain "github.com/vediagames/onlooker/domain/game"
 	domain "github.com/vediagames/onlooker/domain/session"
 	"github.com/vediagames/onlooker/errutil"
+	"github.com/vediagames/onlooker/metadata"
+	"github.com/vediagames/onlooker/pagination"
 )
 
 type service struct {
-	store domain.Store
+	store                domain.Store
+	gameStore            gamedomain.Store
+	metadataLimits       metadata.Limits
+	rejectUnknownOrigins bool
 }
 
 type Config struct {
 	Store domain.Store
+	// GameStore is used to check the url of sessions against the allowed origins
+	// of their game, unless the game is in the context, see gamedomain.ContextWithGame.
+	GameStore      gamedomain.Store
+	MetadataLimits metadata.Limits
+	// RejectUnknownOrigins rejects sessions whose url is not an allowed
+	// origin of their game, otherwise they are flagged.
+	RejectUnknownOrigins bool
 }
 
 func (c Config) Validate() error {
@@ -23,6 +36,14 @@ func (c Config) Validate() error {
 		err.Add(fmt.Errorf("store is empty"))
 	}
 
+	if c.GameStore == nil {
+		err.Add(fmt.Errorf("game store is empty"))
+	}
+
+	if ve := c.MetadataLimits.Validate(); ve != nil {
+		err.Add(fmt.Errorf("invalid metadata limits: %w", ve))
+	}
+
 	return err.Err()
 }
 
@@ -32,16 +53,49 @@ func New(cfg Config) (domain.Service, error) {
 	}
 
 	return &service{
-		store: cfg.Store,
+		store:                cfg.Store,
+		gameStore:            cfg.GameStore,
+		metadataLimits:       cfg.MetadataLimits,
+		rejectUnknownOrigins: cfg.RejectUnknownOrigins,
 	}, nil
 }
 
 func (s service) Create(ctx context.Context, req domain.CreateRequest) (domain.CreateResponse, error) {
 	if err := req.Validate(); err != nil {
-		return domain.CreateResponse{}, fmt.Errorf("invalid request: %w", err)
+		return domain.CreateResponse{}, fmt.Errorf("invalid request: %w", errutil.WithKind(err, domain.ErrInvalidArgument))
+	}
+
+	if err := s.metadataLimits.Check(req.Metadata); err != nil {
+		return domain.CreateResponse{}, fmt.Errorf("invalid request: %w", errutil.WithKind(err, domain.ErrInvalidArgument))
+	}
+
+	game, err := s.game(ctx, req.Game)
+	if err != nil {
+		return domain.CreateResponse{}, fmt.Errorf("failed to get game: %w", err)
 	}
 
-	newRes, err := s.store.Insert(ctx, domain.InsertQuery(req))
+	// Sessions are only checked if the game restricts its origins.
+	var unknownOrigin bool
+	if len(game.AllowedOrigins) > 0 {
+		origin, err := gamedomain.Origin(req.URL)
+		unknownOrigin = err != nil || !game.AllowsOrigin(origin)
+	}
+
+	if unknownOrigin && s.rejectUnknownOrigins {
+		return domain.CreateResponse{}, fmt.Errorf("url %q is not an allowed origin of game %q: %w", req.URL, req.Game, domain.ErrPermissionDenied)
+	}
+
+	newRes, err := s.store.Insert(ctx, domain.InsertQuery{
+		Game:           req.Game,
+		ClientTime:     req.ClientTime,
+		IP:             req.IP,
+		URL:            req.URL,
+		Timezone:       req.Timezone,
+		Metadata:       req.Metadata,
+		IdempotencyKey: req.IdempotencyKey,
+		PlayerID:       req.PlayerID,
+		UnknownOrigin:  unknownOrigin,
+	})
 	if err != nil {
 		return domain.CreateResponse{}, fmt.Errorf("failed to insert: %w", err)
 	}
@@ -54,3 +108,104 @@ func (s service) Create(ctx context.Context, req domain.CreateRequest) (domain.C
 
 	return res, nil
 }
+
+// game returns the game slug, which is taken from ctx if the request already
+// fetched it.
+func (s service) game(ctx context.Context, slug string) (gamedomain.Game, error) {
+	if g, ok := gamedomain.GameFromContext(ctx); ok && g.Slug == slug {
+		return g, nil
+	}
+
+	res, err := s.gameStore.Get(ctx, gamedomain.GetQuery{
+		Slug: slug,
+	})
+	if err != nil {
+		return gamedomain.Game{}, err
+	}
+
+	return res.Game, nil
+}
+
+func (s service) Get(ctx context.Context, req domain.GetRequest) (domain.GetResponse, error) {
+	if err := req.Validate(); err != nil {
+		return domain.GetResponse{}, fmt.Errorf("invalid request: %w", errutil.WithKind(err, domain.ErrInvalidArgument))
+	}
+
+	getRes, err := s.store.Get(ctx, domain.GetQuery(req))
+	if err != nil {
+		return domain.GetResponse{}, fmt.Errorf("failed to get: %w", err)
+	}
+
+	res := domain.GetResponse(getRes)
+
+	if err := res.Validate(); err != nil {
+		return domain.GetResponse{}, fmt.Errorf("invalid response: %w", err)
+	}
+
+	return res, nil
+}
+
+func (s service) List(ctx context.Context, req domain.ListRequest) (domain.ListResponse, error) {
+	if err := req.Validate(); err != nil {
+		return domain.ListResponse{}, fmt.Errorf("invalid request: %w", errutil.WithKind(err, domain.ErrInvalidArgument))
+	}
+
+	listRes, err := s.store.List(ctx, domain.ListQuery{
+		Game:     req.Game,
+		PlayerID: req.PlayerID,
+		Cursor:   req.Cursor,
+		Limit:    pagination.Limit(req.Limit),
+	})
+	if err != nil {
+		return domain.ListResponse{}, fmt.Errorf("failed to list: %w", err)
+	}
+
+	return domain.ListResponse(listRes), nil
+}
+
+func (s service) Heartbeat(ctx context.Context, req domain.HeartbeatRequest) (domain.HeartbeatResponse, error) {
+	if err := req.Validate(); err != nil {
+		return domain.HeartbeatResponse{}, fmt.Errorf("invalid request: %w", errutil.WithKind(err, domain.ErrInvalidArgument))
+	}
+
+	heartbeatRes, err := s.store.Heartbeat(ctx, domain.HeartbeatQuery(req))
+	if err != nil {
+		return domain.HeartbeatResponse{}, fmt.Errorf("failed to heartbeat: %w", err)
+	}
+
+	return domain.HeartbeatResponse(heartbeatRes), nil
+}
+
+func (s service) End(ctx context.Context, req domain.EndRequest) (domain.EndResponse, error) {
+	if err := req.Validate(); err != nil {
+		return domain.EndResponse{}, fmt.Errorf("invalid request: %w", errutil.WithKind(err, domain.ErrInvalidArgument))
+	}
+
+	endRes, err := s.store.End(ctx, domain.EndQuery(req))
+	if err != nil {
+		return domain.EndResponse{}, fmt.Errorf("failed to end: %w", err)
+	}
+
+	return domain.EndResponse(endRes), nil
+}
+
+func (s service) EndIdle(ctx context.Context, req domain.EndIdleRequest) (domain.EndIdleResponse, error) {
+	if err := req.Validate(); err != nil {
+		return domain.EndIdleResponse{}, fmt.Errorf("invalid request: %w", errutil.WithKind(err, domain.ErrInvalidArgument))
+	}
+
+	endRes, err := s.store.EndIdle(ctx, domain.EndIdleQuery(req))
+	if err != nil {
+		return domain.EndIdleResponse{}, fmt.Errorf("failed to end idle sessions: %w", err)
+	}
+
+	return domain.EndIdleResponse(endRes), nil
+}
+
+func (s service) Ping(ctx context.Context) error {
+	if err := s.store.Ping(ctx); err != nil {
+		return fmt.Errorf("failed to ping store: %w", err)
+	}
+
+	return nil
+}
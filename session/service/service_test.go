@@ -0,0 +1,218 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	gamedomain "github.com/vediagames/onlooker/domain/game"
+	domain "github.com/vediagames/onlooker/domain/session"
+	gamememory "github.com/vediagames/onlooker/game/store/memory"
+	"github.com/vediagames/onlooker/metadata"
+	playermemory "github.com/vediagames/onlooker/player/store/memory"
+	"github.com/vediagames/onlooker/session/store/memory"
+)
+
+func TestService_Create(t *testing.T) {
+	valid := domain.CreateRequest{
+		Game:       gamedomain.DefaultGame,
+		ClientTime: time.Now(),
+		IP:         "127.0.0.1",
+		URL:        "https://example.com/game",
+		Timezone:   "UTC",
+	}
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		modify  func(*domain.CreateRequest)
+		origins []string
+		reject  bool
+		wantErr error
+	}{
+		{
+			name:   "valid",
+			ctx:    context.Background(),
+			modify: func(*domain.CreateRequest) {},
+		},
+		{
+			name:    "missing url",
+			ctx:     context.Background(),
+			modify:  func(r *domain.CreateRequest) { r.URL = "" },
+			wantErr: domain.ErrInvalidArgument,
+		},
+		{
+			name:    "unknown game",
+			ctx:     context.Background(),
+			modify:  func(r *domain.CreateRequest) { r.Game = "unknown" },
+			wantErr: domain.ErrNotFound,
+		},
+		{
+			name:    "allowed origin",
+			ctx:     context.Background(),
+			modify:  func(*domain.CreateRequest) {},
+			origins: []string{"https://example.com"},
+			reject:  true,
+		},
+		{
+			name:    "unknown origin",
+			ctx:     context.Background(),
+			modify:  func(r *domain.CreateRequest) { r.URL = "https://other.com/game" },
+			origins: []string{"https://example.com"},
+			reject:  true,
+			wantErr: domain.ErrPermissionDenied,
+		},
+		{
+			name:    "unknown origin not rejected",
+			ctx:     context.Background(),
+			modify:  func(r *domain.CreateRequest) { r.URL = "https://other.com/game" },
+			origins: []string{"https://example.com"},
+		},
+		{
+			name: "game from context",
+			ctx: gamedomain.ContextWithGame(context.Background(), gamedomain.Game{
+				Slug:           gamedomain.DefaultGame,
+				AllowedOrigins: []string{"https://example.com"},
+			}),
+			modify:  func(r *domain.CreateRequest) { r.URL = "https://other.com/game" },
+			reject:  true,
+			wantErr: domain.ErrPermissionDenied,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, gameStore := newService(t, tt.reject)
+
+			if tt.origins != nil {
+				err := gameStore.UpdateAllowedOrigins(context.Background(), gamedomain.UpdateAllowedOriginsQuery{
+					Slug:           gamedomain.DefaultGame,
+					AllowedOrigins: tt.origins,
+				})
+				if err != nil {
+					t.Fatalf("failed to update allowed origins: %v", err)
+				}
+			}
+
+			req := valid
+			tt.modify(&req)
+
+			_, err := s.Create(tt.ctx, req)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("got %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestService_Create_Idempotency(t *testing.T) {
+	ctx := context.Background()
+	s, gameStore := newService(t, false)
+
+	if _, err := gameStore.Insert(ctx, gamedomain.InsertQuery{Slug: "other", Name: "Other"}); err != nil {
+		t.Fatalf("failed to insert game: %v", err)
+	}
+
+	req := domain.CreateRequest{
+		Game:           gamedomain.DefaultGame,
+		ClientTime:     time.Now(),
+		IP:             "127.0.0.1",
+		URL:            "https://example.com/game",
+		Timezone:       "UTC",
+		IdempotencyKey: "key",
+	}
+
+	first, err := s.Create(ctx, req)
+	if err != nil {
+		t.Fatalf("failed to create session: %v", err)
+	}
+
+	replay, err := s.Create(ctx, req)
+	if err != nil {
+		t.Fatalf("failed to replay session: %v", err)
+	}
+
+	if replay.UUID != first.UUID {
+		t.Fatalf("got session %q on replay, want %q", replay.UUID, first.UUID)
+	}
+
+	// Idempotency keys are unique per game.
+	req.Game = "other"
+
+	other, err := s.Create(ctx, req)
+	if err != nil {
+		t.Fatalf("failed to create session of other game: %v", err)
+	}
+
+	if other.UUID == first.UUID {
+		t.Fatalf("got session %q of other game, want a new session", other.UUID)
+	}
+}
+
+func TestService_End(t *testing.T) {
+	ctx := context.Background()
+	s, _ := newService(t, false)
+
+	res, err := s.Create(ctx, domain.CreateRequest{
+		Game:       gamedomain.DefaultGame,
+		ClientTime: time.Now(),
+		IP:         "127.0.0.1",
+		URL:        "https://example.com/game",
+		Timezone:   "UTC",
+	})
+	if err != nil {
+		t.Fatalf("failed to create session: %v", err)
+	}
+
+	if _, err := s.Heartbeat(ctx, domain.HeartbeatRequest{UUID: res.UUID}); err != nil {
+		t.Fatalf("failed to heartbeat open session: %v", err)
+	}
+
+	if _, err := s.End(ctx, domain.EndRequest{UUID: res.UUID, Game: "other"}); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("end of session of other game: got %v, want %v", err, domain.ErrNotFound)
+	}
+
+	if _, err := s.End(ctx, domain.EndRequest{UUID: res.UUID}); err != nil {
+		t.Fatalf("failed to end session: %v", err)
+	}
+
+	if _, err := s.Heartbeat(ctx, domain.HeartbeatRequest{UUID: res.UUID}); !errors.Is(err, domain.ErrConflict) {
+		t.Fatalf("heartbeat of ended session: got %v, want %v", err, domain.ErrConflict)
+	}
+
+	getRes, err := s.Get(ctx, domain.GetRequest{UUID: res.UUID})
+	if err != nil {
+		t.Fatalf("failed to get session: %v", err)
+	}
+
+	if getRes.Session.EndedAt == nil {
+		t.Fatalf("got open session after end, want it ended")
+	}
+}
+
+func newService(t *testing.T, rejectUnknownOrigins bool) (domain.Service, gamedomain.Store) {
+	t.Helper()
+
+	gameStore := gamememory.New()
+
+	store, err := memory.New(memory.Config{
+		GameStore:   gameStore,
+		PlayerStore: playermemory.New(),
+	})
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+
+	s, err := New(Config{
+		Store:                store,
+		GameStore:            gameStore,
+		MetadataLimits:       metadata.Limits{MaxSize: 1024, MaxDepth: 4},
+		RejectUnknownOrigins: rejectUnknownOrigins,
+	})
+	if err != nil {
+		t.Fatalf("failed to create service: %v", err)
+	}
+
+	return s, gameStore
+}
@@ -0,0 +1,116 @@
+package instrumented
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	domain "github.com/vediagames/onlooker/domain/session"
+	"github.com/vediagames/onlooker/errutil"
+	"github.com/vediagames/onlooker/metrics"
+)
+
+const storeName = "session"
+
+// store records the latency and errors of the queries of the wrapped store.
+type store struct {
+	store   domain.Store
+	metrics *metrics.Metrics
+}
+
+type Config struct {
+	Store   domain.Store
+	Metrics *metrics.Metrics
+}
+
+func (c Config) Validate() error {
+	var err errutil.Error
+
+	if c.Store == nil {
+		err.Add(fmt.Errorf("store is empty"))
+	}
+
+	if c.Metrics == nil {
+		err.Add(fmt.Errorf("metrics is empty"))
+	}
+
+	return err.Err()
+}
+
+func New(cfg Config) (domain.Store, error) {
+	if ve := cfg.Validate(); ve != nil {
+		return nil, fmt.Errorf("invalid config: %w", ve)
+	}
+
+	return &store{
+		store:   cfg.Store,
+		metrics: cfg.Metrics,
+	}, nil
+}
+
+func (s store) Insert(ctx context.Context, q domain.InsertQuery) (domain.InsertResult, error) {
+	start := time.Now()
+
+	res, err := s.store.Insert(ctx, q)
+	s.metrics.ObserveStoreQuery(storeName, "insert", start, err)
+
+	return res, err
+}
+
+func (s store) Get(ctx context.Context, q domain.GetQuery) (domain.GetResult, error) {
+	start := time.Now()
+
+	res, err := s.store.Get(ctx, q)
+	s.metrics.ObserveStoreQuery(storeName, "get", start, err)
+
+	return res, err
+}
+
+func (s store) List(ctx context.Context, q domain.ListQuery) (domain.ListResult, error) {
+	start := time.Now()
+
+	res, err := s.store.List(ctx, q)
+	s.metrics.ObserveStoreQuery(storeName, "list", start, err)
+
+	return res, err
+}
+
+func (s store) Heartbeat(ctx context.Context, q domain.HeartbeatQuery) (domain.HeartbeatResult, error) {
+	start := time.Now()
+
+	res, err := s.store.Heartbeat(ctx, q)
+	s.metrics.ObserveStoreQuery(storeName, "heartbeat", start, err)
+
+	return res, err
+}
+
+func (s store) End(ctx context.Context, q domain.EndQuery) (domain.EndResult, error) {
+	start := time.Now()
+
+	res, err := s.store.End(ctx, q)
+	s.metrics.ObserveStoreQuery(storeName, "end", start, err)
+
+	return res, err
+}
+
+func (s store) EndIdle(ctx context.Context, q domain.EndIdleQuery) (domain.EndIdleResult, error) {
+	start := time.Now()
+
+	res, err := s.store.EndIdle(ctx, q)
+	s.metrics.ObserveStoreQuery(storeName, "end_idle", start, err)
+
+	return res, err
+}
+
+func (s store) Ping(ctx context.Context) error {
+	start := time.Now()
+
+	err := s.store.Ping(ctx)
+	s.metrics.ObserveStoreQuery(storeName, "ping", start, err)
+
+	return err
+}
+
+func (s store) Close() error {
+	return s.store.Close()
+}
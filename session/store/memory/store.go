@@ -0,0 +1,270 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	gamedomain "github.com/vediagames/onlooker/domain/game"
+	playerdomain "github.com/vediagames/onlooker/domain/player"
+	domain "github.com/vediagames/onlooker/domain/session"
+	"github.com/vediagames/onlooker/errutil"
+	"github.com/vediagames/onlooker/pagination"
+)
+
+type store struct {
+	gameStore   gamedomain.Store
+	playerStore playerdomain.Store
+
+	mu       sync.RWMutex
+	sessions map[string]domain.Session
+	// keys maps idempotency keys, which are unique per game, to session UUIDs.
+	keys map[string]string
+}
+
+type Config struct {
+	// GameStore is used to check that the game of a session exists.
+	GameStore gamedomain.Store
+	// PlayerStore records the players sessions are created for.
+	PlayerStore playerdomain.Store
+}
+
+func (c Config) Validate() error {
+	var err errutil.Error
+
+	if c.GameStore == nil {
+		err.Add(fmt.Errorf("game store is empty"))
+	}
+
+	if c.PlayerStore == nil {
+		err.Add(fmt.Errorf("player store is empty"))
+	}
+
+	return err.Err()
+}
+
+func New(cfg Config) (domain.Store, error) {
+	if ve := cfg.Validate(); ve != nil {
+		return nil, fmt.Errorf("invalid config: %w", ve)
+	}
+
+	return &store{
+		gameStore:   cfg.GameStore,
+		playerStore: cfg.PlayerStore,
+		sessions:    make(map[string]domain.Session),
+		keys:        make(map[string]string),
+	}, nil
+}
+
+func (s *store) Insert(ctx context.Context, q domain.InsertQuery) (domain.InsertResult, error) {
+	_, err := s.gameStore.Get(ctx, gamedomain.GetQuery{Slug: q.Game})
+	if errors.Is(err, gamedomain.ErrNotFound) {
+		return domain.InsertResult{}, fmt.Errorf("failed to insert session: game %q: %w", q.Game, domain.ErrNotFound)
+	}
+	if err != nil {
+		return domain.InsertResult{}, fmt.Errorf("failed to get game: %w", err)
+	}
+
+	session := domain.Session{
+		UUID:          uuid.NewString(),
+		Game:          q.Game,
+		ClientTime:    q.ClientTime,
+		ServerTime:    time.Now().UTC(),
+		IP:            q.IP,
+		URL:           q.URL,
+		Timezone:      q.Timezone,
+		Metadata:      copyMetadata(q.Metadata),
+		PlayerID:      q.PlayerID,
+		UnknownOrigin: q.UnknownOrigin,
+	}
+
+	session.LastSeenAt = session.ServerTime
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := q.Game + "/" + q.IdempotencyKey
+
+	if id, ok := s.keys[key]; ok && q.IdempotencyKey != "" {
+		return domain.InsertResult{
+			UUID:       id,
+			ServerTime: s.sessions[id].ServerTime,
+		}, nil
+	}
+
+	// Replayed sessions returned above do not touch their player again.
+	if q.PlayerID != "" {
+		_, err := s.playerStore.Touch(ctx, playerdomain.TouchQuery{
+			Game: q.Game,
+			ID:   q.PlayerID,
+		})
+		if err != nil {
+			return domain.InsertResult{}, fmt.Errorf("failed to touch player: %w", err)
+		}
+	}
+
+	s.sessions[session.UUID] = session
+
+	if q.IdempotencyKey != "" {
+		s.keys[key] = session.UUID
+	}
+
+	return domain.InsertResult{
+		UUID:       session.UUID,
+		ServerTime: session.ServerTime,
+	}, nil
+}
+
+func (s *store) Get(ctx context.Context, q domain.GetQuery) (domain.GetResult, error) {
+	s.mu.RLock()
+	session, ok := s.sessions[q.UUID]
+	s.mu.RUnlock()
+
+	if !ok || (q.Game != "" && session.Game != q.Game) {
+		return domain.GetResult{}, fmt.Errorf("session %q: %w", q.UUID, domain.ErrNotFound)
+	}
+
+	session.Metadata = copyMetadata(session.Metadata)
+
+	return domain.GetResult{
+		Session: session,
+	}, nil
+}
+
+func (s *store) List(ctx context.Context, q domain.ListQuery) (domain.ListResult, error) {
+	s.mu.RLock()
+
+	sessions := make([]domain.Session, 0)
+	for _, session := range s.sessions {
+		if session.Game != q.Game || session.PlayerID != q.PlayerID || !after(session.ServerTime, session.UUID, q.Cursor) {
+			continue
+		}
+
+		session.Metadata = copyMetadata(session.Metadata)
+		sessions = append(sessions, session)
+	}
+
+	s.mu.RUnlock()
+
+	sort.Slice(sessions, func(i, j int) bool {
+		return less(sessions[i].ServerTime, sessions[i].UUID, sessions[j].ServerTime, sessions[j].UUID)
+	})
+
+	var res domain.ListResult
+
+	if len(sessions) > q.Limit {
+		sessions = sessions[:q.Limit]
+		last := sessions[len(sessions)-1]
+		res.NextCursor = pagination.Cursor{ServerTime: last.ServerTime, UUID: last.UUID}
+	}
+
+	res.Sessions = sessions
+
+	return res, nil
+}
+
+func (s *store) Heartbeat(ctx context.Context, q domain.HeartbeatQuery) (domain.HeartbeatResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session, ok := s.sessions[q.UUID]
+	if !ok || (q.Game != "" && session.Game != q.Game) {
+		return domain.HeartbeatResult{}, fmt.Errorf("session %q: %w", q.UUID, domain.ErrNotFound)
+	}
+
+	if session.EndedAt != nil {
+		return domain.HeartbeatResult{}, fmt.Errorf("session %q has ended: %w", q.UUID, domain.ErrConflict)
+	}
+
+	session.LastSeenAt = time.Now().UTC()
+	s.sessions[q.UUID] = session
+
+	return domain.HeartbeatResult{
+		LastSeenAt: session.LastSeenAt,
+	}, nil
+}
+
+func (s *store) End(ctx context.Context, q domain.EndQuery) (domain.EndResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session, ok := s.sessions[q.UUID]
+	if !ok || (q.Game != "" && session.Game != q.Game) {
+		return domain.EndResult{}, fmt.Errorf("session %q: %w", q.UUID, domain.ErrNotFound)
+	}
+
+	if session.EndedAt == nil {
+		now := time.Now().UTC()
+		session.LastSeenAt = now
+		session.EndedAt = &now
+		s.sessions[q.UUID] = session
+	}
+
+	return domain.EndResult{
+		EndedAt: *session.EndedAt,
+	}, nil
+}
+
+func (s *store) EndIdle(ctx context.Context, q domain.EndIdleQuery) (domain.EndIdleResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	lastSeenBefore := time.Now().UTC().Add(-q.Timeout)
+
+	var res domain.EndIdleResult
+
+	for id, session := range s.sessions {
+		if session.EndedAt != nil || !session.LastSeenAt.Before(lastSeenBefore) {
+			continue
+		}
+
+		endedAt := session.LastSeenAt
+		session.EndedAt = &endedAt
+		s.sessions[id] = session
+
+		res.Count++
+	}
+
+	return res, nil
+}
+
+func less(t1 time.Time, uuid1 string, t2 time.Time, uuid2 string) bool {
+	if t1.Equal(t2) {
+		return uuid1 < uuid2
+	}
+
+	return t1.Before(t2)
+}
+
+func after(t time.Time, uuid string, c pagination.Cursor) bool {
+	if c.IsZero() {
+		return true
+	}
+
+	return less(c.ServerTime, c.UUID, t, uuid)
+}
+
+func copyMetadata(m map[string]interface{}) map[string]interface{} {
+	if m == nil {
+		return nil
+	}
+
+	c := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		c[k] = v
+	}
+
+	return c
+}
+
+func (s *store) Ping(ctx context.Context) error {
+	return nil
+}
+
+func (s *store) Close() error {
+	return nil
+}
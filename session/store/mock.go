@@ -16,3 +16,38 @@ func (s mock) Insert(ctx context.Context, query domain.InsertQuery) (domain.Inse
 	//TODO implement me
 	panic("implement me")
 }
+
+func (s mock) Get(ctx context.Context, query domain.GetQuery) (domain.GetResult, error) {
+	//TODO implement me
+	panic("implement me")
+}
+
+func (s mock) List(ctx context.Context, query domain.ListQuery) (domain.ListResult, error) {
+	//TODO implement me
+	panic("implement me")
+}
+
+func (s mock) Heartbeat(ctx context.Context, query domain.HeartbeatQuery) (domain.HeartbeatResult, error) {
+	//TODO implement me
+	panic("implement me")
+}
+
+func (s mock) End(ctx context.Context, query domain.EndQuery) (domain.EndResult, error) {
+	//TODO implement me
+	panic("implement me")
+}
+
+func (s mock) EndIdle(ctx context.Context, query domain.EndIdleQuery) (domain.EndIdleResult, error) {
+	//TODO implement me
+	panic("implement me")
+}
+
+func (s mock) Ping(ctx context.Context) error {
+	//TODO implement me
+	panic("implement me")
+}
+
+func (s mock) Close() error {
+	//TODO implement me
+	panic("implement me")
+}
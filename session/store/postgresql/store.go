@@ -2,14 +2,18 @@ package postgresql
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/jmoiron/sqlx"
 	_ "github.com/lib/pq"
+	"github.com/vediagames/onlooker/database"
 	domain "github.com/vediagames/onlooker/domain/session"
 	"github.com/vediagames/onlooker/errutil"
+	"github.com/vediagames/onlooker/pagination"
 )
 
 type store struct {
@@ -17,14 +21,14 @@ type store struct {
 }
 
 type Config struct {
-	ConnectionString string
+	DB *sqlx.DB
 }
 
 func (c Config) Validate() error {
 	var err errutil.Error
 
-	if c.ConnectionString == "" {
-		err.Add(fmt.Errorf("connection string is empty"))
+	if c.DB == nil {
+		err.Add(fmt.Errorf("db is empty"))
 	}
 
 	return err.Err()
@@ -35,26 +39,16 @@ func New(cfg Config) (domain.Store, error) {
 		return nil, fmt.Errorf("invalid config: %w", ve)
 	}
 
-	db, err := sqlx.Open("postgres", cfg.ConnectionString)
-	if err != nil {
-		return nil, fmt.Errorf("failed to open database: %v", err)
-	}
-
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-
-	if err = db.PingContext(ctx); err != nil {
-		return nil, fmt.Errorf("failed to ping database: %v", err)
-	}
-
 	return &store{
-		db: db,
+		db: cfg.DB,
 	}, nil
 }
 
 type insertResult struct {
 	UUID       string    `db:"uuid"`
 	ServerTime time.Time `db:"server_time"`
+	// Created is false if the idempotency key replayed an existing session.
+	Created bool `db:"created"`
 }
 
 func (s store) Insert(ctx context.Context, q domain.InsertQuery) (domain.InsertResult, error) {
@@ -65,13 +59,39 @@ func (s store) Insert(ctx context.Context, q domain.InsertQuery) (domain.InsertR
 		return domain.InsertResult{}, fmt.Errorf("failed to marshal metadata: %w", err)
 	}
 
-	err = s.db.Get(&res, `
-		INSERT INTO sessions (client_time, ip, url, "timezone", server_time, metadata) 
-		VALUES ($1, $2, $3, $4, now(), $5)
-		RETURNING uuid, server_time
-	`, q.ClientTime, q.IP, q.URL, q.Timezone, metadata)
+	tx, err := s.db.BeginTxx(ctx, nil)
 	if err != nil {
-		return domain.InsertResult{}, fmt.Errorf("failed to insert level: %v", err)
+		return domain.InsertResult{}, fmt.Errorf("failed to begin transaction: %w", database.TranslateError(err))
+	}
+	defer tx.Rollback()
+
+	err = tx.GetContext(ctx, &res, `
+		INSERT INTO sessions (game, client_time, ip, url, "timezone", server_time, metadata, idempotency_key, unknown_origin, last_seen_at, player_id) 
+		VALUES ($1, $2, $3, $4, $5, now(), $6, $7, $8, now(), $9)
+		ON CONFLICT (game, idempotency_key) WHERE idempotency_key IS NOT NULL
+		DO UPDATE SET idempotency_key = EXCLUDED.idempotency_key
+		RETURNING uuid, server_time, xmax = 0 AS created
+	`, q.Game, q.ClientTime, q.IP, q.URL, q.Timezone, metadata, database.NullString(q.IdempotencyKey), q.UnknownOrigin, database.NullString(q.PlayerID))
+	if err != nil {
+		return domain.InsertResult{}, fmt.Errorf("failed to insert session: %w", database.TranslateError(err))
+	}
+
+	// The player is touched after its session, which references it once the
+	// transaction commits. Replayed sessions do not touch it again.
+	if res.Created && q.PlayerID != "" {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO players (game, id, first_seen_at, last_seen_at)
+			VALUES ($1, $2, now(), now())
+			ON CONFLICT (game, id)
+			DO UPDATE SET last_seen_at = EXCLUDED.last_seen_at
+		`, q.Game, q.PlayerID)
+		if err != nil {
+			return domain.InsertResult{}, fmt.Errorf("failed to touch player: %w", database.TranslateError(err))
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return domain.InsertResult{}, fmt.Errorf("failed to commit transaction: %w", database.TranslateError(err))
 	}
 
 	return domain.InsertResult{
@@ -79,3 +99,196 @@ func (s store) Insert(ctx context.Context, q domain.InsertQuery) (domain.InsertR
 		ServerTime: res.ServerTime,
 	}, nil
 }
+
+type session struct {
+	UUID          string         `db:"uuid"`
+	Game          string         `db:"game"`
+	ClientTime    time.Time      `db:"client_time"`
+	ServerTime    time.Time      `db:"server_time"`
+	IP            string         `db:"ip"`
+	URL           string         `db:"url"`
+	Timezone      string         `db:"timezone"`
+	Metadata      []byte         `db:"metadata"`
+	PlayerID      sql.NullString `db:"player_id"`
+	UnknownOrigin bool           `db:"unknown_origin"`
+	LastSeenAt    time.Time      `db:"last_seen_at"`
+	EndedAt       *time.Time     `db:"ended_at"`
+}
+
+func (s session) toDomain() (domain.Session, error) {
+	var metadata map[string]interface{}
+
+	if len(s.Metadata) > 0 {
+		if err := json.Unmarshal(s.Metadata, &metadata); err != nil {
+			return domain.Session{}, fmt.Errorf("failed to unmarshal metadata: %w", err)
+		}
+	}
+
+	return domain.Session{
+		UUID:          s.UUID,
+		Game:          s.Game,
+		ClientTime:    s.ClientTime,
+		ServerTime:    s.ServerTime,
+		IP:            s.IP,
+		URL:           s.URL,
+		Timezone:      s.Timezone,
+		Metadata:      metadata,
+		PlayerID:      s.PlayerID.String,
+		UnknownOrigin: s.UnknownOrigin,
+		LastSeenAt:    s.LastSeenAt,
+		EndedAt:       s.EndedAt,
+	}, nil
+}
+
+func (s store) Get(ctx context.Context, q domain.GetQuery) (domain.GetResult, error) {
+	var row session
+
+	err := s.db.GetContext(ctx, &row, `
+		SELECT uuid, game, client_time, server_time, ip, url, "timezone", metadata, player_id, unknown_origin, last_seen_at, ended_at
+		FROM sessions
+		WHERE uuid = $1 AND ($2 = '' OR game = $2)
+	`, q.UUID, q.Game)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.GetResult{}, fmt.Errorf("session %q: %w", q.UUID, domain.ErrNotFound)
+	}
+	if err != nil {
+		return domain.GetResult{}, fmt.Errorf("failed to get session: %w", database.TranslateError(err))
+	}
+
+	res, err := row.toDomain()
+	if err != nil {
+		return domain.GetResult{}, err
+	}
+
+	return domain.GetResult{
+		Session: res,
+	}, nil
+}
+
+func (s store) List(ctx context.Context, q domain.ListQuery) (domain.ListResult, error) {
+	args := []interface{}{q.Game, q.PlayerID}
+	sqlQuery := `
+		SELECT uuid, game, client_time, server_time, ip, url, "timezone", metadata, player_id, unknown_origin, last_seen_at, ended_at
+		FROM sessions
+		WHERE game = $1 AND player_id = $2`
+
+	if !q.Cursor.IsZero() {
+		args = append(args, q.Cursor.ServerTime, q.Cursor.UUID)
+		sqlQuery += fmt.Sprintf(" AND (server_time, uuid) > ($%d, $%d::uuid)", len(args)-1, len(args))
+	}
+
+	args = append(args, q.Limit+1)
+	sqlQuery += fmt.Sprintf(" ORDER BY server_time, uuid LIMIT $%d", len(args))
+
+	var rows []session
+
+	if err := s.db.SelectContext(ctx, &rows, sqlQuery, args...); err != nil {
+		return domain.ListResult{}, fmt.Errorf("failed to list sessions: %w", database.TranslateError(err))
+	}
+
+	var res domain.ListResult
+
+	if len(rows) > q.Limit {
+		rows = rows[:q.Limit]
+		last := rows[len(rows)-1]
+		res.NextCursor = pagination.Cursor{ServerTime: last.ServerTime, UUID: last.UUID}
+	}
+
+	res.Sessions = make([]domain.Session, 0, len(rows))
+
+	for _, r := range rows {
+		session, err := r.toDomain()
+		if err != nil {
+			return domain.ListResult{}, err
+		}
+
+		res.Sessions = append(res.Sessions, session)
+	}
+
+	return res, nil
+}
+
+func (s store) Heartbeat(ctx context.Context, q domain.HeartbeatQuery) (domain.HeartbeatResult, error) {
+	var row struct {
+		LastSeenAt time.Time `db:"last_seen_at"`
+		Ended      bool      `db:"ended"`
+	}
+
+	// The update of an ended session is a no-op, which still returns the row
+	// to tell ended from missing sessions.
+	err := s.db.GetContext(ctx, &row, `
+		UPDATE sessions
+		SET last_seen_at = CASE WHEN ended_at IS NULL THEN now() ELSE last_seen_at END
+		WHERE uuid = $1 AND ($2 = '' OR game = $2)
+		RETURNING last_seen_at, ended_at IS NOT NULL AS ended
+	`, q.UUID, q.Game)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.HeartbeatResult{}, fmt.Errorf("session %q: %w", q.UUID, domain.ErrNotFound)
+	}
+	if err != nil {
+		return domain.HeartbeatResult{}, fmt.Errorf("failed to update session: %w", database.TranslateError(err))
+	}
+
+	if row.Ended {
+		return domain.HeartbeatResult{}, fmt.Errorf("session %q has ended: %w", q.UUID, domain.ErrConflict)
+	}
+
+	return domain.HeartbeatResult{
+		LastSeenAt: row.LastSeenAt,
+	}, nil
+}
+
+func (s store) End(ctx context.Context, q domain.EndQuery) (domain.EndResult, error) {
+	var endedAt time.Time
+
+	err := s.db.GetContext(ctx, &endedAt, `
+		UPDATE sessions
+		SET ended_at     = COALESCE(ended_at, now()),
+		    last_seen_at = CASE WHEN ended_at IS NULL THEN now() ELSE last_seen_at END
+		WHERE uuid = $1 AND ($2 = '' OR game = $2)
+		RETURNING ended_at
+	`, q.UUID, q.Game)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.EndResult{}, fmt.Errorf("session %q: %w", q.UUID, domain.ErrNotFound)
+	}
+	if err != nil {
+		return domain.EndResult{}, fmt.Errorf("failed to end session: %w", database.TranslateError(err))
+	}
+
+	return domain.EndResult{
+		EndedAt: endedAt,
+	}, nil
+}
+
+func (s store) EndIdle(ctx context.Context, q domain.EndIdleQuery) (domain.EndIdleResult, error) {
+	res, err := s.db.ExecContext(ctx, `
+		UPDATE sessions
+		SET ended_at = last_seen_at
+		WHERE ended_at IS NULL AND last_seen_at < now() - make_interval(secs => $1)
+	`, q.Timeout.Seconds())
+	if err != nil {
+		return domain.EndIdleResult{}, fmt.Errorf("failed to end idle sessions: %w", database.TranslateError(err))
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return domain.EndIdleResult{}, fmt.Errorf("failed to get ended sessions: %w", database.TranslateError(err))
+	}
+
+	return domain.EndIdleResult{
+		Count: int(n),
+	}, nil
+}
+
+func (s store) Ping(ctx context.Context) error {
+	if err := s.db.PingContext(ctx); err != nil {
+		return fmt.Errorf("failed to ping database: %w", database.TranslateError(err))
+	}
+
+	return nil
+}
+
+// Close does nothing, the database is shared and closed by its owner.
+func (s store) Close() error {
+	return nil
+}
@@ -0,0 +1,137 @@
+package signature
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/vediagames/onlooker/errutil"
+)
+
+const (
+	// HeaderTimestamp is the unix time in seconds at which the client signed the request.
+	HeaderTimestamp = "X-Onlooker-Timestamp"
+	// HeaderSignature is the hex encoded signature of the request, see Sign.
+	HeaderSignature = "X-Onlooker-Signature"
+
+	// MaxBodySize is the maximum size in bytes of a signed body, which is read into memory to verify it.
+	MaxBodySize = 8 << 20
+)
+
+var (
+	ErrMissing  = errors.New("missing signature")
+	ErrInvalid  = errors.New("invalid signature")
+	ErrExpired  = errors.New("signature timestamp is outside of the window")
+	ErrReplayed = errors.New("signature was already used")
+)
+
+// Sign returns the hex encoded HMAC-SHA256 of the timestamp, the method, the
+// path and the body separated by dots, so a signed body cannot be replayed
+// against another endpoint.
+func Sign(secret, timestamp, method, path string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "." + method + "." + path + "."))
+	mac.Write(body)
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+type Config struct {
+	// Window is how far the timestamp of a signature may be from the server time.
+	Window time.Duration
+}
+
+func (c Config) Validate() error {
+	var err errutil.Error
+
+	if c.Window <= 0 {
+		err.Add(fmt.Errorf("window must be above 0"))
+	}
+
+	return err.Err()
+}
+
+// Verifier checks signatures and rejects signatures which were already used
+// within the window. Used signatures are kept in memory, so replays to
+// another instance of the server are not detected.
+type Verifier struct {
+	window time.Duration
+	now    func() time.Time
+
+	mu sync.Mutex
+	// seen maps used signatures to the time they leave the window.
+	seen      map[string]time.Time
+	lastPrune time.Time
+}
+
+func New(cfg Config) (*Verifier, error) {
+	if ve := cfg.Validate(); ve != nil {
+		return nil, fmt.Errorf("invalid config: %w", ve)
+	}
+
+	return &Verifier{
+		window: cfg.Window,
+		now:    time.Now,
+		seen:   make(map[string]time.Time),
+	}, nil
+}
+
+// Verify checks that signature is the signature of timestamp, method, path and
+// body with secret, that timestamp is within the window and that signature was
+// not used before.
+func (v *Verifier) Verify(secret, timestamp, signature, method, path string, body []byte) error {
+	if timestamp == "" || signature == "" {
+		return ErrMissing
+	}
+
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid timestamp %q: %w", timestamp, ErrInvalid)
+	}
+
+	expected := Sign(secret, timestamp, method, path, body)
+	if !hmac.Equal([]byte(signature), []byte(expected)) {
+		return ErrInvalid
+	}
+
+	now := v.now()
+	signedAt := time.Unix(unix, 0)
+
+	if signedAt.Before(now.Add(-v.window)) || signedAt.After(now.Add(v.window)) {
+		return ErrExpired
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	v.prune(now)
+
+	if _, ok := v.seen[signature]; ok {
+		return ErrReplayed
+	}
+
+	v.seen[signature] = signedAt.Add(v.window)
+
+	return nil
+}
+
+// prune forgets signatures which left the window, at most once per window.
+// It must be called with the lock held.
+func (v *Verifier) prune(now time.Time) {
+	if now.Sub(v.lastPrune) < v.window {
+		return
+	}
+
+	for s, expiresAt := range v.seen {
+		if now.After(expiresAt) {
+			delete(v.seen, s)
+		}
+	}
+
+	v.lastPrune = now
+}
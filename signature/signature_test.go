@@ -0,0 +1,212 @@
+package signature
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestVerifier_Verify(t *testing.T) {
+	const secret = "secret"
+
+	now := time.Unix(1700000000, 0)
+	ts := strconv.FormatInt(now.Unix(), 10)
+	body := []byte(`{"uuid":"4a7d1ed4-1f3b-4ac7-9d7c-5f9b1b0f3c11"}`)
+
+	tests := []struct {
+		name      string
+		secret    string
+		timestamp string
+		signature string
+		method    string
+		path      string
+		body      []byte
+		want      error
+	}{
+		{
+			name:      "valid",
+			secret:    secret,
+			timestamp: ts,
+			signature: Sign(secret, ts, "POST", "/api/v1/session/", body),
+			method:    "POST",
+			path:      "/api/v1/session/",
+			body:      body,
+		},
+		{
+			name:      "missing timestamp",
+			secret:    secret,
+			signature: Sign(secret, ts, "POST", "/api/v1/session/", body),
+			method:    "POST",
+			path:      "/api/v1/session/",
+			body:      body,
+			want:      ErrMissing,
+		},
+		{
+			name:      "missing signature",
+			secret:    secret,
+			timestamp: ts,
+			method:    "POST",
+			path:      "/api/v1/session/",
+			body:      body,
+			want:      ErrMissing,
+		},
+		{
+			name:      "malformed timestamp",
+			secret:    secret,
+			timestamp: "yesterday",
+			signature: Sign(secret, "yesterday", "POST", "/api/v1/session/", body),
+			method:    "POST",
+			path:      "/api/v1/session/",
+			body:      body,
+			want:      ErrInvalid,
+		},
+		{
+			name:      "other secret",
+			secret:    secret,
+			timestamp: ts,
+			signature: Sign("other", ts, "POST", "/api/v1/session/", body),
+			method:    "POST",
+			path:      "/api/v1/session/",
+			body:      body,
+			want:      ErrInvalid,
+		},
+		{
+			name:      "other method",
+			secret:    secret,
+			timestamp: ts,
+			signature: Sign(secret, ts, "PUT", "/api/v1/session/", body),
+			method:    "POST",
+			path:      "/api/v1/session/",
+			body:      body,
+			want:      ErrInvalid,
+		},
+		{
+			name:      "other path",
+			secret:    secret,
+			timestamp: ts,
+			signature: Sign(secret, ts, "POST", "/api/v1/level/", body),
+			method:    "POST",
+			path:      "/api/v1/session/",
+			body:      body,
+			want:      ErrInvalid,
+		},
+		{
+			name:      "other body",
+			secret:    secret,
+			timestamp: ts,
+			signature: Sign(secret, ts, "POST", "/api/v1/session/", []byte(`{}`)),
+			method:    "POST",
+			path:      "/api/v1/session/",
+			body:      body,
+			want:      ErrInvalid,
+		},
+		{
+			name:      "timestamp before window",
+			secret:    secret,
+			timestamp: strconv.FormatInt(now.Add(-time.Minute-time.Second).Unix(), 10),
+			signature: Sign(secret, strconv.FormatInt(now.Add(-time.Minute-time.Second).Unix(), 10), "POST", "/api/v1/session/", body),
+			method:    "POST",
+			path:      "/api/v1/session/",
+			body:      body,
+			want:      ErrExpired,
+		},
+		{
+			name:      "timestamp after window",
+			secret:    secret,
+			timestamp: strconv.FormatInt(now.Add(time.Minute+time.Second).Unix(), 10),
+			signature: Sign(secret, strconv.FormatInt(now.Add(time.Minute+time.Second).Unix(), 10), "POST", "/api/v1/session/", body),
+			method:    "POST",
+			path:      "/api/v1/session/",
+			body:      body,
+			want:      ErrExpired,
+		},
+		{
+			name:      "timestamp at edge of window",
+			secret:    secret,
+			timestamp: strconv.FormatInt(now.Add(-time.Minute).Unix(), 10),
+			signature: Sign(secret, strconv.FormatInt(now.Add(-time.Minute).Unix(), 10), "POST", "/api/v1/session/", body),
+			method:    "POST",
+			path:      "/api/v1/session/",
+			body:      body,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := newVerifier(t, now)
+
+			err := v.Verify(tt.secret, tt.timestamp, tt.signature, tt.method, tt.path, tt.body)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("got %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerifier_Verify_Replay(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	ts := strconv.FormatInt(now.Unix(), 10)
+	sig := Sign("secret", ts, "POST", "/api/v1/session/", nil)
+
+	v := newVerifier(t, now)
+
+	if err := v.Verify("secret", ts, sig, "POST", "/api/v1/session/", nil); err != nil {
+		t.Fatalf("first use: got %v, want nil", err)
+	}
+
+	if err := v.Verify("secret", ts, sig, "POST", "/api/v1/session/", nil); !errors.Is(err, ErrReplayed) {
+		t.Fatalf("second use: got %v, want %v", err, ErrReplayed)
+	}
+
+	// Once the signature left the window, it is expired rather than replayed
+	// and forgotten on the next prune.
+	later := now.Add(2*time.Minute + time.Second)
+	v.now = func() time.Time { return later }
+
+	if err := v.Verify("secret", ts, sig, "POST", "/api/v1/session/", nil); !errors.Is(err, ErrExpired) {
+		t.Fatalf("after window: got %v, want %v", err, ErrExpired)
+	}
+
+	laterTS := strconv.FormatInt(later.Unix(), 10)
+	if err := v.Verify("secret", laterTS, Sign("secret", laterTS, "POST", "/api/v1/session/", nil), "POST", "/api/v1/session/", nil); err != nil {
+		t.Fatalf("new signature after window: got %v, want nil", err)
+	}
+
+	if _, ok := v.seen[sig]; ok {
+		t.Fatalf("got signature used after window, want it pruned")
+	}
+}
+
+func TestConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     Config
+		wantErr bool
+	}{
+		{name: "valid", cfg: Config{Window: time.Minute}},
+		{name: "zero window", cfg: Config{}, wantErr: true},
+		{name: "negative window", cfg: Config{Window: -time.Second}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.cfg.Validate(); (err != nil) != tt.wantErr {
+				t.Fatalf("got %v, want error %t", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func newVerifier(t *testing.T, now time.Time) *Verifier {
+	t.Helper()
+
+	v, err := New(Config{Window: time.Minute})
+	if err != nil {
+		t.Fatalf("failed to create verifier: %v", err)
+	}
+
+	v.now = func() time.Time { return now }
+
+	return v
+}